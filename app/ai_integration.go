@@ -9,6 +9,7 @@ import (
 
 	"github.com/SongRunqi/go-todo/internal/logger"
 	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/google/uuid"
 )
 
 const Cmd = `
@@ -21,6 +22,7 @@ You will receive user context in XML format:
 	<current_time>ISO 8601 timestamp</current_time>
 	<weekday>Day of the week</weekday>
 	<user_preferred_language>Chinese or English</user_preferred_language>
+	<current_list>Name of the task list the user is working in, e.g. work or home</current_list>
 	<user_input>The actual user input</user_input>
 </context>
 
@@ -32,6 +34,7 @@ Key behaviors:
 5. Return tasks array only when user wants to create tasks (intent="create")
 6. Use <current_time> to calculate task times and deadlines
 7. Use <user_preferred_language> to generate taskName and taskDesc in the appropriate language
+8. Tasks are created in <current_list>; do not repeat the list name in taskName
 
 <ability>
 <item>
@@ -98,7 +101,8 @@ Return format (remove markdown code fence):
 			"recurringType": "Only set if isRecurring=true. Values: 'daily', 'weekly', 'monthly', 'yearly'. Examples: 每天->daily, 每周->weekly, 每月->monthly, 每年->yearly",
 			"recurringInterval": "Only set if isRecurring=true. Integer for interval. Default 1. Examples: 每天->1, 每两天->2, 每周->1, 每两周->2",
 			"recurringWeekdays": "Only set if isRecurring=true AND recurringType='weekly' AND task specifies specific weekdays. Array of integers where 0=Sunday, 1=Monday, 2=Tuesday, 3=Wednesday, 4=Thursday, 5=Friday, 6=Saturday. Examples: 周一周三周五->[1,3,5], 周二周四->[2,4], Mon/Wed/Fri->[1,3,5], Tue/Thu->[2,4]. Leave empty for simple weekly (every week same day).",
-			"recurringMaxCount": "Only set if isRecurring=true AND user specifies a limited number of repetitions. Integer value for maximum repetitions (periods, not individual occurrences). 0 or omitted = infinite. IMPORTANT: For weekday-specific tasks, count means number of WEEKS, not individual days. Examples: 每天跑步30次->30, 每周健身12次->12, 连续10天打卡->10, 连续7周->7, 共8周->8, 连续4个月->4, daily exercise for 30 days->30, weekly meeting 12 times->12, for 12 weeks->12, Mon/Wed/Fri driving for 7 weeks->7. If no count specified, omit this field or use 0."
+			"recurringMaxCount": "Only set if isRecurring=true AND user specifies a limited number of repetitions. Integer value for maximum repetitions (periods, not individual occurrences). 0 or omitted = infinite. IMPORTANT: For weekday-specific tasks, count means number of WEEKS, not individual days. Examples: 每天跑步30次->30, 每周健身12次->12, 连续10天打卡->10, 连续7周->7, 共8周->8, 连续4个月->4, daily exercise for 30 days->30, weekly meeting 12 times->12, for 12 weeks->12, Mon/Wed/Fri driving for 7 weeks->7. If no count specified, omit this field or use 0.",
+			"recurrenceRule": "Only set if isRecurring=true AND the schedule cannot be expressed by recurringType, recurringInterval and recurringWeekdays. An iCalendar RRULE (RFC 5545) without the 'RRULE:' prefix. Supported parts: FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, BYDAY (weekdays MO,TU,WE,TH,FR,SA,SU, with an ordinal for MONTHLY/YEARLY: 2TU = 2nd Tuesday, -1FR = last Friday), BYMONTHDAY (-1 = last day of the month), BYMONTH, BYSETPOS, COUNT, UNTIL (yyyyMMdd). No hourly or minutely rules. endTime must be the first occurrence. Examples: 每月最后一个周五->FREQ=MONTHLY;BYDAY=-1FR, 每月第二和第四个周二->FREQ=MONTHLY;BYDAY=2TU,4TU, 每月最后一个工作日->FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1, 每月15号和最后一天->FREQ=MONTHLY;BYMONTHDAY=15,-1, 每年11月第四个周四->FREQ=YEARLY;BYMONTH=11;BYDAY=4TH. Omit this field for simple schedules."
 		}
 	]
 }
@@ -158,6 +162,14 @@ Pattern recognition for "连续X周/月/年" (consecutive periods):
 - "for 12 weeks" = recurringMaxCount=12, recurringType="weekly"
 - "for 30 days" = recurringMaxCount=30, recurringType="daily"
 
+RULE-BASED recurring task examples (recurrenceRule; recurringType follows FREQ):
+- "每月最后一个周五下午5点发工资" -> isRecurring=true, recurringType="monthly", recurrenceRule="FREQ=MONTHLY;BYDAY=-1FR", endTime=the next last Friday of a month 5pm
+- "每月第二和第四个周二开例会" -> isRecurring=true, recurringType="monthly", recurrenceRule="FREQ=MONTHLY;BYDAY=2TU,4TU", endTime=the next 2nd or 4th Tuesday
+- "每月最后一个工作日报销" -> isRecurring=true, recurringType="monthly", recurrenceRule="FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1"
+- "每个工作日写日报，到年底为止" -> isRecurring=true, recurringType="weekly", recurrenceRule="FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;UNTIL=<yyyy>1231"
+- "last Friday of every month" -> isRecurring=true, recurringType="monthly", recurrenceRule="FREQ=MONTHLY;BYDAY=-1FR"
+- "1st and 15th of each month" -> isRecurring=true, recurringType="monthly", recurrenceRule="FREQ=MONTHLY;BYMONTHDAY=1,15"
+
 - "例行检查设备" (without specific frequency) -> isRecurring=false (not specific enough)
 - "买牛奶" (one-time task) -> isRecurring=false
 
@@ -185,7 +197,7 @@ Expected output: taskName: "Meeting Tomorrow at 3 PM", taskDesc: "Attend meeting
 
 `
 
-func DoI(todoStr string, todos *[]TodoItem, store *FileTodoStore) error {
+func DoI(todoStr string, todos *[]TodoItem, store TodoStore) error {
 
 	var intentResponse IntentResponse
 	removedata := removeJsonTag(todoStr)
@@ -202,12 +214,14 @@ func DoI(todoStr string, todos *[]TodoItem, store *FileTodoStore) error {
 		// Handle multiple tasks separated by semicolons
 		for i := range intentResponse.Tasks {
 			task := &intentResponse.Tasks[i]
-			if err := CreateTask(todos, task); err != nil {
+			nextID := func() (int, error) { return NextTaskID(store, *todos) }
+			if err := createTask(todos, task, nextID); err != nil {
 				return fmt.Errorf("failed to create task: %w", err)
 			}
 			output.PrintTaskCreated(task.TaskID, task.TaskName)
 		}
 		// Save all tasks at once after creating them
+		beginOperation(store, "create", describeCreated(intentResponse.Tasks))
 		err := store.Save(*todos, false)
 		if err != nil {
 			return fmt.Errorf("failed to save todos batch: %w", err)
@@ -248,7 +262,7 @@ func DoI(todoStr string, todos *[]TodoItem, store *FileTodoStore) error {
 	return nil
 }
 
-func CompactTasks(store *FileTodoStore, period string) error {
+func CompactTasks(store TodoStore, period string) error {
 	// Validate period
 	if period != "week" && period != "month" {
 		return fmt.Errorf("invalid period: %s (must be 'week' or 'month')", period)
@@ -405,8 +419,13 @@ Summary: [your summary here]`, periodKey, periodKey, len(tasks), completedCount,
 		taskDesc += fmt.Sprintf("\nSummary: %s", summary)
 
 		// Create summary task with unique ID
+		id, err := NextTaskID(store, newBackupTodos)
+		if err != nil {
+			return err
+		}
 		summaryTask := TodoItem{
-			TaskID:     GetLastId(&newBackupTodos),
+			TaskID:     id,
+			UUID:       uuid.NewString(),
 			CreateTime: periodData.StartTime,
 			EndTime:    periodData.EndTime,
 			User:       "System",
@@ -417,12 +436,13 @@ Summary: [your summary here]`, periodKey, periodKey, len(tasks), completedCount,
 			Urgent:     "low",
 		}
 
-		// Add summary task to backup immediately so next ID is unique
+		// Add summary task to backup immediately
 		newBackupTodos = append(newBackupTodos, summaryTask)
 		totalCompacted += len(tasks)
 	}
 
 	// Save updated backup
+	beginOperation(store, "compact", fmt.Sprintf("Compact %d backup tasks by %s into %d summaries", totalCompacted, period, len(periods)))
 	err = store.Save(newBackupTodos, true)
 	if err != nil {
 		return fmt.Errorf("failed to save compacted backup: %w", err)
@@ -437,6 +457,18 @@ Summary: [your summary here]`, periodKey, periodKey, len(tasks), completedCount,
 	return nil
 }
 
+// describeCreated summarises a batch of newly created tasks for the journal
+func describeCreated(tasks []TodoItem) string {
+	if len(tasks) == 1 {
+		return fmt.Sprintf("Create task %d: %s", tasks[0].TaskID, tasks[0].TaskName)
+	}
+	names := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		names = append(names, fmt.Sprintf("%d: %s", task.TaskID, task.TaskName))
+	}
+	return fmt.Sprintf("Create %d tasks (%s)", len(tasks), strings.Join(names, ", "))
+}
+
 // parseAISummaryResponse extracts title and summary from AI response
 func parseAISummaryResponse(response string) (title, summary string) {
 	lines := strings.Split(response, "\n")
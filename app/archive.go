@@ -0,0 +1,50 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/logger"
+)
+
+// ArchiveCompleted moves the completed tasks of todos that were finished at
+// least olderThan before now to the backup list, keeping their completed
+// status and recording the archive time. It returns the archived tasks and
+// saves nothing when there are none.
+func ArchiveCompleted(todos *[]TodoItem, store TodoStore, now time.Time, olderThan time.Duration) ([]TodoItem, error) {
+	var indices []int
+	for i, task := range *todos {
+		if task.Status == "completed" && !now.Before(completedTime(task).Add(olderThan)) {
+			indices = append(indices, i)
+		}
+	}
+	if len(indices) == 0 {
+		return nil, nil
+	}
+
+	archived := make([]TodoItem, 0, len(indices))
+	for _, i := range indices {
+		(*todos)[i].ArchivedAt = now
+		archived = append(archived, (*todos)[i])
+	}
+
+	logger.Debugf("Archiving %d completed tasks", len(archived))
+	beginOperation(store, "archive", fmt.Sprintf("Archive %d completed tasks", len(archived)))
+	if err := moveToBackup(todos, indices, store); err != nil {
+		return nil, err
+	}
+	return archived, nil
+}
+
+// completedTime returns when task was completed. Tasks completed before
+// completion times were recorded are aged from their end time, or failing
+// that their creation time.
+func completedTime(task TodoItem) time.Time {
+	switch {
+	case !task.CompletedAt.IsZero():
+		return task.CompletedAt
+	case !task.EndTime.IsZero():
+		return task.EndTime
+	}
+	return task.CreateTime
+}
@@ -6,14 +6,26 @@ import (
 	"strings"
 	"time"
 
+	"github.com/SongRunqi/go-todo/internal/domain"
 	"github.com/SongRunqi/go-todo/internal/i18n"
 	"github.com/SongRunqi/go-todo/internal/logger"
 	"github.com/SongRunqi/go-todo/internal/output"
 	"github.com/SongRunqi/go-todo/internal/validator"
 	"github.com/SongRunqi/go-todo/parser"
+	"github.com/google/uuid"
 )
 
-func CreateTask(todos *[]TodoItem, todo *TodoItem) error {
+// CreateTask validates todo, gives it an ID from store (see NextTaskID) and
+// a UUID, and appends it to todos
+func CreateTask(todos *[]TodoItem, todo *TodoItem, store TodoStore) error {
+	return createTask(todos, todo, func() (int, error) {
+		return NextTaskID(store, *todos)
+	})
+}
+
+// createTask validates todo, gives it an ID from nextID and a UUID, and
+// appends it to todos
+func createTask(todos *[]TodoItem, todo *TodoItem, nextID func() (int, error)) error {
 	// Validate task fields
 	if err := validator.ValidateTaskName(todo.TaskName); err != nil {
 		return err
@@ -39,10 +51,10 @@ func CreateTask(todos *[]TodoItem, todo *TodoItem) error {
 
 	// Validate recurring task fields
 	if todo.IsRecurring {
-		if err := validator.ValidateRecurringType(todo.RecurringType); err != nil {
+		if err := validator.ValidateRecurrenceRule(todo.RecurrenceRule); err != nil {
 			return err
 		}
-		if err := validator.ValidateRecurringInterval(todo.RecurringInterval, todo.IsRecurring); err != nil {
+		if err := validator.ValidateRecurringType(todo.RecurringType); err != nil {
 			return err
 		}
 		if err := validator.ValidateRecurringWeekdays(todo.RecurringWeekdays); err != nil {
@@ -51,9 +63,12 @@ func CreateTask(todos *[]TodoItem, todo *TodoItem) error {
 		if err := validator.ValidateRecurringMaxCount(todo.RecurringMaxCount, todo.IsRecurring); err != nil {
 			return err
 		}
-		// Set default interval if not specified
-		if todo.RecurringInterval == 0 {
-			todo.RecurringInterval = 1
+		// Describe the schedule by a rule; the interval defaults to 1
+		if err := setUpRecurrence(todo); err != nil {
+			return err
+		}
+		if err := validator.ValidateRecurringInterval(todo.RecurringInterval, todo.IsRecurring); err != nil {
+			return err
 		}
 		// Initialize completion count
 		todo.CompletionCount = 0
@@ -69,8 +84,12 @@ func CreateTask(todos *[]TodoItem, todo *TodoItem) error {
 	}
 
 	// Generate a unique TaskID
-	id := GetLastId(todos)
+	id, err := nextID()
+	if err != nil {
+		return fmt.Errorf("failed to allocate task ID: %w", err)
+	}
 	todo.TaskID = id
+	todo.UUID = uuid.NewString()
 	// Add the new todo to the todos slice (but don't save yet)
 	*todos = append(*todos, *todo)
 	return nil
@@ -93,6 +112,25 @@ func GetLastId(todos *[]TodoItem) int {
 	return maxID + 1
 }
 
+// NextTaskID returns the ID for a new task in store. Stores that allocate
+// IDs never hand out the same ID twice; for other stores it is the next ID
+// after every task in the active and backup lists and in todos, which holds
+// tasks that may not have been saved yet.
+func NextTaskID(store TodoStore, todos []TodoItem) (int, error) {
+	if allocator, ok := domain.As[domain.IDAllocator](store); ok {
+		return allocator.NextID()
+	}
+	all := append([]TodoItem{}, todos...)
+	for _, backup := range []bool{false, true} {
+		saved, err := store.Load(backup)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load todos: %w", err)
+		}
+		all = append(all, saved...)
+	}
+	return GetLastId(&all), nil
+}
+
 func List(todos *[]TodoItem) error {
 	newTodos := sortedList(todos)
 	alfredItems := TransToAlfredItem(&newTodos)
@@ -132,6 +170,16 @@ func GetTask(todos *[]TodoItem, id int) error {
 				recurringInfo = "\n\n## 🔄 Recurring Task Details\n\n"
 				recurringInfo += fmt.Sprintf("- **Type:** %s\n", task.RecurringType)
 				recurringInfo += fmt.Sprintf("- **Interval:** Every %d %s\n", task.RecurringInterval, task.RecurringType)
+				if task.RecurrenceRule != "" {
+					recurringInfo += fmt.Sprintf("- **Rule:** `%s`\n", task.RecurrenceRule)
+				}
+				if task.Status == "paused" {
+					if task.PausedUntil.IsZero() {
+						recurringInfo += "- **Paused:** until resumed\n"
+					} else {
+						recurringInfo += fmt.Sprintf("- **Paused:** until %s\n", task.PausedUntil.Format("2006-01-02"))
+					}
+				}
 
 				// Show event duration if specified
 				if task.EventDuration > 0 {
@@ -164,41 +212,44 @@ func GetTask(todos *[]TodoItem, id int) error {
 
 				// Show occurrence history if using new model
 				if len(task.OccurrenceHistory) > 0 {
-					now := time.Now()
-					weekStart := now
-					for weekStart.Weekday() != time.Sunday {
-						weekStart = weekStart.AddDate(0, 0, -1)
-					}
-					weekEnd := weekStart.AddDate(0, 0, 7)
+					periodStart, periodEnd := currentPeriod(task)
 
-					// Count occurrences in current week
-					pendingThisWeek := 0
-					completedThisWeek := 0
-					missedThisWeek := 0
+					// Count occurrences in the current period
+					pendingInPeriod := 0
+					completedInPeriod := 0
+					missedInPeriod := 0
+					skippedInPeriod := 0
+					inPeriod := 0
 
 					for _, occ := range task.OccurrenceHistory {
-						if !occ.ScheduledTime.Before(weekStart) && occ.ScheduledTime.Before(weekEnd) {
+						if t := seriesTime(occ); !t.Before(periodStart) && t.Before(periodEnd) {
+							inPeriod++
 							switch occ.Status {
 							case "pending":
-								pendingThisWeek++
+								pendingInPeriod++
 							case "completed":
-								completedThisWeek++
+								completedInPeriod++
 							case "missed":
-								missedThisWeek++
+								missedInPeriod++
+							case "skipped":
+								skippedInPeriod++
 							}
 						}
 					}
 
-					// Show current week progress for weekday-specific tasks
+					// Show current period progress for weekday-specific tasks
 					if task.RecurringType == "weekly" && len(task.RecurringWeekdays) > 0 {
-						recurringInfo += fmt.Sprintf("- **Current Week:** %d completed", completedThisWeek)
-						if missedThisWeek > 0 {
-							recurringInfo += fmt.Sprintf(", %d missed", missedThisWeek)
+						recurringInfo += fmt.Sprintf("- **Current Period (week of %s):** %d completed", periodStart.Format("2006-01-02"), completedInPeriod)
+						if missedInPeriod > 0 {
+							recurringInfo += fmt.Sprintf(", %d missed", missedInPeriod)
+						}
+						if skippedInPeriod > 0 {
+							recurringInfo += fmt.Sprintf(", %d skipped", skippedInPeriod)
 						}
-						if pendingThisWeek > 0 {
-							recurringInfo += fmt.Sprintf(", %d pending", pendingThisWeek)
+						if pendingInPeriod > 0 {
+							recurringInfo += fmt.Sprintf(", %d pending", pendingInPeriod)
 						}
-						recurringInfo += fmt.Sprintf(" (out of %d)\n", len(task.RecurringWeekdays))
+						recurringInfo += fmt.Sprintf(" (out of %d)\n", inPeriod)
 					}
 
 					// Show recent completed occurrences (last 3)
@@ -244,6 +295,9 @@ func GetTask(todos *[]TodoItem, id int) error {
 								endTime := occ.ScheduledTime.Add(task.EventDuration)
 								recurringInfo += fmt.Sprintf(" - %s", endTime.Format("15:04"))
 							}
+							if !occ.OriginalTime.IsZero() {
+								recurringInfo += fmt.Sprintf(" (moved from %s)", occ.OriginalTime.Format("2006-01-02 15:04"))
+							}
 							recurringInfo += "\n"
 						}
 					}
@@ -258,6 +312,17 @@ func GetTask(todos *[]TodoItem, id int) error {
 					if len(missedOccs) > 0 {
 						recurringInfo += fmt.Sprintf("- **Missed:** %d occurrence(s)\n", len(missedOccs))
 					}
+
+					// Show skipped occurrences if any
+					skippedOccs := 0
+					for _, occ := range task.OccurrenceHistory {
+						if occ.Status == "skipped" {
+							skippedOccs++
+						}
+					}
+					if skippedOccs > 0 {
+						recurringInfo += fmt.Sprintf("- **Skipped:** %d occurrence(s)\n", skippedOccs)
+					}
 				} else {
 					// Legacy format - show old progress tracking
 					if task.RecurringType == "weekly" && len(task.RecurringWeekdays) > 0 && len(task.OccurrenceHistory) > 0 {
@@ -287,7 +352,7 @@ func GetTask(todos *[]TodoItem, id int) error {
 - **%s:** %s
 - **%s:** %s
 - **%s:** %s
-- **%s:** %s%s%s%s
+- **%s:** %s%s%s%s%s
 
 ## %s
 
@@ -315,6 +380,16 @@ func GetTask(todos *[]TodoItem, id int) error {
 					}
 					return ""
 				}(),
+				func() string {
+					grouping := ""
+					if task.Project != "" {
+						grouping += "\n- **" + i18n.T("field.project") + ":** " + task.Project
+					}
+					if len(task.Tags) > 0 {
+						grouping += "\n- **" + i18n.T("field.tags") + ":** " + strings.Join(task.Tags, ", ")
+					}
+					return grouping
+				}(),
 				recurringInfo,
 				i18n.T("field.description"),
 				task.TaskDesc,
@@ -327,7 +402,7 @@ func GetTask(todos *[]TodoItem, id int) error {
 	return fmt.Errorf("task with ID %d not found", id)
 }
 
-func UpdateTask(todos *[]TodoItem, todoMD string, store *FileTodoStore) error {
+func UpdateTask(todos *[]TodoItem, todoMD string, store TodoStore) error {
 	logger.Debugf("Updating task with content: %s", todoMD)
 
 	// Parse the input using the parser package
@@ -361,7 +436,14 @@ func UpdateTask(todos *[]TodoItem, todoMD string, store *FileTodoStore) error {
 	if err := validator.ValidateTaskName(updatedTask.TaskName); err != nil {
 		return err
 	}
-	if err := validator.ValidateStatus(updatedTask.Status); err != nil {
+	// A recurring task's status describes the series as a whole
+	validateStatus := validator.ValidateStatus
+	for _, task := range *todos {
+		if task.TaskID == updatedTask.TaskID && task.IsRecurring {
+			validateStatus = validator.ValidateRecurringStatus
+		}
+	}
+	if err := validateStatus(updatedTask.Status); err != nil {
 		return err
 	}
 	if updatedTask.Urgent != "" {
@@ -393,10 +475,39 @@ func UpdateTask(todos *[]TodoItem, todoMD string, store *FileTodoStore) error {
 				updatedTask.EndTime = (*todos)[i].EndTime
 			}
 
+			// The UUID, project and tags are not part of the editable content
+			updatedTask.UUID = (*todos)[i].UUID
+			updatedTask.Project = (*todos)[i].Project
+			updatedTask.Tags = (*todos)[i].Tags
+
+			// Neither is the recurrence, nor its progress so far
+			updatedTask.EventDuration = (*todos)[i].EventDuration
+			updatedTask.IsRecurring = (*todos)[i].IsRecurring
+			updatedTask.RecurringType = (*todos)[i].RecurringType
+			updatedTask.RecurringInterval = (*todos)[i].RecurringInterval
+			updatedTask.RecurringWeekdays = (*todos)[i].RecurringWeekdays
+			updatedTask.RecurringMaxCount = (*todos)[i].RecurringMaxCount
+			updatedTask.RecurrenceRule = (*todos)[i].RecurrenceRule
+			updatedTask.RecurrenceStart = (*todos)[i].RecurrenceStart
+			updatedTask.CompletionCount = (*todos)[i].CompletionCount
+			updatedTask.PausedUntil = (*todos)[i].PausedUntil
+			updatedTask.OccurrenceHistory = (*todos)[i].OccurrenceHistory
+			updatedTask.CurrentPeriodCompletions = (*todos)[i].CurrentPeriodCompletions
+
+			// Keep the original completion time, or record it when the
+			// update completes the task
+			if updatedTask.Status == "completed" {
+				updatedTask.CompletedAt = (*todos)[i].CompletedAt
+				if updatedTask.CompletedAt.IsZero() {
+					updatedTask.CompletedAt = time.Now()
+				}
+			}
+
 			// Update the task in place
 			(*todos)[i] = updatedTask
 
 			// Save to file
+			beginOperation(store, "update", fmt.Sprintf("Update task %d: %s", updatedTask.TaskID, updatedTask.TaskName))
 			err := store.Save(*todos, false)
 			if err != nil {
 				return fmt.Errorf("failed to save task: %w", err)
@@ -418,7 +529,7 @@ func UpdateTask(todos *[]TodoItem, todoMD string, store *FileTodoStore) error {
 	return fmt.Errorf("task with ID %d not found", updatedTask.TaskID)
 }
 
-func DeleteTask(todos *[]TodoItem, id int, store *FileTodoStore) error {
+func DeleteTask(todos *[]TodoItem, id int, store TodoStore) error {
 	if err := validator.ValidateTaskID(id); err != nil {
 		return err
 	}
@@ -439,29 +550,46 @@ func DeleteTask(todos *[]TodoItem, id int, store *FileTodoStore) error {
 
 	taskName := deletedTask.TaskName
 	logger.Debugf("Deleting task ID %d: %s", id, taskName)
+	beginOperation(store, "delete", fmt.Sprintf("Delete task %d: %s", id, taskName))
 
 	// Mark task as deleted
 	deletedTask.Status = "deleted"
+	deletedTask.DeletedAt = time.Now()
 
+	if err := moveToBackup(todos, []int{taskIndex}, store); err != nil {
+		return err
+	}
+
+	logger.Debug("Task moved to backup with 'deleted' status")
+	output.PrintTaskDeleted(id)
+	return nil
+}
+
+// moveToBackup appends the tasks of todos at the given indices to the
+// backup list, then removes them from todos and saves the active list
+func moveToBackup(todos *[]TodoItem, indices []int, store TodoStore) error {
 	// Load existing backup todos
 	backupTodos, err := store.Load(true)
 	if err != nil {
 		return fmt.Errorf("failed to load backup: %w", err)
 	}
 
-	// Add deleted task to backup
-	backupTodos = append(backupTodos, *deletedTask)
+	moved := make(map[int]bool, len(indices))
+	for _, i := range indices {
+		backupTodos = append(backupTodos, (*todos)[i])
+		moved[i] = true
+	}
 
-	// Save deleted task to backup file
+	// Save the moved tasks to the backup file first, so a failure cannot lose them
 	err = store.Save(backupTodos, true)
 	if err != nil {
 		return fmt.Errorf("failed to save to backup: %w", err)
 	}
 
-	// Remove task from main todos
+	// Remove the moved tasks from the main todos
 	newTodos := make([]TodoItem, 0)
 	for i := 0; i < len(*todos); i++ {
-		if i != taskIndex {
+		if !moved[i] {
 			newTodos = append(newTodos, (*todos)[i])
 		}
 	}
@@ -470,16 +598,13 @@ func DeleteTask(todos *[]TodoItem, id int, store *FileTodoStore) error {
 	// Save updated todos
 	err = store.Save(*todos, false)
 	if err != nil {
-		return fmt.Errorf("failed to save after deletion: %w", err)
+		return fmt.Errorf("failed to save active todos: %w", err)
 	}
-
-	logger.Debug("Task moved to backup with 'deleted' status")
-	output.PrintTaskDeleted(id)
 	return nil
 }
 
 // DeleteBackupTask permanently removes a task from the backup list.
-func DeleteBackupTask(id int, store *FileTodoStore) error {
+func DeleteBackupTask(id int, store TodoStore) error {
 	if err := validator.ValidateTaskID(id); err != nil {
 		return err
 	}
@@ -504,6 +629,7 @@ func DeleteBackupTask(id int, store *FileTodoStore) error {
 	taskName := backupTodos[index].TaskName
 	backupTodos = append(backupTodos[:index], backupTodos[index+1:]...)
 
+	beginOperation(store, "delete", fmt.Sprintf("Delete backup task %d: %s", id, taskName))
 	if err := store.Save(backupTodos, true); err != nil {
 		return fmt.Errorf("failed to save backup after deletion: %w", err)
 	}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/logger"
+	"github.com/SongRunqi/go-todo/internal/validator"
+)
+
+// ImportTasks validates the imported tasks and adds them to the active
+// list, giving each a new ID and UUID. Recurring tasks that started before
+// now pick up at their next occurrence (see catchUpRecurring). Nothing is
+// imported if any task is invalid. With dryRun set nothing is saved and the
+// returned tasks, which have no IDs yet, show what would be created.
+func ImportTasks(todos *[]TodoItem, imported []TodoItem, store TodoStore, now time.Time, dryRun bool) ([]TodoItem, error) {
+	// Validate every task before allocating any ID
+	created := make([]TodoItem, 0, len(imported))
+	noID := func() (int, error) { return 0, nil }
+	for i := range imported {
+		task := imported[i]
+		status := task.Status
+		if !task.IsRecurring {
+			if err := validator.ValidateStatus(status); err != nil {
+				return nil, fmt.Errorf("task %d (%s): %w", i+1, task.TaskName, err)
+			}
+		}
+		if task.CreateTime.IsZero() {
+			task.CreateTime = now
+		}
+		if task.IsRecurring {
+			// The catch-up follows the task's rule
+			if err := setUpRecurrence(&task); err != nil {
+				return nil, fmt.Errorf("task %d (%s): %w", i+1, task.TaskName, err)
+			}
+		}
+		ended := task.IsRecurring && !catchUpRecurring(&task, now)
+		if err := createTask(&created, &task, noID); err != nil {
+			return nil, fmt.Errorf("task %d (%s): %w", i+1, task.TaskName, err)
+		}
+		// createTask starts every task afresh; keep the imported status
+		last := &created[len(created)-1]
+		if !task.IsRecurring {
+			last.Status = status
+		} else if ended {
+			last.Status = "completed"
+			for j := range last.OccurrenceHistory {
+				last.OccurrenceHistory[j].Status = "missed"
+			}
+		}
+	}
+	if dryRun || len(created) == 0 {
+		return created, nil
+	}
+
+	for i := range created {
+		id, err := NextTaskID(store, append(append([]TodoItem{}, *todos...), created[:i]...))
+		if err != nil {
+			return nil, fmt.Errorf("failed to allocate task ID: %w", err)
+		}
+		created[i].TaskID = id
+	}
+
+	logger.Debugf("Importing %d tasks", len(created))
+	beginOperation(store, "import", fmt.Sprintf("Import %d tasks", len(created)))
+	*todos = append(*todos, created...)
+	if err := store.Save(*todos, false); err != nil {
+		return nil, fmt.Errorf("failed to save imported tasks: %w", err)
+	}
+	return created, nil
+}
+
+// catchUpRecurring moves the first occurrence of an imported recurring task
+// that lies before now to its next occurrence from now. The periods passed
+// count against the task's maximum; once none remain, or the task's rule
+// ends, it stops at the last occurrence and reports false.
+func catchUpRecurring(task *TodoItem, now time.Time) bool {
+	if task.EndTime.IsZero() || !task.EndTime.Before(now) {
+		return true
+	}
+	defer func() { task.DueDate = task.EndTime.Format("2006-01-02") }()
+
+	weekdays := task.RecurringType == "weekly" && len(task.RecurringWeekdays) > 0
+	first := taskWeekStart(task)
+	passed := 0
+	for task.EndTime.Before(now) {
+		next := calculateNextOccurrence(task)
+		if next.IsZero() {
+			// The rule ended before now
+			return false
+		}
+		// Occurrences on weekdays belong to the period of their week
+		if !weekdays || !weekOf(next, first).Equal(weekOf(task.EndTime, first)) {
+			passed++
+			if task.RecurringMaxCount > 0 && passed >= task.RecurringMaxCount {
+				return false
+			}
+		}
+		task.EndTime = next
+	}
+	if task.RecurringMaxCount > 0 {
+		task.RecurringMaxCount -= passed
+	}
+	return true
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/logger"
+	"github.com/SongRunqi/go-todo/internal/validator"
+)
+
+// SkipOccurrence marks the pending occurrence of recurring task id on the
+// day of date as skipped, or with a zero date the one that is due, or
+// failing that the next. Skipping the last pending occurrence of a period
+// moves the task on to the next period the way completing it would, though
+// only a period with a completed occurrence counts as completed. It returns
+// the skipped occurrence.
+func SkipOccurrence(todos *[]TodoItem, id int, date time.Time, store TodoStore, now time.Time) (OccurrenceRecord, error) {
+	task, err := findRecurring(todos, id, "active")
+	if err != nil {
+		return OccurrenceRecord{}, err
+	}
+	occ, err := pendingOccurrence(task, date)
+	if err != nil {
+		return OccurrenceRecord{}, err
+	}
+
+	logger.Debugf("Skipping occurrence of task %d at %s", id, occ.ScheduledTime.Format("2006-01-02 15:04"))
+	beginOperation(store, "skip", fmt.Sprintf("Skip occurrence %s of task %d: %s",
+		occ.ScheduledTime.Format("2006-01-02 15:04"), id, task.TaskName))
+	occ.Status = "skipped"
+	skipped := *occ
+
+	if task.RecurringType == "weekly" && len(task.RecurringWeekdays) > 0 && IsPeriodCompletedNew(task) {
+		task.CompletionCount++
+		if task.RecurringMaxCount > 0 && task.CompletionCount >= task.RecurringMaxCount {
+			task.Status = "completed"
+			task.CompletedAt = now
+		}
+	}
+	if task.Status == "active" {
+		// Move EndTime to the next pending occurrence, scheduling the next
+		// period if this was the last one of its period
+		reconcileTask(task, now)
+	}
+
+	if err := store.Save(*todos, false); err != nil {
+		return OccurrenceRecord{}, fmt.Errorf("failed to save updated todos: %w", err)
+	}
+	return skipped, nil
+}
+
+// RescheduleOccurrence moves the pending occurrence of recurring task id on
+// the day of from, or with a zero from the one that is due or next, to the
+// time to, leaving the rest of the series alone. With keepTime only the date
+// of to is used and the occurrence keeps its time of day. The occurrence
+// remembers where the series had it, so it still belongs to its period. It
+// returns the moved occurrence.
+func RescheduleOccurrence(todos *[]TodoItem, id int, from, to time.Time, keepTime bool, store TodoStore, now time.Time) (OccurrenceRecord, error) {
+	task, err := findRecurring(todos, id, "active")
+	if err != nil {
+		return OccurrenceRecord{}, err
+	}
+	occ, err := pendingOccurrence(task, from)
+	if err != nil {
+		return OccurrenceRecord{}, err
+	}
+
+	if keepTime {
+		at := occ.ScheduledTime.In(to.Location())
+		to = time.Date(to.Year(), to.Month(), to.Day(), at.Hour(), at.Minute(), at.Second(), 0, to.Location())
+	}
+	if today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()); to.Before(today) {
+		return OccurrenceRecord{}, fmt.Errorf("cannot reschedule to %s, which has passed", to.Format("2006-01-02 15:04"))
+	}
+	for _, other := range task.OccurrenceHistory {
+		if other.ScheduledTime.Equal(to) {
+			return OccurrenceRecord{}, fmt.Errorf("task %d already has an occurrence at %s", id, to.Format("2006-01-02 15:04"))
+		}
+	}
+
+	logger.Debugf("Rescheduling occurrence of task %d from %s to %s", id,
+		occ.ScheduledTime.Format("2006-01-02 15:04"), to.Format("2006-01-02 15:04"))
+	beginOperation(store, "reschedule", fmt.Sprintf("Reschedule occurrence %s of task %d: %s",
+		occ.ScheduledTime.Format("2006-01-02 15:04"), id, task.TaskName))
+	if occ.OriginalTime.IsZero() {
+		occ.OriginalTime = occ.ScheduledTime
+	}
+	occ.ScheduledTime = to
+	moved := *occ
+
+	// Keep the history in schedule order, which the next pending occurrence
+	// is found by
+	slices.SortStableFunc(task.OccurrenceHistory, func(a, b OccurrenceRecord) int {
+		return a.ScheduledTime.Compare(b.ScheduledTime)
+	})
+	next, _ := GetNextPendingOccurrence(task)
+	task.EndTime = next.ScheduledTime
+	task.DueDate = next.ScheduledTime.Format("2006-01-02")
+
+	if err := store.Save(*todos, false); err != nil {
+		return OccurrenceRecord{}, fmt.Errorf("failed to save updated todos: %w", err)
+	}
+	return moved, nil
+}
+
+// findRecurring returns the recurring task with the given ID, which must
+// have one of the given statuses
+func findRecurring(todos *[]TodoItem, id int, statuses ...string) (*TodoItem, error) {
+	if err := validator.ValidateTaskID(id); err != nil {
+		return nil, err
+	}
+	for i := range *todos {
+		task := &(*todos)[i]
+		if task.TaskID != id {
+			continue
+		}
+		if !task.IsRecurring {
+			return nil, fmt.Errorf("task %d is not a recurring task", id)
+		}
+		if err := validator.ValidateRecurringStatus(task.Status); err != nil {
+			return nil, fmt.Errorf("task %d: %w", id, err)
+		}
+		if !slices.Contains(statuses, task.Status) {
+			return nil, fmt.Errorf("task %d is %s, not %s", id, task.Status, strings.Join(statuses, " or "))
+		}
+		return task, nil
+	}
+	return nil, fmt.Errorf("task with ID %d not found", id)
+}
+
+// pendingOccurrence returns the pending occurrence of task on the day of
+// date, or with a zero date the one that is due, or failing that the next
+func pendingOccurrence(task *TodoItem, date time.Time) (*OccurrenceRecord, error) {
+	if date.IsZero() {
+		occ, _ := GetCurrentOccurrence(task)
+		if occ == nil {
+			occ, _ = GetNextPendingOccurrence(task)
+		}
+		if occ == nil {
+			return nil, fmt.Errorf("task %d has no pending occurrence", task.TaskID)
+		}
+		return occ, nil
+	}
+
+	year, month, day := date.Date()
+	for i := range task.OccurrenceHistory {
+		occ := &task.OccurrenceHistory[i]
+		y, m, d := occ.ScheduledTime.In(date.Location()).Date()
+		if occ.Status == "pending" && y == year && m == month && d == day {
+			return occ, nil
+		}
+	}
+	return nil, fmt.Errorf("task %d has no pending occurrence on %s", task.TaskID, date.Format("2006-01-02"))
+}
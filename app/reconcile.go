@@ -0,0 +1,119 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/logger"
+	"github.com/SongRunqi/go-todo/internal/migration"
+)
+
+// Reconciliation records what ReconcileRecurring changed on a recurring task
+type Reconciliation struct {
+	TaskID   int
+	TaskName string
+	Missed   int       // Occurrences marked missed
+	Added    int       // Pending occurrences scheduled
+	Next     time.Time // The next pending occurrence, zero when the series ended
+	Resumed  bool      // The task was paused until a time that has come
+}
+
+// ReconcileRecurring catches the active recurring tasks of todos up with
+// now: pending occurrences from days before now are marked missed, the
+// occurrences the task's rule has up next are scheduled, and EndTime and
+// DueDate move to the next pending one. Tasks whose rule has no occurrences
+// left are completed. Paused tasks are left alone until the day they are
+// paused until, when they resume from that day. It returns the tasks it
+// changed and saves nothing when there are none.
+func ReconcileRecurring(todos *[]TodoItem, store TodoStore, now time.Time) ([]Reconciliation, error) {
+	var changes []Reconciliation
+	for i := range *todos {
+		task := &(*todos)[i]
+		if !task.IsRecurring {
+			continue
+		}
+		resumed := false
+		if until := task.PausedUntil; task.Status == "paused" && !until.IsZero() && !now.Before(until) {
+			if err := resume(task, until, now); err != nil {
+				logger.Warnf("Failed to resume task %d: %v", task.TaskID, err)
+				continue
+			}
+			logger.Infof("Resumed recurring task %d, paused until %s", task.TaskID, until.Format("2006-01-02"))
+			resumed = true
+		}
+		if task.Status != "active" {
+			if resumed {
+				changes = append(changes, Reconciliation{TaskID: task.TaskID, TaskName: task.TaskName, Resumed: true})
+			}
+			continue
+		}
+		if change, ok := reconcileTask(task, now); ok || resumed {
+			change.Resumed = resumed
+			changes = append(changes, change)
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	logger.Debugf("Reconciled %d recurring tasks", len(changes))
+	beginOperation(store, "reconcile", fmt.Sprintf("Reconcile %d recurring tasks", len(changes)))
+	if err := store.Save(*todos, false); err != nil {
+		return nil, fmt.Errorf("failed to save updated todos: %w", err)
+	}
+	return changes, nil
+}
+
+// reconcileTask catches a single active recurring task up with now and
+// reports whether anything changed
+func reconcileTask(task *TodoItem, now time.Time) (Reconciliation, bool) {
+	change := Reconciliation{TaskID: task.TaskID, TaskName: task.TaskName}
+	if task.EndTime.IsZero() {
+		return change, false
+	}
+	// Without its rule the task's schedule is unknown; todo doctor reports it
+	if _, err := recurrenceRule(task); err != nil {
+		logger.Warnf("Task %d: %v, not reconciled", task.TaskID, err)
+		return change, false
+	}
+	if len(task.OccurrenceHistory) == 0 {
+		migration.ConvertPeriodCompletions(task)
+	}
+	endTime, dueDate, scheduled := task.EndTime, task.DueDate, len(task.OccurrenceHistory)
+
+	for {
+		change.Missed += MarkMissedOccurrences(task, now)
+		if next, _ := GetNextPendingOccurrence(task); next != nil {
+			change.Next = next.ScheduledTime
+			task.EndTime = next.ScheduledTime
+			task.DueDate = next.ScheduledTime.Format("2006-01-02")
+			break
+		}
+
+		// Schedule what follows the latest occurrence
+		task.EndTime = lastScheduled(task)
+		next := CreateNextPeriodOccurrences(task, now)
+		if len(next) == 0 {
+			// The rule has no more occurrences (COUNT or UNTIL)
+			task.Status = "completed"
+			task.CompletedAt = now
+			logger.Infof("Recurring task %d has no occurrences left, marked completed", task.TaskID)
+			break
+		}
+		for _, occ := range next {
+			if occ.Status == "missed" {
+				change.Missed++
+			}
+		}
+		task.OccurrenceHistory = append(task.OccurrenceHistory, next...)
+	}
+
+	for _, occ := range task.OccurrenceHistory[scheduled:] {
+		if occ.Status == "pending" {
+			change.Added++
+		}
+	}
+	changed := change.Missed > 0 || change.Added > 0 || task.Status != "active" ||
+		!task.EndTime.Equal(endTime) || task.DueDate != dueDate
+	return change, changed
+}
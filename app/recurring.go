@@ -2,14 +2,17 @@ package app
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/SongRunqi/go-todo/internal/logger"
+	"github.com/SongRunqi/go-todo/internal/migration"
 	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/SongRunqi/go-todo/internal/rrule"
 	"github.com/SongRunqi/go-todo/internal/validator"
 )
 
-func Complete(todos *[]TodoItem, todo *TodoItem, store *FileTodoStore) error {
+func Complete(todos *[]TodoItem, todo *TodoItem, store TodoStore) error {
 	id := todo.TaskID
 	if err := validator.ValidateTaskID(id); err != nil {
 		return err
@@ -19,7 +22,20 @@ func Complete(todos *[]TodoItem, todo *TodoItem, store *FileTodoStore) error {
 		if (*todos)[i].TaskID == id {
 			task := &(*todos)[i]
 			taskName := task.TaskName
+			// Paused and cancelled series have no occurrence to complete
+			if task.IsRecurring {
+				if _, err := findRecurring(todos, id, "active"); err != nil {
+					return err
+				}
+			}
 			logger.Debugf("Completing task ID %d: %s - %s", id, task.TaskName, task.TaskDesc)
+			beginOperation(store, "complete", fmt.Sprintf("Complete task %d: %s", id, taskName))
+
+			// Task files are upgraded on load, but legacy recurring tasks can
+			// still arrive from other stores; convert them to the occurrence model
+			if task.IsRecurring && len(task.OccurrenceHistory) == 0 {
+				migration.ConvertPeriodCompletions(task)
+			}
 
 			// Handle recurring tasks with new occurrence-based model
 			if task.IsRecurring && len(task.OccurrenceHistory) > 0 {
@@ -49,6 +65,7 @@ func Complete(todos *[]TodoItem, todo *TodoItem, store *FileTodoStore) error {
 						// Check if max count is reached
 						if task.RecurringMaxCount > 0 && task.CompletionCount >= task.RecurringMaxCount {
 							task.Status = "completed"
+							task.CompletedAt = time.Now()
 							err := store.Save(*todos, false)
 							if err != nil {
 								return fmt.Errorf("failed to save updated todos: %w", err)
@@ -60,14 +77,26 @@ func Complete(todos *[]TodoItem, todo *TodoItem, store *FileTodoStore) error {
 						}
 
 						// Create occurrences for next period
-						nextPeriodOccurrences := CreateNextPeriodOccurrences(task)
+						nextPeriodOccurrences := CreateNextPeriodOccurrences(task, time.Now())
 						task.OccurrenceHistory = append(task.OccurrenceHistory, nextPeriodOccurrences...)
 
 						// Update EndTime to first occurrence of next period
-						if len(nextPeriodOccurrences) > 0 {
-							task.EndTime = nextPeriodOccurrences[0].ScheduledTime
-							task.DueDate = nextPeriodOccurrences[0].ScheduledTime.Format("2006-01-02")
+						nextOcc, _ := GetNextPendingOccurrence(task)
+						if nextOcc == nil {
+							// The rule has no more periods (COUNT or UNTIL)
+							task.Status = "completed"
+							task.CompletedAt = time.Now()
+							err := store.Save(*todos, false)
+							if err != nil {
+								return fmt.Errorf("failed to save updated todos: %w", err)
+							}
+
+							logger.Infof("Recurring task completed its last scheduled period. Total periods: %d", task.CompletionCount)
+							fmt.Printf("✅ Period completed! (%d - Last scheduled period) 🎉\n", task.CompletionCount)
+							return nil
 						}
+						task.EndTime = nextOcc.ScheduledTime
+						task.DueDate = nextOcc.ScheduledTime.Format("2006-01-02")
 
 						err := store.Save(*todos, false)
 						if err != nil {
@@ -91,18 +120,16 @@ func Complete(todos *[]TodoItem, todo *TodoItem, store *FileTodoStore) error {
 						task.EndTime = nextOcc.ScheduledTime
 						task.DueDate = nextOcc.ScheduledTime.Format("2006-01-02")
 
-						// Count completed occurrences in current week
-						now := time.Now()
-						weekStart := now
-						for weekStart.Weekday() != time.Sunday {
-							weekStart = weekStart.AddDate(0, 0, -1)
-						}
-						weekEnd := weekStart.AddDate(0, 0, 7)
+						// Count completed occurrences in the current period
+						periodStart, periodEnd := currentPeriod(task)
 
-						completedInWeek := 0
+						completedInPeriod, inPeriod := 0, 0
 						for _, occ := range task.OccurrenceHistory {
-							if !occ.ScheduledTime.Before(weekStart) && occ.ScheduledTime.Before(weekEnd) && occ.Status == "completed" {
-								completedInWeek++
+							if t := seriesTime(occ); !t.Before(periodStart) && t.Before(periodEnd) {
+								inPeriod++
+								if occ.Status == "completed" {
+									completedInPeriod++
+								}
 							}
 						}
 
@@ -111,7 +138,7 @@ func Complete(todos *[]TodoItem, todo *TodoItem, store *FileTodoStore) error {
 							return fmt.Errorf("failed to save updated todos: %w", err)
 						}
 
-						progressDisplay := fmt.Sprintf("%d/%d in this period", completedInWeek, len(task.RecurringWeekdays))
+						progressDisplay := fmt.Sprintf("%d/%d in this period", completedInPeriod, inPeriod)
 						logger.Infof("Sub-task completed. Progress: %s, Next: %s", progressDisplay, nextOcc.ScheduledTime.Format("2006-01-02 15:04"))
 						fmt.Printf("✅ Sub-task completed! (%s) Next: %s\n", progressDisplay, nextOcc.ScheduledTime.Format("2006-01-02 15:04"))
 						return nil
@@ -125,6 +152,7 @@ func Complete(todos *[]TodoItem, todo *TodoItem, store *FileTodoStore) error {
 				// Check if max count is reached
 				if task.RecurringMaxCount > 0 && task.CompletionCount >= task.RecurringMaxCount {
 					task.Status = "completed"
+					task.CompletedAt = time.Now()
 					err := store.Save(*todos, false)
 					if err != nil {
 						return fmt.Errorf("failed to save updated todos: %w", err)
@@ -135,14 +163,26 @@ func Complete(todos *[]TodoItem, todo *TodoItem, store *FileTodoStore) error {
 					return nil
 				}
 
-				// Create next occurrence
-				nextOccurrences := CreateNextPeriodOccurrences(task)
-				task.OccurrenceHistory = append(task.OccurrenceHistory, nextOccurrences...)
+				// Create the next occurrence from where the series had this one,
+				// in case it was rescheduled
+				task.EndTime = lastScheduled(task)
+				nextOccurrences := CreateNextPeriodOccurrences(task, time.Now())
+				if len(nextOccurrences) == 0 {
+					// The rule has no more occurrences (COUNT or UNTIL)
+					task.Status = "completed"
+					task.CompletedAt = time.Now()
+					err := store.Save(*todos, false)
+					if err != nil {
+						return fmt.Errorf("failed to save updated todos: %w", err)
+					}
 
-				if len(nextOccurrences) > 0 {
-					task.EndTime = nextOccurrences[0].ScheduledTime
-					task.DueDate = nextOccurrences[0].ScheduledTime.Format("2006-01-02")
+					logger.Infof("Recurring task completed its last scheduled occurrence. Total completions: %d", task.CompletionCount)
+					fmt.Printf("✅ Task completed! (%d - Last scheduled occurrence) 🎉\n", task.CompletionCount)
+					return nil
 				}
+				task.OccurrenceHistory = append(task.OccurrenceHistory, nextOccurrences...)
+				task.EndTime = nextOccurrences[0].ScheduledTime
+				task.DueDate = nextOccurrences[0].ScheduledTime.Format("2006-01-02")
 
 				err := store.Save(*todos, false)
 				if err != nil {
@@ -160,14 +200,9 @@ func Complete(todos *[]TodoItem, todo *TodoItem, store *FileTodoStore) error {
 				return nil
 			}
 
-			// Handle legacy recurring tasks (with CurrentPeriodCompletions) - migrate to new model
-			if task.IsRecurring && len(task.CurrentPeriodCompletions) > 0 {
-				// TODO: Migration logic for old format
-				return fmt.Errorf("please recreate this recurring task to use the new occurrence tracking system")
-			}
-
 			// Non-recurring task: mark as completed
 			task.Status = "completed"
+			task.CompletedAt = time.Now()
 
 			err := store.Save(*todos, false)
 			if err != nil {
@@ -227,74 +262,128 @@ func IsPeriodCompletedNew(task *TodoItem) bool {
 		return false
 	}
 
-	// For weekday-specific weekly tasks, check if all occurrences in current week are completed
+	// For weekday-specific weekly tasks, check if all occurrences in the current period are completed
 	if task.RecurringType == "weekly" && len(task.RecurringWeekdays) > 0 {
-		now := time.Now()
-		weekStart := now
-		for weekStart.Weekday() != time.Sunday {
-			weekStart = weekStart.AddDate(0, 0, -1)
-		}
-		weekEnd := weekStart.AddDate(0, 0, 7)
+		periodStart, periodEnd := currentPeriod(task)
 
-		pendingInCurrentWeek := 0
-		completedInCurrentWeek := 0
+		pendingInPeriod := 0
+		completedInPeriod := 0
 
 		for _, occ := range task.OccurrenceHistory {
-			if !occ.ScheduledTime.Before(weekStart) && occ.ScheduledTime.Before(weekEnd) {
+			if t := seriesTime(occ); !t.Before(periodStart) && t.Before(periodEnd) {
 				if occ.Status == "pending" {
-					pendingInCurrentWeek++
+					pendingInPeriod++
 				} else if occ.Status == "completed" {
-					completedInCurrentWeek++
+					completedInPeriod++
 				}
 			}
 		}
 
-		// Period is completed if no pending occurrences left in current week
+		// Period is completed if no pending occurrences left in the period
 		// and we have completed at least some occurrences
-		return pendingInCurrentWeek == 0 && completedInCurrentWeek > 0
+		return pendingInPeriod == 0 && completedInPeriod > 0
 	}
 
 	// For other types, a single completion marks the period as complete
 	return false
 }
 
+// currentPeriod returns the week, from the week start of its rule, of the
+// latest occurrence scheduled for a weekday task. Periods are created one at
+// a time, so it holds the occurrences being worked through.
+func currentPeriod(task *TodoItem) (time.Time, time.Time) {
+	start := weekOf(lastScheduled(task), taskWeekStart(task))
+	return start, start.AddDate(0, 0, 7)
+}
+
+// lastScheduled returns the latest time the series of a recurring task has
+// scheduled an occurrence for, or its EndTime when it has no history
+func lastScheduled(task *TodoItem) time.Time {
+	var last time.Time
+	for _, occ := range task.OccurrenceHistory {
+		if t := seriesTime(occ); t.After(last) {
+			last = t
+		}
+	}
+	if last.IsZero() {
+		return task.EndTime
+	}
+	return last
+}
+
+// seriesTime returns the time the series scheduled occ for, which for a
+// rescheduled occurrence is where it was before it moved
+func seriesTime(occ OccurrenceRecord) time.Time {
+	if occ.OriginalTime.IsZero() {
+		return occ.ScheduledTime
+	}
+	return occ.OriginalTime
+}
+
+// weekOf returns the start of the week of t, for weeks starting on first
+func weekOf(t time.Time, first time.Weekday) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -((int(day.Weekday()) - int(first) + 7) % 7))
+}
+
+// taskWeekStart returns the first day of the weeks of a task's rule (its
+// WKST), which the periods of a weekday task follow
+func taskWeekStart(task *TodoItem) time.Weekday {
+	rule, err := recurrenceRule(task)
+	if err != nil {
+		return time.Sunday
+	}
+	return rule.WeekStart
+}
+
 // CreateNextPeriodOccurrences creates occurrence records for the next period
-func CreateNextPeriodOccurrences(task *TodoItem) []OccurrenceRecord {
+func CreateNextPeriodOccurrences(task *TodoItem, now time.Time) []OccurrenceRecord {
 	newOccurrences := []OccurrenceRecord{}
 
 	if !task.IsRecurring {
 		return newOccurrences
 	}
 
-	// For weekday-specific weekly tasks, create occurrences for next week
+	// For weekday-specific weekly tasks, create the occurrences of the next
+	// period the task's rule falls on after the current one, so every other
+	// week stays every other week however late the period was completed.
+	// Periods that passed entirely in the meantime are recorded as missed.
 	if task.RecurringType == "weekly" && len(task.RecurringWeekdays) > 0 {
-		// Find the start of next week
-		now := time.Now()
-		nextWeekStart := now
-		for nextWeekStart.Weekday() != time.Sunday {
-			nextWeekStart = nextWeekStart.AddDate(0, 0, -1)
+		rule, err := recurrenceRule(task)
+		if err != nil {
+			logger.Warnf("Task %d: %v, no next period created", task.TaskID, err)
+			return newOccurrences
 		}
-		nextWeekStart = nextWeekStart.AddDate(0, 0, 7) // Move to next week
-
-		// Create occurrences for each required weekday
-		for _, weekday := range task.RecurringWeekdays {
-			scheduledTime := nextWeekStart.AddDate(0, 0, weekday)
-
-			// Preserve the time of day from the task's EndTime
-			scheduledTime = time.Date(
-				scheduledTime.Year(), scheduledTime.Month(), scheduledTime.Day(),
-				task.EndTime.Hour(), task.EndTime.Minute(), task.EndTime.Second(),
-				0, task.EndTime.Location(),
-			)
-
-			newOccurrences = append(newOccurrences, OccurrenceRecord{
-				ScheduledTime: scheduledTime,
-				Status:        "pending",
-			})
+		start := recurrenceStart(task)
+		_, from := currentPeriod(task)
+		for {
+			next := rule.After(start, from.Add(-time.Nanosecond))
+			if next.IsZero() {
+				break // The rule has ended
+			}
+			periodStart := weekOf(next, rule.WeekStart)
+			from = periodStart.AddDate(0, 0, 7)
+			status := "pending"
+			if !from.After(now) {
+				status = "missed"
+			}
+			for _, scheduledTime := range rule.Between(start, periodStart, from) {
+				newOccurrences = append(newOccurrences, OccurrenceRecord{
+					ScheduledTime: scheduledTime,
+					Status:        status,
+				})
+			}
+			if status == "pending" {
+				break
+			}
 		}
 	} else {
-		// For other recurring types, create a single next occurrence
+		// For other recurring types, create a single next occurrence unless
+		// the rule has ended
 		nextTime := calculateNextOccurrence(task)
+		if nextTime.IsZero() {
+			return newOccurrences
+		}
 		newOccurrences = append(newOccurrences, OccurrenceRecord{
 			ScheduledTime: nextTime,
 			Status:        "pending",
@@ -304,21 +393,23 @@ func CreateNextPeriodOccurrences(task *TodoItem) []OccurrenceRecord {
 	return newOccurrences
 }
 
-// MarkMissedOccurrences marks overdue pending occurrences as missed
-func MarkMissedOccurrences(task *TodoItem) int {
+// MarkMissedOccurrences marks the pending occurrences of a recurring task
+// that ended on a day before now as missed. Occurrences stay pending for the
+// rest of their day, so they can still be completed late.
+func MarkMissedOccurrences(task *TodoItem, now time.Time) int {
 	if !task.IsRecurring {
 		return 0
 	}
 
-	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	missedCount := 0
 
 	for i := range task.OccurrenceHistory {
 		occ := &task.OccurrenceHistory[i]
 		if occ.Status == "pending" {
-			// If scheduled time + event duration has passed, mark as missed
+			// If scheduled time + event duration is before today, mark as missed
 			endTime := occ.ScheduledTime.Add(task.EventDuration)
-			if endTime.Before(now) {
+			if endTime.Before(today) {
 				occ.Status = "missed"
 				missedCount++
 			}
@@ -338,25 +429,14 @@ func initializeOccurrenceHistory(task *TodoItem) []OccurrenceRecord {
 
 	// For weekday-specific weekly tasks, create records for all days in the current period (week)
 	if task.RecurringType == "weekly" && len(task.RecurringWeekdays) > 0 {
-		currentDate := task.EndTime // EndTime is set to the first scheduled occurrence
-
-		// Find the start of the current week (Sunday)
-		weekStart := currentDate
-		for weekStart.Weekday() != time.Sunday {
-			weekStart = weekStart.AddDate(0, 0, -1)
+		rule, err := recurrenceRule(task)
+		if err != nil {
+			logger.Warnf("Task %d: %v, no occurrences created", task.TaskID, err)
+			return history
 		}
-
-		// Create an occurrence for each required weekday in the current period
-		for _, weekday := range task.RecurringWeekdays {
-			scheduledTime := weekStart.AddDate(0, 0, weekday)
-
-			// Preserve the time of day from EndTime
-			scheduledTime = time.Date(
-				scheduledTime.Year(), scheduledTime.Month(), scheduledTime.Day(),
-				task.EndTime.Hour(), task.EndTime.Minute(), task.EndTime.Second(),
-				0, task.EndTime.Location(),
-			)
-
+		// EndTime is set to the first scheduled occurrence
+		weekStart := weekOf(task.EndTime, rule.WeekStart)
+		for _, scheduledTime := range rule.Between(recurrenceStart(task), weekStart, weekStart.AddDate(0, 0, 7)) {
 			// Only add if it's in the future or today
 			if !scheduledTime.Before(time.Now().Truncate(24 * time.Hour)) {
 				history = append(history, OccurrenceRecord{
@@ -377,64 +457,71 @@ func initializeOccurrenceHistory(task *TodoItem) []OccurrenceRecord {
 	return history
 }
 
-// calculateNextOccurrence calculates the next occurrence time based on recurring type and interval
+// calculateNextOccurrence returns the occurrence of a recurring task's rule
+// that follows its EndTime, or the zero time when the rule has ended
 func calculateNextOccurrence(task *TodoItem) time.Time {
-	current := task.EndTime
-	recurringType := task.RecurringType
-	interval := task.RecurringInterval
-
-	switch recurringType {
-	case "daily":
-		return current.AddDate(0, 0, interval)
-
-	case "weekly":
-		// Check if specific weekdays are set
-		if len(task.RecurringWeekdays) > 0 {
-			return calculateNextWeekday(current, task.RecurringWeekdays)
-		}
-		// Default weekly behavior: add interval weeks
-		return current.AddDate(0, 0, interval*7)
-
-	case "monthly":
-		return current.AddDate(0, interval, 0)
-
-	case "yearly":
-		return current.AddDate(interval, 0, 0)
-
-	default:
-		// Default to daily if type is unknown
-		logger.Warnf("Unknown recurring type: %s, defaulting to daily", recurringType)
-		return current.AddDate(0, 0, 1)
+	rule, err := recurrenceRule(task)
+	if err != nil {
+		// Default to daily if the schedule is unknown
+		logger.Warnf("Task %d: %v, defaulting to daily", task.TaskID, err)
+		return task.EndTime.AddDate(0, 0, 1)
 	}
+	return rule.After(recurrenceStart(task), task.EndTime)
 }
 
-// calculateNextWeekday finds the next occurrence for specific weekdays
-// weekdays is an array of integers (0=Sunday, 1=Monday, ..., 6=Saturday)
-func calculateNextWeekday(current time.Time, weekdays []int) time.Time {
-	if len(weekdays) == 0 {
-		return current.AddDate(0, 0, 7) // Default to next week same day
+// recurrenceRule returns the rule the occurrences of a recurring task
+// follow: its RecurrenceRule, or for tasks without one the rule that its
+// type, interval and weekdays describe
+func recurrenceRule(task *TodoItem) (*rrule.Rule, error) {
+	if task.RecurrenceRule != "" {
+		return rrule.Parse(task.RecurrenceRule)
 	}
+	return rrule.FromFields(task.RecurringType, task.RecurringInterval, task.RecurringWeekdays)
+}
 
-	// Convert weekdays slice to map for quick lookup
-	weekdaySet := make(map[int]bool)
-	for _, day := range weekdays {
-		weekdaySet[day] = true
+// recurrenceStart returns the first occurrence a task's rule counts from
+func recurrenceStart(task *TodoItem) time.Time {
+	if task.RecurrenceStart.IsZero() {
+		return task.EndTime
 	}
+	return task.RecurrenceStart
+}
 
-	// Start from next day
-	next := current.AddDate(0, 0, 1)
-
-	// Search for the next matching weekday (max 7 days)
-	for i := 0; i < 7; i++ {
-		currentWeekday := int(next.Weekday())
-		if weekdaySet[currentWeekday] {
-			return next
-		}
-		next = next.AddDate(0, 0, 1)
+// setUpRecurrence describes the schedule of a new recurring task by a rule.
+// A task given a rule takes the type, interval and weekdays the rule amounts
+// to; otherwise those fields make its rule. EndTime moves to the first
+// occurrence from there, which the rule starts at unless it has a start.
+func setUpRecurrence(task *TodoItem) error {
+	if task.RecurrenceRule == "" && task.RecurringType == "" {
+		return fmt.Errorf("recurring tasks need a recurring type or a recurrence rule")
+	}
+	rule, err := recurrenceRule(task)
+	if err != nil {
+		return fmt.Errorf("invalid recurrence rule: %w", err)
+	}
+	task.RecurrenceRule = rule.String()
+	task.RecurringType = strings.ToLower(string(rule.Freq))
+	task.RecurringInterval = rule.Interval
+	task.RecurringWeekdays = rule.Weekdays()
+	if task.EndTime.IsZero() {
+		return nil
 	}
 
-	// Fallback (should never reach here if weekdays is not empty)
-	return current.AddDate(0, 0, 7)
+	start := task.RecurrenceStart
+	if start.IsZero() {
+		start = task.EndTime
+	}
+	first := rule.After(start, task.EndTime.Add(-time.Nanosecond))
+	if first.IsZero() {
+		return fmt.Errorf("recurrence rule %s has no occurrence after %s", task.RecurrenceRule,
+			task.EndTime.Format("2006-01-02 15:04"))
+	}
+	if task.RecurrenceStart.IsZero() {
+		task.RecurrenceStart = first
+	}
+	task.EndTime = first
+	task.DueDate = first.Format("2006-01-02")
+	return nil
 }
 
 // findNextInCurrentPeriod finds the next date to complete in the current period
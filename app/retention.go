@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/logger"
+)
+
+// RetentionPolicy says how long the backup list keeps deleted and completed
+// tasks. A zero age keeps those tasks forever.
+type RetentionPolicy struct {
+	DeletedAfter   time.Duration
+	CompletedAfter time.Duration
+}
+
+// PurgeBackup permanently removes the tasks of the backup list that have
+// outlived policy at now and returns them. With dryRun set the backup list
+// is left alone.
+func PurgeBackup(store TodoStore, now time.Time, policy RetentionPolicy, dryRun bool) ([]TodoItem, error) {
+	backupTodos, err := store.Load(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load backup: %w", err)
+	}
+
+	var purged []TodoItem
+	kept := make([]TodoItem, 0, len(backupTodos))
+	for _, task := range backupTodos {
+		if expired(task, now, policy) {
+			purged = append(purged, task)
+		} else {
+			kept = append(kept, task)
+		}
+	}
+	if len(purged) == 0 || dryRun {
+		return purged, nil
+	}
+
+	logger.Debugf("Purging %d tasks from backup", len(purged))
+	beginOperation(store, "purge", fmt.Sprintf("Purge %d tasks from backup", len(purged)))
+	if err := store.Save(kept, true); err != nil {
+		return nil, fmt.Errorf("failed to save backup: %w", err)
+	}
+	return purged, nil
+}
+
+// expired reports whether policy no longer keeps the backup task at now
+func expired(task TodoItem, now time.Time, policy RetentionPolicy) bool {
+	switch task.Status {
+	case "deleted":
+		return policy.DeletedAfter > 0 && !now.Before(deletedTime(task).Add(policy.DeletedAfter))
+	case "completed":
+		return policy.CompletedAfter > 0 && !now.Before(completedTime(task).Add(policy.CompletedAfter))
+	}
+	return false
+}
+
+// deletedTime returns when task was deleted. Tasks deleted before deletion
+// times were recorded are aged like completed tasks.
+func deletedTime(task TodoItem) time.Time {
+	if !task.DeletedAt.IsZero() {
+		return task.DeletedAt
+	}
+	return completedTime(task)
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/logger"
+)
+
+// PauseRecurring pauses active recurring task id. While it is paused no
+// occurrences are scheduled or marked missed. With a non-zero until it
+// resumes by itself on that day; otherwise it waits for ResumeRecurring.
+func PauseRecurring(todos *[]TodoItem, id int, until time.Time, store TodoStore, now time.Time) error {
+	task, err := findRecurring(todos, id, "active")
+	if err != nil {
+		return err
+	}
+	if !until.IsZero() && !until.After(now) {
+		return fmt.Errorf("cannot pause until %s, which has passed", until.Format("2006-01-02"))
+	}
+
+	logger.Debugf("Pausing recurring task %d: %s", id, task.TaskName)
+	beginOperation(store, "pause", fmt.Sprintf("Pause task %d: %s", id, task.TaskName))
+	task.Status = "paused"
+	task.PausedUntil = until
+	if err := store.Save(*todos, false); err != nil {
+		return fmt.Errorf("failed to save updated todos: %w", err)
+	}
+	return nil
+}
+
+// ResumeRecurring resumes paused recurring task id, scheduling it afresh
+// from the day of now
+func ResumeRecurring(todos *[]TodoItem, id int, store TodoStore, now time.Time) error {
+	task, err := findRecurring(todos, id, "paused")
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Resuming recurring task %d: %s", id, task.TaskName)
+	beginOperation(store, "resume", fmt.Sprintf("Resume task %d: %s", id, task.TaskName))
+	if err := resume(task, now, now); err != nil {
+		return err
+	}
+	if err := store.Save(*todos, false); err != nil {
+		return fmt.Errorf("failed to save updated todos: %w", err)
+	}
+	return nil
+}
+
+// CancelRecurring ends active or paused recurring task id for good. Its
+// pending occurrences are dropped; the ones that took place stay in its
+// history.
+func CancelRecurring(todos *[]TodoItem, id int, store TodoStore) error {
+	task, err := findRecurring(todos, id, "active", "paused")
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Cancelling recurring task %d: %s", id, task.TaskName)
+	beginOperation(store, "cancel", fmt.Sprintf("Cancel task %d: %s", id, task.TaskName))
+	task.Status = "cancelled"
+	task.PausedUntil = time.Time{}
+	task.OccurrenceHistory = withoutPending(task.OccurrenceHistory)
+	if err := store.Save(*todos, false); err != nil {
+		return fmt.Errorf("failed to save updated todos: %w", err)
+	}
+	return nil
+}
+
+// resume makes a paused task active again with the occurrences its rule
+// has from the day of from on, in place of the pending ones it had when it
+// was paused. A task whose rule has none left is completed at now.
+func resume(task *TodoItem, from, now time.Time) error {
+	rule, err := recurrenceRule(task)
+	if err != nil {
+		return fmt.Errorf("task %d: invalid recurrence rule: %w", task.TaskID, err)
+	}
+	task.Status = "active"
+	task.PausedUntil = time.Time{}
+	task.OccurrenceHistory = withoutPending(task.OccurrenceHistory)
+
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	start := recurrenceStart(task)
+	first := rule.After(start, from.Add(-time.Nanosecond))
+	if first.IsZero() {
+		// The rule has no more occurrences (COUNT or UNTIL)
+		task.Status = "completed"
+		task.CompletedAt = now
+		return nil
+	}
+
+	// Weekday tasks get the rest of the week of the first occurrence,
+	// others just that occurrence
+	next := []time.Time{first}
+	if task.RecurringType == "weekly" && len(task.RecurringWeekdays) > 0 {
+		weekStart := weekOf(first, rule.WeekStart)
+		next = rule.Between(start, first, weekStart.AddDate(0, 0, 7))
+	}
+	for _, scheduledTime := range next {
+		if !hasOccurrenceAt(task.OccurrenceHistory, scheduledTime) {
+			task.OccurrenceHistory = append(task.OccurrenceHistory, OccurrenceRecord{
+				ScheduledTime: scheduledTime,
+				Status:        "pending",
+			})
+		}
+	}
+	task.EndTime = first
+	task.DueDate = first.Format("2006-01-02")
+	return nil
+}
+
+// withoutPending returns history without its pending occurrences
+func withoutPending(history []OccurrenceRecord) []OccurrenceRecord {
+	kept := make([]OccurrenceRecord, 0, len(history))
+	for _, occ := range history {
+		if occ.Status != "pending" {
+			kept = append(kept, occ)
+		}
+	}
+	return kept
+}
+
+// hasOccurrenceAt reports whether history has an occurrence at t
+func hasOccurrenceAt(history []OccurrenceRecord, t time.Time) bool {
+	for _, occ := range history {
+		if occ.ScheduledTime.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/SongRunqi/go-todo/internal/validator"
 )
 
-func RestoreTask(todos *[]TodoItem, backupTodos *[]TodoItem, id int, store *FileTodoStore) error {
+func RestoreTask(todos *[]TodoItem, backupTodos *[]TodoItem, id int, store TodoStore) error {
 	if err := validator.ValidateTaskID(id); err != nil {
 		return err
 	}
@@ -31,10 +31,25 @@ func RestoreTask(todos *[]TodoItem, backupTodos *[]TodoItem, id int, store *File
 	}
 
 	logger.Debugf("Found task to restore - ID %d: %s", id, taskToRestore.TaskName)
+	beginOperation(store, "restore", fmt.Sprintf("Restore task %d: %s", id, taskToRestore.TaskName))
 
 	// Change status back to pending
 	restoredTask := *taskToRestore
 	restoredTask.Status = "pending"
+	restoredTask.CompletedAt = time.Time{}
+	restoredTask.ArchivedAt = time.Time{}
+	restoredTask.DeletedAt = time.Time{}
+
+	// Tasks saved before IDs were allocated globally may share an ID with an
+	// active task; give the restored task a fresh one rather than a duplicate
+	if hasTaskID(*todos, id) {
+		newID, err := NextTaskID(store, *todos)
+		if err != nil {
+			return err
+		}
+		restoredTask.TaskID = newID
+		output.PrintWarning("Task %d is already used by an active task; restored as task %d", id, newID)
+	}
 
 	// Add to active todos
 	*todos = append(*todos, restoredTask)
@@ -61,11 +76,71 @@ func RestoreTask(todos *[]TodoItem, backupTodos *[]TodoItem, id int, store *File
 	}
 
 	logger.Debug("Task restored successfully")
-	output.PrintTaskRestored(id, restoredTask.TaskName)
+	output.PrintTaskRestored(restoredTask.TaskID, restoredTask.TaskName)
 	return nil
 }
 
-func CopyCompletedTasks(todos *[]TodoItem, store *FileTodoStore, weekOnly bool) error {
+// hasTaskID reports whether a task in todos has the given ID
+func hasTaskID(todos []TodoItem, id int) bool {
+	for _, t := range todos {
+		if t.TaskID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// MoveTask moves the active task id from store (the list named from) to the
+// end of the target store's active list (named to). The task gets a new ID
+// from the target store, which is returned. The target is saved first,
+// so a failure part way leaves the task in both lists rather than in neither.
+func MoveTask(todos *[]TodoItem, id int, store TodoStore, from string, target TodoStore, to string) (int, error) {
+	if err := validator.ValidateTaskID(id); err != nil {
+		return 0, err
+	}
+
+	taskIndex := -1
+	for i := range *todos {
+		if (*todos)[i].TaskID == id {
+			taskIndex = i
+			break
+		}
+	}
+	if taskIndex < 0 {
+		return 0, fmt.Errorf("task with ID %d not found", id)
+	}
+	task := (*todos)[taskIndex]
+
+	targetTodos, err := target.Load(false)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load list %s: %w", to, err)
+	}
+	moved := task
+	moved.TaskID, err = NextTaskID(target, targetTodos)
+	if err != nil {
+		return 0, err
+	}
+	targetTodos = append(targetTodos, moved)
+
+	beginOperation(target, "move", fmt.Sprintf("Move task %d from %s as %d: %s", id, from, moved.TaskID, task.TaskName))
+	if err := target.Save(targetTodos, false); err != nil {
+		return 0, fmt.Errorf("failed to save list %s: %w", to, err)
+	}
+
+	beginOperation(store, "move", fmt.Sprintf("Move task %d to %s as %d: %s", id, to, moved.TaskID, task.TaskName))
+	newTodos := make([]TodoItem, 0, len(*todos)-1)
+	newTodos = append(newTodos, (*todos)[:taskIndex]...)
+	newTodos = append(newTodos, (*todos)[taskIndex+1:]...)
+	if err := store.Save(newTodos, false); err != nil {
+		return 0, fmt.Errorf("task was copied to %s but could not be removed from %s: %w", to, from, err)
+	}
+	*todos = newTodos
+
+	logger.Debugf("Moved task %d to list %s as %d", id, to, moved.TaskID)
+	return moved.TaskID, nil
+}
+
+func CopyCompletedTasks(todos *[]TodoItem, store TodoStore, weekOnly bool) error {
 	// Collect completed tasks from both main list and backup
 	completedTasks := make([]TodoItem, 0)
 
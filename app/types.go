@@ -11,9 +11,15 @@ import (
 type TodoItem = domain.TodoItem
 type OccurrenceRecord = domain.OccurrenceRecord
 type TodoStore = domain.TodoStore
+type Locker = domain.Locker
 
 // Re-export repository types for backward compatibility
 type FileTodoStore = repository.FileTodoStore
+type ConflictError = repository.ConflictError
+
+// ErrConflict is returned (wrapped) when a save would overwrite changes made
+// to the task files after they were loaded
+var ErrConflict = repository.ErrConflict
 
 // Re-export config types for backward compatibility
 type Config = config.Config
@@ -23,6 +29,26 @@ func LoadConfig() Config {
 	return config.Load()
 }
 
+// LoadListConfig loads the configuration for the named task list; an empty
+// name selects the default list
+func LoadListConfig(name string) Config {
+	return config.LoadList(name)
+}
+
+// OpenStore returns the storage backend selected by cfg.Storage; the
+// in-memory store is for tests only and is never returned
+func OpenStore(cfg Config) (TodoStore, error) {
+	return repository.Open(cfg)
+}
+
+// beginOperation tells a journaling store that the saves which follow make
+// up one operation, so they are recorded (and later undone) together
+func beginOperation(store TodoStore, op, desc string) {
+	if recorder, ok := domain.As[domain.OperationRecorder](store); ok {
+		recorder.BeginOperation(op, desc)
+	}
+}
+
 // AlfredResponse the json structure "return" to Alfred
 // Alfred1
 type AlfredResponse struct {
@@ -46,11 +46,15 @@ func TransToAlfredItem(todos *[]TodoItem) *[]AlfredItem {
 
 		item.Title = "[" + strconv.Itoa(task.TaskID) + "] " + recurringIndicator + "🎯" + task.TaskName + " " + task.Urgent
 
-		completed := task.Status == "completed"
 		var prefix string = ""
-		if completed {
+		switch task.Status {
+		case "completed":
 			prefix = "✅"
-		} else {
+		case "paused":
+			prefix = "⏸️"
+		case "cancelled":
+			prefix = "🚫"
+		default:
 			prefix = "⌛️"
 		}
 		item.Subtitle = prefix + task.TaskDesc
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var archiveOlderThan int
+
+// errArchiveOff is returned by todo archive without an age to archive by
+var errArchiveOff = errors.New(`no archive policy is configured; set "archiveAfterDays" in ~/.todo/config.json (or TODO_ARCHIVE_AFTER_DAYS), or pass --older-than`)
+
+// archiveCmd moves old completed tasks to the backup list
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "",
+	Long:  "",
+	Example: `  todo archive
+  todo archive --older-than 3`,
+	Args: cobra.NoArgs,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Skip the automatic archiving, so this command reports what it moves
+		setupApp(cmd, true)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		days := ctx.Config.ArchiveAfterDays
+		if cmd.Flags().Changed("older-than") {
+			days = archiveOlderThan
+		} else if days <= 0 {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), errArchiveOff)
+			os.Exit(1)
+		}
+		if err := runArchive(ctx, days); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	archiveCmd.Flags().IntVar(&archiveOlderThan, "older-than", 0, "Archive completed tasks at least this many days old")
+}
+
+func runArchive(ctx *AppContext, days int) error {
+	if days < 0 {
+		return fmt.Errorf("--older-than must not be negative, got %d", days)
+	}
+	archived, err := app.ArchiveCompleted(ctx.Todos, ctx.Store, ctx.CurrentTime, ageInDays(days))
+	if err != nil {
+		return err
+	}
+	if len(archived) == 0 {
+		output.PrintInfo("%s", i18n.T("cmd.archive.none"))
+		return nil
+	}
+	for _, task := range archived {
+		fmt.Printf("  #%d %s\n", task.TaskID, task.TaskName)
+	}
+	output.PrintSuccess("%s", i18n.T("cmd.archive.success", len(archived)))
+	return nil
+}
+
+// ageInDays converts an age in days to a duration
+func ageInDays(days int) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+)
+
+func TestRunArchive_MovesOldCompletedTasks(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	ctx, store := newTestContext(t, []app.TodoItem{
+		{TaskID: 1, TaskName: "Old", Status: "completed", CompletedAt: now.AddDate(0, 0, -8)},
+		{TaskID: 2, TaskName: "Recent", Status: "completed", CompletedAt: now.AddDate(0, 0, -2)},
+		{TaskID: 3, TaskName: "Open", Status: "pending", EndTime: now.AddDate(0, 0, -30)},
+		{TaskID: 4, TaskName: "Legacy", Status: "completed", EndTime: now.AddDate(0, -1, 0)},
+	})
+	ctx.CurrentTime = now
+
+	if err := runArchive(ctx, 7); err != nil {
+		t.Fatalf("runArchive failed: %v", err)
+	}
+
+	active, _ := store.Load(false)
+	if len(active) != 2 || active[0].TaskID != 2 || active[1].TaskID != 3 {
+		t.Errorf("Expected tasks 2 and 3 to stay active, got %+v", active)
+	}
+	backup, _ := store.Load(true)
+	if len(backup) != 2 || backup[0].TaskID != 1 || backup[1].TaskID != 4 {
+		t.Fatalf("Expected tasks 1 and 4 in backup, got %+v", backup)
+	}
+	for _, task := range backup {
+		if task.Status != "completed" || !task.ArchivedAt.Equal(now) {
+			t.Errorf("Expected an archived completed task, got %+v", task)
+		}
+	}
+}
+
+func TestApplyArchivePolicy(t *testing.T) {
+	now := time.Now()
+	ctx, store := newTestContext(t, []app.TodoItem{
+		{TaskID: 1, TaskName: "Done", Status: "completed", CompletedAt: now.AddDate(0, 0, -3)},
+	})
+
+	applyArchivePolicy(ctx)
+	if active, _ := store.Load(false); len(active) != 1 {
+		t.Fatalf("Expected nothing archived without a policy, got %+v", active)
+	}
+
+	ctx.Config.ArchiveAfterDays = 2
+	applyArchivePolicy(ctx)
+	if len(*ctx.Todos) != 0 {
+		t.Errorf("Expected the task to be archived, got %+v", *ctx.Todos)
+	}
+	if backup, _ := store.Load(true); len(backup) != 1 || backup[0].Status != "completed" {
+		t.Errorf("Expected the completed task in backup, got %+v", backup)
+	}
+}
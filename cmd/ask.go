@@ -24,7 +24,8 @@ var askCmd = &cobra.Command{
 	Long:  "",
 	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		err := ask(args)
+		ctx := getAppContext(cmd)
+		err := ask(ctx, args)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
 			os.Exit(1)
@@ -37,8 +38,8 @@ func init() {
 	rootCmd.AddCommand(askCmd)
 }
 
-func ask(args []string) error {
-	cfg := app.LoadConfig()
+func ask(ctx *AppContext, args []string) error {
+	cfg := ctx.Config
 
 	now := time.Now()
 	nowStr := now.Format("2006-01-02 15:04:05")
@@ -51,22 +52,16 @@ func ask(args []string) error {
 		userLanguage = "English"
 	}
 
-	// load todos
-	fileStore := app.FileTodoStore{cfg.TodoPath, cfg.BackupPath}
-	load, err := fileStore.Load(false)
-	if err != nil {
-		fmt.Println(err)
-	}
-
 	// Build context in XML format for better structure and clarity
-	bytes, _ := json.Marshal(load)
+	bytes, _ := json.Marshal(*ctx.Todos)
 	contextStr := fmt.Sprintf(`<context>
 	<current_time>%s</current_time>
 	<weekday>%s</weekday>
 	<user_preferred_language>%s</user_preferred_language>
+	<current_list>%s</current_list>
 	<user_input>%s</user_input>
 	<user_todos>%s</user_todos>
-</context>`, nowStr, weekday, userLanguage, args[0], string(bytes))
+</context>`, nowStr, weekday, userLanguage, cfg.List, args[0], string(bytes))
 
 	logger.Debugf("AI context: %s", contextStr)
 
@@ -95,6 +90,6 @@ func ask(args []string) error {
 		return fmt.Errorf("AI request failed: %w", err)
 	}
 
-	return app.DoI(warpIntend, &load, &fileStore)
+	return app.DoI(warpIntend, ctx.Todos, ctx.Store)
 
 }
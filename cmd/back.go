@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/SongRunqi/go-todo/app"
 	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/output"
 )
 
 // backCmd represents the back command
@@ -15,6 +17,9 @@ var backCmd = &cobra.Command{
 	Use:   "back",
 	Short: "",
 	Long:  "",
+	// Listing and reading the backup change nothing; restore and purge
+	// bring the active list up to date first
+	PersistentPreRun: setupReadOnly,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := getAppContext(cmd)
 		// Default: list backup todos
@@ -60,10 +65,11 @@ var backGetCmd = &cobra.Command{
 
 // backRestoreCmd represents the "back restore" command
 var backRestoreCmd = &cobra.Command{
-	Use:   "restore <id>",
-	Short: "",
-	Long:  "",
-	Args:  cobra.ExactArgs(1),
+	Use:              "restore <id>",
+	Short:            "",
+	Long:             "",
+	Args:             cobra.ExactArgs(1),
+	PersistentPreRun: setupWithUpkeep,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := getAppContext(cmd)
 		id, err := strconv.Atoi(args[0])
@@ -85,8 +91,75 @@ var backRestoreCmd = &cobra.Command{
 	},
 }
 
+var (
+	purgeDryRun         bool
+	purgeDeletedAfter   int
+	purgeCompletedAfter int
+)
+
+// errRetentionOff is returned by "back purge" without any age to purge by
+var errRetentionOff = errors.New(`no retention policy is configured; set "keepDeletedDays" or "keepCompletedDays" in ~/.todo/config.json, or pass --deleted-after or --completed-after`)
+
+// backPurgeCmd represents the "back purge" command
+var backPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "",
+	Long:  "",
+	Example: `  todo back purge --dry-run
+  todo back purge --deleted-after 90 --completed-after 365`,
+	Args:             cobra.NoArgs,
+	PersistentPreRun: setupWithUpkeep,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		deletedDays, completedDays := ctx.Config.KeepDeletedDays, ctx.Config.KeepCompletedDays
+		if cmd.Flags().Changed("deleted-after") {
+			deletedDays = purgeDeletedAfter
+		}
+		if cmd.Flags().Changed("completed-after") {
+			completedDays = purgeCompletedAfter
+		}
+		if err := runPurge(ctx, deletedDays, completedDays, purgeDryRun); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(backCmd)
 	backCmd.AddCommand(backGetCmd)
 	backCmd.AddCommand(backRestoreCmd)
+	backCmd.AddCommand(backPurgeCmd)
+
+	backPurgeCmd.Flags().BoolVar(&purgeDryRun, "dry-run", false, "Only show what would be purged")
+	backPurgeCmd.Flags().IntVar(&purgeDeletedAfter, "deleted-after", 0, "Purge deleted tasks at least this many days old (0 keeps them)")
+	backPurgeCmd.Flags().IntVar(&purgeCompletedAfter, "completed-after", 0, "Purge completed tasks at least this many days old (0 keeps them)")
+}
+
+func runPurge(ctx *AppContext, deletedDays, completedDays int, dryRun bool) error {
+	if deletedDays < 0 || completedDays < 0 {
+		return fmt.Errorf("retention ages must not be negative")
+	}
+	if deletedDays == 0 && completedDays == 0 {
+		return errRetentionOff
+	}
+
+	policy := app.RetentionPolicy{DeletedAfter: ageInDays(deletedDays), CompletedAfter: ageInDays(completedDays)}
+	purged, err := app.PurgeBackup(ctx.Store, ctx.CurrentTime, policy, dryRun)
+	if err != nil {
+		return err
+	}
+	if len(purged) == 0 {
+		output.PrintInfo("%s", i18n.T("cmd.back.purge.none"))
+		return nil
+	}
+	for _, task := range purged {
+		fmt.Printf("  #%d %s (%s)\n", task.TaskID, task.TaskName, task.Status)
+	}
+	if dryRun {
+		fmt.Printf(i18n.T("cmd.back.purge.dry_run"), len(purged))
+		return nil
+	}
+	output.PrintSuccess("%s", i18n.T("cmd.back.purge.success", len(purged)))
+	return nil
 }
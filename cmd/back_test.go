@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+)
+
+func TestRestoreTask_RemapsClashingID(t *testing.T) {
+	ctx, store := newTestContext(t, []app.TodoItem{{TaskID: 3, TaskName: "Active", Status: "pending"}})
+	backup := []app.TodoItem{{TaskID: 3, TaskName: "Old", Status: "completed"}}
+	if err := store.Save(backup, true); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := app.RestoreTask(ctx.Todos, &backup, 3, store); err != nil {
+		t.Fatalf("RestoreTask failed: %v", err)
+	}
+
+	active, _ := store.Load(false)
+	if len(active) != 2 || active[0].TaskID != 3 || active[1].TaskID != 4 || active[1].TaskName != "Old" {
+		t.Errorf("Expected the restored task to become #4, got %+v", active)
+	}
+	if saved, _ := store.Load(true); len(saved) != 0 {
+		t.Errorf("Expected the backup to be empty, got %+v", saved)
+	}
+}
+
+func TestRunPurge(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	ctx, store := newTestContext(t, nil)
+	ctx.CurrentTime = now
+	backup := []app.TodoItem{
+		{TaskID: 1, TaskName: "Old delete", Status: "deleted", DeletedAt: now.AddDate(0, 0, -100)},
+		{TaskID: 2, TaskName: "New delete", Status: "deleted", DeletedAt: now.AddDate(0, 0, -10)},
+		{TaskID: 3, TaskName: "Old done", Status: "completed", CompletedAt: now.AddDate(-2, 0, 0)},
+		{TaskID: 4, TaskName: "Legacy delete", Status: "deleted", EndTime: now.AddDate(0, -6, 0)},
+	}
+	if err := store.Save(backup, true); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := runPurge(ctx, 0, 0, false); !errors.Is(err, errRetentionOff) {
+		t.Fatalf("Expected errRetentionOff, got %v", err)
+	}
+
+	if err := runPurge(ctx, 90, 0, true); err != nil {
+		t.Fatalf("runPurge --dry-run failed: %v", err)
+	}
+	if saved, _ := store.Load(true); len(saved) != 4 {
+		t.Fatalf("Expected a dry run to keep every task, got %+v", saved)
+	}
+
+	if err := runPurge(ctx, 90, 0, false); err != nil {
+		t.Fatalf("runPurge failed: %v", err)
+	}
+	saved, _ := store.Load(true)
+	if len(saved) != 2 || saved[0].TaskID != 2 || saved[1].TaskID != 3 {
+		t.Errorf("Expected tasks 2 and 3 to be kept, got %+v", saved)
+	}
+}
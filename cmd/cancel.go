@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// cancelCmd ends a recurring task for good
+var cancelCmd = &cobra.Command{
+	Use:     "cancel <id>",
+	Short:   "",
+	Long:    "",
+	Example: `  todo cancel 3`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.invalid_task_id"), args[0])
+			os.Exit(1)
+		}
+		if err := runCancel(ctx, id); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cancelCmd)
+}
+
+func runCancel(ctx *AppContext, id int) error {
+	err := retryOnConflict(ctx, func() error {
+		return app.CancelRecurring(ctx.Todos, id, ctx.Store)
+	})
+	if err != nil {
+		return err
+	}
+	output.PrintSuccess("%s", i18n.T("cmd.cancel.success", id))
+	return nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+)
+
+func TestRunCancel(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+	stretch := dailyStretch(now)
+	stretch.OccurrenceHistory = append([]app.OccurrenceRecord{
+		{ScheduledTime: stretch.EndTime.AddDate(0, 0, -1), Status: "completed"},
+	}, stretch.OccurrenceHistory...)
+	ctx, store := newTestContext(t, []app.TodoItem{stretch, {TaskID: 2, TaskName: "Once", Status: "pending"}})
+	ctx.CurrentTime = now
+
+	if err := runCancel(ctx, 1); err != nil {
+		t.Fatalf("runCancel failed: %v", err)
+	}
+	active, _ := store.Load(false)
+	history := active[0].OccurrenceHistory
+	if active[0].Status != "cancelled" || len(history) != 1 || history[0].Status != "completed" {
+		t.Errorf("Expected a cancelled task keeping only its completed occurrence, got %+v", active[0])
+	}
+	if changes, _ := app.ReconcileRecurring(ctx.Todos, ctx.Store, now.AddDate(0, 0, 7)); len(changes) != 0 {
+		t.Errorf("Expected a cancelled task to be left alone, got %+v", changes)
+	}
+
+	for _, id := range []int{1, 2, 9} {
+		if err := runCancel(ctx, id); err == nil {
+			t.Errorf("Expected an error cancelling task %d", id)
+		}
+	}
+}
@@ -24,8 +24,11 @@ var completeCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		task := &app.TodoItem{TaskID: id}
-		if err := app.Complete(ctx.Todos, task, ctx.Store); err != nil {
+		err = retryOnConflict(ctx, func() error {
+			task := &app.TodoItem{TaskID: id}
+			return app.Complete(ctx.Todos, task, ctx.Store)
+		})
+		if err != nil {
 			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
 			os.Exit(1)
 		}
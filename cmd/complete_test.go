@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+)
+
+// biweeklyClass returns a Monday/Wednesday task repeating every other week
+// from the Monday weeksAgo weeks before now, with Monday done
+func biweeklyClass(now time.Time, weeksAgo int) app.TodoItem {
+	sunday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	sunday = sunday.AddDate(0, 0, -int(sunday.Weekday())-7*weeksAgo)
+	monday := sunday.AddDate(0, 0, 1).Add(9 * time.Hour)
+	return app.TodoItem{
+		TaskID: 1, TaskName: "Class", Status: "active", EndTime: monday.AddDate(0, 0, 2),
+		IsRecurring: true, RecurringType: "weekly", RecurringInterval: 2, RecurringWeekdays: []int{1, 3},
+		RecurrenceRule: "FREQ=WEEKLY;INTERVAL=2;WKST=SU;BYDAY=MO,WE", RecurrenceStart: monday,
+		OccurrenceHistory: []app.OccurrenceRecord{
+			{ScheduledTime: monday, Status: "completed"},
+			{ScheduledTime: monday.AddDate(0, 0, 2), Status: "pending"},
+		},
+	}
+}
+
+func TestComplete_WeekdayTaskKeepsItsInterval(t *testing.T) {
+	ctx, store := newTestContext(t, []app.TodoItem{biweeklyClass(time.Now(), 0)})
+	monday := (*ctx.Todos)[0].RecurrenceStart
+
+	if err := app.Complete(ctx.Todos, &app.TodoItem{TaskID: 1}, ctx.Store); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	active, _ := store.Load(false)
+	class := active[0]
+	next := monday.AddDate(0, 0, 14)
+	if class.CompletionCount != 1 || !class.EndTime.Equal(next) || len(class.OccurrenceHistory) != 4 {
+		t.Fatalf("Expected the next period two weeks later on %v, got %+v", next, class)
+	}
+	for i, want := range []time.Time{next, next.AddDate(0, 0, 2)} {
+		occ := class.OccurrenceHistory[2+i]
+		if !occ.ScheduledTime.Equal(want) || occ.Status != "pending" {
+			t.Errorf("Occurrence %d: got %v %s, want %v pending", i, occ.ScheduledTime, occ.Status, want)
+		}
+	}
+}
+
+func TestComplete_WeekdayTaskAfterSkippedPeriods(t *testing.T) {
+	// The period six weeks ago is completed late; the two periods since
+	// passed without anyone doing them, and this week's is next
+	ctx, store := newTestContext(t, []app.TodoItem{biweeklyClass(time.Now(), 6)})
+	monday := (*ctx.Todos)[0].RecurrenceStart
+
+	if err := app.Complete(ctx.Todos, &app.TodoItem{TaskID: 1}, ctx.Store); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	active, _ := store.Load(false)
+	class := active[0]
+	if len(class.OccurrenceHistory) != 8 {
+		t.Fatalf("Expected three new periods, got %+v", class.OccurrenceHistory)
+	}
+	for i, weeks := range []int{2, 2, 4, 4, 6, 6} {
+		status := "missed"
+		if weeks == 6 {
+			status = "pending"
+		}
+		want := monday.AddDate(0, 0, 7*weeks+2*(i%2))
+		occ := class.OccurrenceHistory[2+i]
+		if !occ.ScheduledTime.Equal(want) || occ.Status != status {
+			t.Errorf("Occurrence %d: got %v %s, want %v %s", i, occ.ScheduledTime, occ.Status, want, status)
+		}
+	}
+	if thisWeek := monday.AddDate(0, 0, 42); !class.EndTime.Equal(thisWeek) || class.DueDate != thisWeek.Format("2006-01-02") {
+		t.Errorf("Expected EndTime to move to this week's Monday %v, got %v", thisWeek, class.EndTime)
+	}
+}
+
+func TestComplete_WeekdayTaskFollowsTheWeeksOfItsRule(t *testing.T) {
+	// Weeks of a rule without WKST start on Monday, so its Sunday closes
+	// the week its Monday opens
+	today := time.Now()
+	monday := time.Date(today.Year(), today.Month(), today.Day(), 9, 0, 0, 0, time.Local)
+	monday = monday.AddDate(0, 0, -(int(monday.Weekday())+6)%7)
+	sunday := monday.AddDate(0, 0, 6)
+	ctx, store := newTestContext(t, []app.TodoItem{{
+		TaskID: 1, TaskName: "Review", Status: "active", EndTime: sunday,
+		IsRecurring: true, RecurringType: "weekly", RecurringInterval: 2, RecurringWeekdays: []int{0, 1},
+		RecurrenceRule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,SU", RecurrenceStart: monday,
+		OccurrenceHistory: []app.OccurrenceRecord{
+			{ScheduledTime: monday, Status: "completed"},
+			{ScheduledTime: sunday, Status: "pending"},
+		},
+	}})
+
+	if err := app.Complete(ctx.Todos, &app.TodoItem{TaskID: 1}, ctx.Store); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	active, _ := store.Load(false)
+	review := active[0]
+	if review.CompletionCount != 1 || len(review.OccurrenceHistory) != 4 {
+		t.Fatalf("Expected the period to be completed, got %+v", review)
+	}
+	for i, want := range []time.Time{monday.AddDate(0, 0, 14), sunday.AddDate(0, 0, 14)} {
+		if occ := review.OccurrenceHistory[2+i]; !occ.ScheduledTime.Equal(want) || occ.Status != "pending" {
+			t.Errorf("Occurrence %d: got %v %s, want %v pending", i, occ.ScheduledTime, occ.Status, want)
+		}
+	}
+}
@@ -15,9 +15,10 @@ var (
 
 // copyCmd represents the copy command
 var copyCmd = &cobra.Command{
-	Use:   "copy",
-	Short: "Copy completed tasks to clipboard",
-	Long:  "Copy completed tasks to clipboard, grouped by week",
+	Use:              "copy",
+	Short:            "Copy completed tasks to clipboard",
+	Long:             "Copy completed tasks to clipboard, grouped by week",
+	PersistentPreRun: setupReadOnly,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := getAppContext(cmd)
 		if err := app.CopyCompletedTasks(ctx.Todos, ctx.Store, copyWeek); err != nil {
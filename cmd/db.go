@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/SongRunqi/go-todo/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbImportForce bool
+)
+
+// dbCmd groups the SQLite storage backend commands
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "",
+	Long:  "",
+}
+
+// dbImportCmd copies the JSON task files into the SQLite database
+var dbImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "",
+	Long:  "",
+	Example: `  todo db import
+  todo db import --force`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		if err := runDBImport(ctx, dbImportForce); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbImportCmd)
+	dbImportCmd.Flags().BoolVarP(&dbImportForce, "force", "f", false, "Overwrite a database that already contains tasks")
+}
+
+func runDBImport(ctx *AppContext, force bool) error {
+	src := repository.NewFileTodoStore(ctx.Config.TodoPath, ctx.Config.BackupPath)
+	src.Cipher = storeCipher(ctx)
+
+	dst, err := repository.NewSQLiteTodoStore(ctx.Config.DBPath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	if !force {
+		empty, err := repository.IsEmpty(dst)
+		if err != nil {
+			return fmt.Errorf("failed to inspect database: %w", err)
+		}
+		if !empty {
+			return fmt.Errorf("database %s already contains tasks (use --force to overwrite)", ctx.Config.DBPath)
+		}
+	}
+
+	active, backup, err := repository.CopyTodos(dst, src)
+	if err != nil {
+		return err
+	}
+
+	output.PrintSuccess("%s", i18n.T("cmd.db.import.success", active, backup, ctx.Config.DBPath))
+	if ctx.Config.Storage != "sqlite" {
+		output.PrintInfo("%s", i18n.T("cmd.db.import.hint"))
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/encryption"
+	"github.com/SongRunqi/go-todo/internal/repository"
+)
+
+func TestRunDBImport_EncryptedFiles(t *testing.T) {
+	dir := t.TempDir()
+	store := repository.NewFileTodoStore(filepath.Join(dir, "todo.json"), filepath.Join(dir, "todo_back.json"))
+	store.Cipher = encryption.New("secret")
+	if err := store.Save([]app.TodoItem{{TaskID: 1, TaskName: "Secret", Status: "pending"}}, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	ctx := &AppContext{Store: store, Config: app.Config{
+		TodoPath:   store.Path,
+		BackupPath: store.BackupPath,
+		DBPath:     filepath.Join(dir, "todo.db"),
+	}}
+
+	if err := runDBImport(ctx, false); err != nil {
+		t.Fatalf("runDBImport failed: %v", err)
+	}
+	db, err := repository.NewSQLiteTodoStore(ctx.Config.DBPath)
+	if err != nil {
+		t.Fatalf("NewSQLiteTodoStore failed: %v", err)
+	}
+	defer db.Close()
+	if todos, _ := db.Load(false); len(todos) != 1 || todos[0].TaskName != "Secret" {
+		t.Errorf("Expected the encrypted task in the database, got %+v", todos)
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/storage"
+)
+
+// newTestContext builds an AppContext backed by an in-memory store holding todos
+func newTestContext(t *testing.T, todos []app.TodoItem) (*AppContext, *storage.MemoryTodoStore) {
+	t.Helper()
+	store := storage.NewMemoryStore()
+	if err := store.Save(todos, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := store.Load(false)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return &AppContext{
+		Store:       store,
+		Todos:       &loaded,
+		CurrentTime: time.Now(),
+	}, store
+}
+
+func TestRunDelete_MovesActiveTaskToBackup(t *testing.T) {
+	ctx, store := newTestContext(t, []app.TodoItem{
+		{TaskID: 1, TaskName: "Keep", Status: "pending"},
+		{TaskID: 2, TaskName: "Remove", Status: "pending"},
+	})
+
+	if err := runDelete(ctx, 2, "active"); err != nil {
+		t.Fatalf("runDelete failed: %v", err)
+	}
+
+	active, _ := store.Load(false)
+	if len(active) != 1 || active[0].TaskID != 1 {
+		t.Errorf("Expected only task 1 to remain active, got %+v", active)
+	}
+	backup, _ := store.Load(true)
+	if len(backup) != 1 || backup[0].TaskID != 2 || backup[0].Status != "deleted" {
+		t.Errorf("Expected task 2 in backup with status deleted, got %+v", backup)
+	}
+}
+
+func TestRunDelete_RemovesBackupTask(t *testing.T) {
+	ctx, store := newTestContext(t, nil)
+	if err := store.Save([]app.TodoItem{{TaskID: 5, TaskName: "Old", Status: "completed"}}, true); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := runDelete(ctx, 5, "backup"); err != nil {
+		t.Fatalf("runDelete failed: %v", err)
+	}
+	if n := store.Size(true); n != 0 {
+		t.Errorf("Expected empty backup, got %d tasks", n)
+	}
+}
+
+func TestRunDelete_Errors(t *testing.T) {
+	ctx, _ := newTestContext(t, []app.TodoItem{{TaskID: 1, TaskName: "Task"}})
+
+	if err := runDelete(ctx, 1, "archive"); err == nil {
+		t.Error("Expected error for invalid source")
+	}
+	if err := runDelete(ctx, 42, "active"); err == nil {
+		t.Error("Expected error for unknown task ID")
+	}
+	if len(*ctx.Todos) != 1 {
+		t.Errorf("Failed deletes must not change the task list, got %+v", *ctx.Todos)
+	}
+}
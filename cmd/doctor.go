@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/doctor"
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/encryption"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/journal"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/SongRunqi/go-todo/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+var doctorFix bool
+
+// doctorCmd checks the task lists for problems
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "",
+	Long:  "",
+	Example: `  todo doctor
+  todo doctor --fix`,
+	Args: cobra.NoArgs,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// The lists are loaded by the checks, which must survive unreadable files
+		setupApp(cmd, false)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		remaining, err := runDoctor(ctx, doctorFix)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+		if remaining > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair what can be repaired safely, after taking a snapshot")
+}
+
+// doctorList is one task list as examined by doctor
+type doctorList struct {
+	name   string
+	backup bool
+	path   string
+	todos  []app.TodoItem
+	loaded bool
+
+	// fileIssues are the problems with the file that saving the list fixes
+	fileIssues []doctor.Issue
+	resave     bool
+}
+
+// runDoctor reports the problems in the current list, repairing them when
+// fix is set, and returns the number of problems left
+func runDoctor(ctx *AppContext, fix bool) (int, error) {
+	lists := []*doctorList{
+		{name: journal.StoreActive, path: ctx.Config.TodoPath},
+		{name: journal.StoreBackup, backup: true, path: ctx.Config.BackupPath},
+	}
+	issues := examineLists(ctx, lists)
+
+	fixable := 0
+	for _, issue := range issues {
+		if issue.Fixable {
+			fixable++
+		}
+	}
+	if !fix || fixable == 0 {
+		printIssues(issues)
+		if len(issues) == 0 {
+			output.PrintSuccess("%s", i18n.T("cmd.doctor.no_problems"))
+		} else if fixable > 0 {
+			fmt.Printf(i18n.T("cmd.doctor.fixable"), len(issues), fixable)
+		} else {
+			fmt.Printf(i18n.T("cmd.doctor.unfixable"), len(issues))
+		}
+		return len(issues), nil
+	}
+
+	if err := snapshotLists(ctx, lists); err != nil {
+		return 0, err
+	}
+
+	active, backup := lists[0], lists[1]
+	fixed, err := doctor.Repair(active.todos, backup.todos, ctx.CurrentTime, func() (int, error) {
+		return app.NextTaskID(ctx.Store, append(append([]app.TodoItem{}, active.todos...), backup.todos...))
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, issue := range fixed {
+		for _, l := range lists {
+			if l.name == issue.List {
+				l.resave = true
+			}
+		}
+	}
+
+	if recorder, ok := domain.As[domain.OperationRecorder](ctx.Store); ok {
+		recorder.BeginOperation("doctor", fmt.Sprintf("Repair %d problems", fixable))
+	}
+	for _, l := range []*doctorList{backup, active} {
+		if !l.loaded || !(l.resave || len(l.fileIssues) > 0) {
+			continue
+		}
+		if err := ctx.Store.Save(l.todos, l.backup); err != nil {
+			return 0, fmt.Errorf("failed to save the repaired %s list: %w", l.name, err)
+		}
+	}
+	for _, l := range lists {
+		fixed = append(fixed, l.fileIssues...)
+	}
+	for _, issue := range fixed {
+		fmt.Printf(i18n.T("cmd.doctor.fixed"), issue)
+	}
+
+	remaining := examineLists(ctx, lists)
+	printIssues(remaining)
+	if len(remaining) == 0 {
+		output.PrintSuccess("%s", i18n.T("cmd.doctor.repaired", fixable))
+	} else {
+		fmt.Printf(i18n.T("cmd.doctor.remaining"), fixable, len(remaining))
+	}
+	return len(remaining), nil
+}
+
+// examineLists loads the lists and returns every problem found in them,
+// starting with problems in the files themselves
+func examineLists(ctx *AppContext, lists []*doctorList) []doctor.Issue {
+	var issues []doctor.Issue
+	fileStore, isFileStore := domain.As[*repository.FileTodoStore](ctx.Store)
+
+	for _, l := range lists {
+		l.loaded, l.fileIssues = false, nil
+		if isFileStore {
+			fileIssues, readable := checkTaskFile(l.name, l.path, fileStore.Cipher)
+			issues = append(issues, fileIssues...)
+			if !readable {
+				continue
+			}
+			l.fileIssues = fileIssues
+		}
+		todos, err := ctx.Store.Load(l.backup)
+		if err != nil {
+			issues = append(issues, doctor.Issue{List: l.name, Problem: err.Error()})
+			continue
+		}
+		l.todos, l.loaded = todos, true
+	}
+	return append(issues, doctor.Check(lists[0].todos, lists[1].todos, ctx.CurrentTime)...)
+}
+
+// checkTaskFile checks the JSON in the task file at path and reports
+// whether the file can be loaded at all
+func checkTaskFile(list, path string, c *encryption.Cipher) ([]doctor.Issue, bool) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, true
+	}
+	if err != nil {
+		return []doctor.Issue{{List: list, Problem: err.Error()}}, false
+	}
+	plaintext, err := encryption.Open(c, data)
+	if err != nil {
+		return []doctor.Issue{{List: list, Problem: fmt.Sprintf("cannot read %s: %v", path, err)}}, false
+	}
+	issues := doctor.CheckJSON(list, plaintext)
+	for _, issue := range issues {
+		if !issue.Fixable {
+			return issues, false
+		}
+	}
+	return issues, true
+}
+
+// snapshotLists takes a snapshot of every list before it is repaired
+func snapshotLists(ctx *AppContext, lists []*doctorList) error {
+	manager, err := snapshotManager(ctx)
+	if err != nil {
+		return fmt.Errorf("refusing to repair without a snapshot: %w", err)
+	}
+	_, isFileStore := domain.As[*repository.FileTodoStore](ctx.Store)
+	for _, l := range lists {
+		var data []byte
+		if isFileStore {
+			// The file as it is, including anything the repair drops
+			if data, err = os.ReadFile(l.path); os.IsNotExist(err) {
+				continue
+			}
+		} else if l.loaded {
+			data, err = repository.MarshalTodos(l.todos, storeCipher(ctx))
+		} else {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the %s list for a snapshot: %w", l.name, err)
+		}
+		s, err := manager.Take(l.path, data)
+		if err != nil {
+			return fmt.Errorf("refusing to repair without a snapshot: %w", err)
+		}
+		output.PrintInfo("%s", i18n.T("cmd.doctor.snapshot", l.name, s.Name))
+	}
+	return nil
+}
+
+func printIssues(issues []doctor.Issue) {
+	for _, issue := range issues {
+		marker := "✗"
+		if issue.Fixable {
+			marker = "!"
+		}
+		fmt.Printf("  %s %s\n", marker, issue)
+	}
+}
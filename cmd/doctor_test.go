@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/SongRunqi/go-todo/app"
+)
+
+func newDoctorContext(t *testing.T, todos []app.TodoItem) *AppContext {
+	t.Helper()
+	ctx, _ := newTestContext(t, todos)
+	dir := t.TempDir()
+	ctx.Config.TodoPath = filepath.Join(dir, "todo.json")
+	ctx.Config.BackupPath = filepath.Join(dir, "todo_back.json")
+	ctx.Config.SnapshotDir = filepath.Join(dir, "snapshots")
+	ctx.Config.SnapshotKeep = 5
+	return ctx
+}
+
+func TestRunDoctor_ReportsAndRepairs(t *testing.T) {
+	ctx := newDoctorContext(t, []app.TodoItem{
+		{TaskID: 1, UUID: "a", TaskName: "First", Status: "pending"},
+		{TaskID: 1, UUID: "b", TaskName: "Second", Status: "Done"},
+	})
+
+	remaining, err := runDoctor(ctx, false)
+	if err != nil {
+		t.Fatalf("runDoctor failed: %v", err)
+	}
+	if remaining != 2 {
+		t.Fatalf("Expected 2 problems, got %d", remaining)
+	}
+
+	if remaining, err = runDoctor(ctx, true); err != nil {
+		t.Fatalf("runDoctor --fix failed: %v", err)
+	}
+	if remaining != 0 {
+		t.Errorf("Expected no problems after repair, got %d", remaining)
+	}
+	todos, err := ctx.Store.Load(false)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if todos[1].TaskID != 2 || todos[1].Status != "completed" {
+		t.Errorf("Expected the second task to be renumbered and normalized, got %+v", todos[1])
+	}
+
+	manager, err := snapshotManager(ctx)
+	if err != nil {
+		t.Fatalf("snapshotManager failed: %v", err)
+	}
+	if snapshots, err := manager.List("todo.json"); err != nil || len(snapshots) == 0 {
+		t.Errorf("Expected a snapshot before the repair, got %v (%v)", snapshots, err)
+	}
+}
+
+func TestRunDoctor_NeedsSnapshots(t *testing.T) {
+	ctx := newDoctorContext(t, []app.TodoItem{{TaskID: 1, UUID: "a", TaskName: "Task", Status: "todo"}})
+	ctx.Config.SnapshotDir = ""
+
+	if _, err := runDoctor(ctx, true); !errors.Is(err, errSnapshotsOff) {
+		t.Fatalf("Expected errSnapshotsOff, got %v", err)
+	}
+	todos, _ := ctx.Store.Load(false)
+	if todos[0].Status != "todo" {
+		t.Errorf("Expected the list to be left alone, got %+v", todos[0])
+	}
+}
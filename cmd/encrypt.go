@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/config"
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/encryption"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/journal"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/SongRunqi/go-todo/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+var errEncryptionNeedsFiles = errors.New("encryption is only supported by the file storage backend")
+
+// encryptCmd turns on encryption at rest
+var encryptCmd = &cobra.Command{
+	Use:     "encrypt",
+	Short:   "",
+	Long:    "",
+	Example: `  TODO_PASSPHRASE_CMD="pass show todo" todo encrypt`,
+	Args:    cobra.NoArgs,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		setupApp(cmd, false)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		if err := runConvertEncryption(ctx, true); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+		if err := setConfigValue("encrypt", true); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+		output.PrintSuccess("%s", i18n.T("cmd.encrypt.success"))
+	},
+}
+
+// decryptCmd turns off encryption at rest
+var decryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "",
+	Long:  "",
+	Args:  cobra.NoArgs,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		setupApp(cmd, false)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		if err := runConvertEncryption(ctx, false); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+		if err := setConfigValue("encrypt", false); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+		output.PrintSuccess("%s", i18n.T("cmd.decrypt.success"))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(encryptCmd)
+	rootCmd.AddCommand(decryptCmd)
+}
+
+// storeCipher returns the cipher used by the file store, or nil when
+// encryption is off
+func storeCipher(ctx *AppContext) *encryption.Cipher {
+	if store, ok := domain.As[*repository.FileTodoStore](ctx.Store); ok {
+		return store.Cipher
+	}
+	return nil
+}
+
+// runConvertEncryption rewrites every file holding task data, in every
+// list, either encrypted (encrypt is true) or as plaintext: encryption is
+// turned on and off for all lists at once. Files already in the wanted form
+// are rewritten too, so an interrupted run can simply be repeated.
+func runConvertEncryption(ctx *AppContext, encrypt bool) error {
+	store, ok := domain.As[*repository.FileTodoStore](ctx.Store)
+	if !ok || ctx.Config.Storage == config.StorageSQLite {
+		return errEncryptionNeedsFiles
+	}
+
+	c := store.Cipher
+	if c == nil {
+		passphrase, err := encryption.Passphrase(ctx.Config.PassphraseCommand)
+		if err != nil {
+			return err
+		}
+		c = encryption.New(passphrase)
+	}
+	var target *encryption.Cipher
+	if encrypt {
+		target = c
+	}
+
+	names, err := listNames(ctx.Config)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if name == ctx.Config.List {
+			var j *journal.Journal
+			if js, ok := domain.As[*journal.Store](ctx.Store); ok {
+				j = js.Journal()
+			}
+			err = convertListEncryption(ctx.Config, store, j, c, target)
+		} else {
+			err = convertOtherListEncryption(name, c, target)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to convert list %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// convertOtherListEncryption opens and locks the files of another task list
+// and converts them. The store is not opened through its configuration,
+// which would ask for the passphrase again.
+func convertOtherListEncryption(name string, c, target *encryption.Cipher) error {
+	cfg := loadListConfig(name)
+	store := repository.NewFileTodoStore(cfg.TodoPath, cfg.BackupPath)
+	store.Snapshots = repository.OpenSnapshots(cfg)
+	if err := store.Lock(); err != nil {
+		return err
+	}
+	defer store.Unlock()
+
+	var j *journal.Journal
+	if cfg.JournalPath != "" {
+		j = journal.New(cfg.JournalPath)
+	}
+	return convertListEncryption(cfg, store, j, c, target)
+}
+
+// convertListEncryption rewrites the task files, journal, migration backups
+// and snapshots of one list, reading them with c and writing them with
+// target (plaintext when nil)
+func convertListEncryption(cfg app.Config, store *repository.FileTodoStore, j *journal.Journal, c, target *encryption.Cipher) error {
+	// Task files go through the store so migrations and snapshots still apply.
+	// The journal wrapper is bypassed: the tasks themselves do not change.
+	for _, backup := range []bool{false, true} {
+		store.Cipher = c
+		todos, err := store.Load(backup)
+		if err != nil {
+			return err
+		}
+		store.Cipher = target
+		if err := store.Save(todos, backup); err != nil {
+			return err
+		}
+	}
+
+	if j != nil {
+		j.Cipher = c
+		if err := j.Rewrite(target); err != nil {
+			return err
+		}
+	}
+
+	var paths []string
+	for _, taskFile := range []string{store.Path, store.BackupPath} {
+		backups, err := filepath.Glob(taskFile + ".v*.bak")
+		if err != nil {
+			return err
+		}
+		paths = append(paths, backups...)
+	}
+	if manager := repository.OpenSnapshots(cfg); manager != nil {
+		snapshots, err := manager.List("")
+		if err != nil {
+			return err
+		}
+		for _, s := range snapshots {
+			paths = append(paths, s.Path)
+		}
+	}
+	for _, path := range paths {
+		if err := encryption.ConvertFile(path, c, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
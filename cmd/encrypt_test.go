@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/repository"
+)
+
+func TestRunConvertEncryption_EveryList(t *testing.T) {
+	t.Setenv("TODO_PASSPHRASE", "secret")
+	dir := t.TempDir()
+	listConfig := func(name string) app.Config {
+		cfg := app.Config{List: name, ListsDir: filepath.Join(dir, "lists")}
+		listDir := dir
+		if name != "default" {
+			listDir = filepath.Join(cfg.ListsDir, name)
+		}
+		cfg.TodoPath = filepath.Join(listDir, "todo.json")
+		cfg.BackupPath = filepath.Join(listDir, "todo_back.json")
+		cfg.JournalPath = filepath.Join(listDir, "journal.jsonl")
+		return cfg
+	}
+	saved := loadListConfig
+	t.Cleanup(func() { loadListConfig = saved })
+	loadListConfig = listConfig
+
+	for _, name := range []string{"default", "work"} {
+		cfg := listConfig(name)
+		if err := os.MkdirAll(filepath.Dir(cfg.TodoPath), 0755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+		store := repository.NewFileTodoStore(cfg.TodoPath, cfg.BackupPath)
+		if err := store.Save([]app.TodoItem{{TaskID: 1, TaskName: "Task in " + name, Status: "pending"}}, false); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	// The command runs on the default list; the work list is converted too
+	cfg := listConfig("default")
+	ctx := &AppContext{Store: repository.NewFileTodoStore(cfg.TodoPath, cfg.BackupPath), Config: cfg}
+	work := listConfig("work").TodoPath
+	if err := runConvertEncryption(ctx, true); err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	for _, path := range []string{cfg.TodoPath, work} {
+		if data, _ := os.ReadFile(path); strings.Contains(string(data), "Task in") {
+			t.Errorf("Expected %s to be encrypted, got %s", path, data)
+		}
+	}
+
+	if err := runConvertEncryption(ctx, false); err != nil {
+		t.Fatalf("decrypt failed: %v", err)
+	}
+	if data, _ := os.ReadFile(work); !strings.Contains(string(data), "Task in work") {
+		t.Errorf("Expected the work list in plain JSON, got %s", data)
+	}
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/exporter"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/SongRunqi/go-todo/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat   string
+	exportSource   string
+	exportStatuses []string
+	exportFrom     string
+	exportTo       string
+	exportOutput   string
+)
+
+// exportCmd writes tasks in formats other tools can read
+var exportCmd = &cobra.Command{
+	Use:   "export --format <format>",
+	Short: "",
+	Long:  "",
+	Example: `  todo export --format csv --output tasks.csv
+  todo export --format todotxt --source all --status pending,in_progress
+  todo export --format markdown --from 2026-10-01 --to 2026-10-31
+  todo export --format ics --output ~/todo.ics`,
+	Args:             cobra.NoArgs,
+	PersistentPreRun: setupReadOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		filter, err := exportFilter(exportStatuses, exportFrom, exportTo)
+		if err == nil {
+			err = runExport(ctx, exportFormat, exportSource, filter, exportOutput)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(&exportFormat, "format", "", "Output format: "+strings.Join(exporter.Formats, ", "))
+	exportCmd.Flags().StringVar(&exportSource, "source", "active", "Tasks to export: active, backup or all")
+	exportCmd.Flags().StringSliceVar(&exportStatuses, "status", nil, "Only export tasks with these statuses")
+	exportCmd.Flags().StringVar(&exportFrom, "from", "", "Only export tasks due on or after this date (YYYY-MM-DD)")
+	exportCmd.Flags().StringVar(&exportTo, "to", "", "Only export tasks due on or before this date (YYYY-MM-DD)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write to this file instead of standard output")
+	_ = exportCmd.MarkFlagRequired("format")
+}
+
+// exportFilter builds the export filter from the command line flags
+func exportFilter(statuses []string, from, to string) (exporter.Filter, error) {
+	var filter exporter.Filter
+	for _, status := range statuses {
+		status = validator.NormalizeStatus(status)
+		if validator.ValidateStatus(status) != nil && validator.ValidateRecurringStatus(status) != nil {
+			return filter, fmt.Errorf("invalid status %q", status)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	var err error
+	if from != "" {
+		if filter.From, err = time.ParseInLocation("2006-01-02", from, time.Local); err != nil {
+			return filter, fmt.Errorf("invalid --from date %q, expected YYYY-MM-DD", from)
+		}
+	}
+	if to != "" {
+		if filter.To, err = time.ParseInLocation("2006-01-02", to, time.Local); err != nil {
+			return filter, fmt.Errorf("invalid --to date %q, expected YYYY-MM-DD", to)
+		}
+		// Include the whole last day
+		filter.To = filter.To.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return filter, fmt.Errorf("--to %s is before --from %s", to, from)
+	}
+	return filter, nil
+}
+
+func runExport(ctx *AppContext, format, source string, filter exporter.Filter, path string) error {
+	if !slices.Contains(exporter.Formats, format) {
+		return fmt.Errorf("unknown export format %q, must be one of: %s", format, strings.Join(exporter.Formats, ", "))
+	}
+
+	var todos []app.TodoItem
+	switch source {
+	case "active":
+		todos = *ctx.Todos
+	case "backup", "all":
+		backupTodos, err := ctx.Store.Load(true)
+		if err != nil {
+			return fmt.Errorf("failed to load backup todos: %w", err)
+		}
+		if source == "all" {
+			todos = append(todos, *ctx.Todos...)
+		}
+		todos = append(todos, backupTodos...)
+	default:
+		return fmt.Errorf("invalid source %q, must be active, backup or all", source)
+	}
+
+	var selected []app.TodoItem
+	for _, task := range todos {
+		if filter.Match(task) {
+			selected = append(selected, task)
+		}
+	}
+
+	if path == "" {
+		return exporter.Write(format, os.Stdout, selected)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := exporter.Write(format, f, selected); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	output.PrintSuccess("%s", i18n.T("cmd.export.success", len(selected), path))
+	return nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/exporter"
+)
+
+func TestRunExport_SourcesAndFilters(t *testing.T) {
+	due := time.Date(2026, 10, 20, 18, 0, 0, 0, time.Local)
+	ctx, store := newTestContext(t, []app.TodoItem{
+		{TaskID: 1, TaskName: "Call mom", Status: "pending", EndTime: due},
+		{TaskID: 2, TaskName: "Someday", Status: "pending"},
+	})
+	if err := store.Save([]app.TodoItem{{TaskID: 3, TaskName: "Pay rent", Status: "completed", EndTime: due}}, true); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "tasks.txt")
+
+	tests := []struct {
+		source   string
+		statuses []string
+		from, to string
+		want     string
+	}{
+		{"active", nil, "", "", "Call mom due:2026-10-20\nSomeday\n"},
+		{"backup", nil, "", "", "x Pay rent due:2026-10-20\n"},
+		{"all", []string{"done"}, "", "", "x Pay rent due:2026-10-20\n"},
+		{"all", nil, "2026-10-20", "2026-10-20", "Call mom due:2026-10-20\nx Pay rent due:2026-10-20\n"},
+		{"all", nil, "2026-10-21", "", ""},
+	}
+	for _, tt := range tests {
+		filter, err := exportFilter(tt.statuses, tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("exportFilter failed: %v", err)
+		}
+		if err := runExport(ctx, "todotxt", tt.source, filter, path); err != nil {
+			t.Fatalf("runExport failed: %v", err)
+		}
+		data, _ := os.ReadFile(path)
+		if string(data) != tt.want {
+			t.Errorf("Export of %s %v %s..%s = %q, want %q", tt.source, tt.statuses, tt.from, tt.to, data, tt.want)
+		}
+	}
+}
+
+func TestRunExport_RejectsBadArguments(t *testing.T) {
+	ctx, _ := newTestContext(t, nil)
+	if _, err := exportFilter([]string{"someday"}, "", ""); err == nil {
+		t.Error("Expected an error for an unknown status")
+	}
+	if _, err := exportFilter(nil, "2026-10-21", "2026-10-20"); err == nil {
+		t.Error("Expected an error for --to before --from")
+	}
+	path := filepath.Join(t.TempDir(), "tasks.xml")
+	if err := runExport(ctx, "xml", "active", exporter.Filter{}, path); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected no file to be created for an unknown format")
+	}
+	if err := runExport(ctx, "csv", "trash", exporter.Filter{}, ""); err == nil {
+		t.Error("Expected an error for an unknown source")
+	}
+}
@@ -12,10 +12,11 @@ import (
 
 // getCmd represents the get command
 var getCmd = &cobra.Command{
-	Use:   "get <id>",
-	Short: "",
-	Long:  "",
-	Args:  cobra.ExactArgs(1),
+	Use:              "get <id>",
+	Short:            "",
+	Long:             "",
+	Args:             cobra.ExactArgs(1),
+	PersistentPreRun: setupReadOnly,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := getAppContext(cmd)
 		id, err := strconv.Atoi(args[0])
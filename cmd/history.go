@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/encryption"
+	"github.com/SongRunqi/go-todo/internal/githistory"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/journal"
+	"github.com/SongRunqi/go-todo/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+var errGitHistoryOff = errors.New(`git history is off; set "gitHistory": true in ~/.todo/config.json or TODO_GIT_HISTORY=1`)
+
+// historyCmd shows how a task changed over time
+var historyCmd = &cobra.Command{
+	Use:     "history <id>",
+	Short:   "",
+	Long:    "",
+	Example: `  todo history 12`,
+	Args:    cobra.ExactArgs(1),
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		setupApp(cmd, false)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.invalid_task_id"), args[0])
+			os.Exit(1)
+		}
+		if err := runHistory(ctx, id); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+}
+
+// historyEntry is one commit that changed a task
+type historyEntry struct {
+	Commit  githistory.Commit
+	Changes []journal.Change
+}
+
+func runHistory(ctx *AppContext, id int) error {
+	gitStore, ok := domain.As[*githistory.Store](ctx.Store)
+	if !ok {
+		return errGitHistoryOff
+	}
+	fileStore, ok := domain.As[*repository.FileTodoStore](ctx.Store)
+	if !ok {
+		return errGitHistoryOff
+	}
+
+	entries, err := taskHistory(gitStore.Repo(), fileStore.Path, fileStore.BackupPath, fileStore.Cipher, id)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("task %d does not appear in the git history", id)
+	}
+
+	for _, e := range entries {
+		fmt.Printf("%s  %.7s  %s\n", e.Commit.Time.Format("2006-01-02 15:04"), e.Commit.Hash, e.Commit.Subject)
+		for _, c := range e.Changes {
+			fmt.Printf("    %s\n", c.Describe())
+			for _, field := range changedFields(c.Before, c.After) {
+				fmt.Printf("      %s\n", field)
+			}
+		}
+	}
+	return nil
+}
+
+// taskHistory returns the commits that changed task id in the active
+// (path) or backup (backupPath) list, oldest first
+func taskHistory(repo *githistory.Repo, path, backupPath string, c *encryption.Cipher, id int) ([]historyEntry, error) {
+	commits, err := repo.Log(path, backupPath)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := []struct {
+		name, path string
+		prev       []app.TodoItem
+	}{
+		{name: journal.StoreBackup, path: backupPath},
+		{name: journal.StoreActive, path: path},
+	}
+
+	var entries []historyEntry
+	for _, commit := range commits {
+		var changes []journal.Change
+		for i := range lists {
+			list := &lists[i]
+			data, ok, err := repo.Show(commit.Hash, list.path)
+			if err != nil {
+				return nil, err
+			}
+			var current []app.TodoItem
+			if ok {
+				todos, err := repository.ParseTodos(data, c)
+				if err != nil {
+					return nil, fmt.Errorf("cannot read %s in commit %.7s: %w", list.path, commit.Hash, err)
+				}
+				current = tasksWithID(todos, id)
+			}
+			changes = append(changes, journal.Diff(list.name, list.prev, current)...)
+			list.prev = current
+		}
+		if len(changes) > 0 {
+			entries = append(entries, historyEntry{Commit: commit, Changes: changes})
+		}
+	}
+	return entries, nil
+}
+
+// tasksWithID returns the tasks in todos with the given ID
+func tasksWithID(todos []app.TodoItem, id int) []app.TodoItem {
+	var matches []app.TodoItem
+	for _, task := range todos {
+		if task.TaskID == id {
+			matches = append(matches, task)
+		}
+	}
+	return matches
+}
+
+// changedFields lists the JSON fields that differ between two versions of
+// a task, with old and new values for short scalar fields
+func changedFields(before, after *app.TodoItem) []string {
+	if before == nil || after == nil {
+		return nil
+	}
+	var old, new map[string]json.RawMessage
+	b, _ := json.Marshal(before)
+	a, _ := json.Marshal(after)
+	if json.Unmarshal(b, &old) != nil || json.Unmarshal(a, &new) != nil {
+		return nil
+	}
+
+	keys := make(map[string]bool)
+	for k := range old {
+		keys[k] = true
+	}
+	for k := range new {
+		keys[k] = true
+	}
+	var fields []string
+	for k := range keys {
+		o, n := string(old[k]), string(new[k])
+		if o == n {
+			continue
+		}
+		if o == "" {
+			o = "(none)"
+		}
+		if n == "" {
+			n = "(none)"
+		}
+		if len(o) <= 40 && len(n) <= 40 && o[0] != '[' && o[0] != '{' && n[0] != '[' && n[0] != '{' {
+			fields = append(fields, fmt.Sprintf("%s: %s -> %s", k, o, n))
+		} else {
+			fields = append(fields, fmt.Sprintf("%s changed", k))
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
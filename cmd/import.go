@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/importer"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFormat string
+	importDryRun bool
+)
+
+// importCmd imports tasks from other todo tools
+var importCmd = &cobra.Command{
+	Use:   "import <file> --format <format>",
+	Short: "",
+	Long:  "",
+	Example: `  todo import todo.txt --format todotxt --dry-run
+  task export | todo import - --format taskwarrior
+  todo --list work import tasks.csv --format csv
+  todo import classes.ics --format ics`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		if err := runImport(ctx, args[0], importFormat, importDryRun); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVar(&importFormat, "format", "", "Format of the file: "+strings.Join(importer.Formats, ", "))
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Only show the tasks that would be created")
+	_ = importCmd.MarkFlagRequired("format")
+}
+
+func runImport(ctx *AppContext, path, format string, dryRun bool) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	result, err := importer.Read(format, r)
+	if err != nil {
+		return err
+	}
+	for _, warning := range result.Warnings {
+		output.PrintWarning("%s", warning)
+	}
+
+	created, err := app.ImportTasks(ctx.Todos, result.Tasks, ctx.Store, ctx.CurrentTime, dryRun)
+	if err != nil {
+		return err
+	}
+	if len(created) == 0 {
+		output.PrintInfo("%s", i18n.T("cmd.import.none"))
+		return nil
+	}
+	for _, task := range created {
+		if dryRun {
+			fmt.Printf("  + %s\n", describeImported(task))
+		} else {
+			fmt.Printf("  #%d %s\n", task.TaskID, describeImported(task))
+		}
+	}
+	if dryRun {
+		fmt.Printf(i18n.T("cmd.import.dry_run"), len(created))
+		return nil
+	}
+	output.PrintSuccess("%s", i18n.T("cmd.import.success", len(created)))
+	return nil
+}
+
+// describeImported summarizes an imported task on one line
+func describeImported(task app.TodoItem) string {
+	parts := []string{task.TaskName}
+	if task.Status != "pending" && task.Status != "active" {
+		parts = append(parts, "["+task.Status+"]")
+	}
+	if task.IsRecurring {
+		if task.RecurringInterval > 1 {
+			parts = append(parts, fmt.Sprintf("(%s, every %d)", task.RecurringType, task.RecurringInterval))
+		} else {
+			parts = append(parts, "("+task.RecurringType+")")
+		}
+	}
+	if task.DueDate != "" {
+		parts = append(parts, "due "+task.DueDate)
+	}
+	if task.Urgent != "" {
+		parts = append(parts, task.Urgent)
+	}
+	if task.Project != "" {
+		parts = append(parts, "+"+task.Project)
+	}
+	for _, tag := range task.Tags {
+		parts = append(parts, "@"+tag)
+	}
+	return strings.Join(parts, " ")
+}
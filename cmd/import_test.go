@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+)
+
+func writeImportFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tasks")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return path
+}
+
+func TestRunImport_AllocatesFreshIDs(t *testing.T) {
+	ctx, store := newTestContext(t, []app.TodoItem{{TaskID: 1, TaskName: "Existing", Status: "pending"}})
+	if err := store.Save([]app.TodoItem{{TaskID: 2, TaskName: "Done", Status: "completed"}}, true); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	path := writeImportFile(t, "(B) Call mom +Family\nx Pay rent\n")
+
+	if err := runImport(ctx, path, "todotxt", true); err != nil {
+		t.Fatalf("runImport --dry-run failed: %v", err)
+	}
+	if active, _ := store.Load(false); len(active) != 1 {
+		t.Fatalf("Expected a dry run to save nothing, got %+v", active)
+	}
+
+	if err := runImport(ctx, path, "todotxt", false); err != nil {
+		t.Fatalf("runImport failed: %v", err)
+	}
+	active, _ := store.Load(false)
+	if len(active) != 3 || active[1].TaskID != 3 || active[2].TaskID != 4 {
+		t.Fatalf("Expected tasks #3 and #4 to be added, got %+v", active)
+	}
+	if active[1].Urgent != "high" || active[1].Project != "Family" || active[1].UUID == "" || active[2].Status != "completed" {
+		t.Errorf("Unexpected imported tasks: %+v", active[1:])
+	}
+}
+
+func TestRunImport_InvalidTaskImportsNothing(t *testing.T) {
+	ctx, store := newTestContext(t, nil)
+	path := writeImportFile(t, "name,status\nFine,pending\n"+strings.Repeat("x", 300)+",pending\n")
+
+	if err := runImport(ctx, path, "csv", false); err == nil {
+		t.Fatal("Expected the over-long task name to be rejected")
+	}
+	if active, _ := store.Load(false); len(active) != 0 {
+		t.Errorf("Expected nothing to be imported, got %+v", active)
+	}
+}
+
+func TestRunImport_ICSSeriesCatchUp(t *testing.T) {
+	ctx, store := newTestContext(t, nil)
+	now := ctx.CurrentTime
+	icsTime := func(t time.Time) string { return t.Format("20060102T150405") }
+	weeklyStart := now.AddDate(0, 0, -21).Add(-time.Hour).Truncate(time.Second)
+	dailyStart := now.AddDate(0, 0, -10)
+	path := writeImportFile(t, strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"SUMMARY:Standup",
+		"DTSTART:" + icsTime(weeklyStart),
+		"DURATION:PT15M",
+		"RRULE:FREQ=WEEKLY;COUNT=10",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"SUMMARY:Water plants",
+		"DTSTART:" + icsTime(dailyStart),
+		"RRULE:FREQ=DAILY;COUNT=5",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n"))
+
+	if err := runImport(ctx, path, "ics", false); err != nil {
+		t.Fatalf("runImport failed: %v", err)
+	}
+	active, _ := store.Load(false)
+	if len(active) != 2 {
+		t.Fatalf("Expected 2 tasks, got %+v", active)
+	}
+
+	// Four weekly standups have passed, so six remain from next week on
+	standup := active[0]
+	next := weeklyStart.AddDate(0, 0, 28)
+	if standup.Status != "active" || standup.RecurringMaxCount != 6 || standup.EventDuration != 15*time.Minute ||
+		!standup.EndTime.Equal(next) || len(standup.OccurrenceHistory) != 1 || standup.OccurrenceHistory[0].Status != "pending" {
+		t.Errorf("Expected the standup to pick up on %v, got %+v", next, standup)
+	}
+
+	// All five waterings have passed
+	if plants := active[1]; plants.Status != "completed" || plants.EndTime.After(now) {
+		t.Errorf("Expected the ended series to be imported as completed, got %+v", plants)
+	}
+}
+
+func TestRunImport_RecurrenceRule(t *testing.T) {
+	ctx, store := newTestContext(t, nil)
+	now := ctx.CurrentTime
+	lastFriday := func(year int, month time.Month) time.Time {
+		day := time.Date(year, month+1, 0, 9, 0, 0, 0, time.Local)
+		for day.Weekday() != time.Friday {
+			day = day.AddDate(0, 0, -1)
+		}
+		return day
+	}
+	start := lastFriday(now.Year(), now.Month()-3)
+	path := writeImportFile(t, strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VTODO",
+		"SUMMARY:Payday",
+		"DTSTART:" + start.Format("20060102T150405"),
+		"RRULE:FREQ=MONTHLY;BYDAY=FR;BYSETPOS=-1",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n"))
+
+	if err := runImport(ctx, path, "ics", false); err != nil {
+		t.Fatalf("runImport failed: %v", err)
+	}
+	month := now.Month() - 3
+	for !lastFriday(now.Year(), month).After(now) {
+		month++
+	}
+	next := lastFriday(now.Year(), month)
+	payday := (*ctx.Todos)[0]
+	if payday.RecurrenceRule != "FREQ=MONTHLY;BYDAY=FR;BYSETPOS=-1" || payday.RecurringType != "monthly" ||
+		!payday.RecurrenceStart.Equal(start) || !payday.EndTime.Equal(next) {
+		t.Fatalf("Expected payday to follow its rule to %v, got %+v", next, payday)
+	}
+
+	if err := app.Complete(ctx.Todos, &app.TodoItem{TaskID: payday.TaskID}, ctx.Store); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	active, _ := store.Load(false)
+	if following := lastFriday(now.Year(), month+1); !active[0].EndTime.Equal(following) || active[0].Status != "active" {
+		t.Errorf("Expected the next payday on %v, got %+v", following, active[0])
+	}
+}
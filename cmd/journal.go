@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/journal"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	journalLogLimit    int
+	journalReplayTo    int
+	journalReplayDry   bool
+	errJournalDisabled = errors.New("the journal is disabled (TODO_JOURNAL_PATH is empty)")
+)
+
+// journalCmd groups the operation journal commands
+var journalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "",
+	Long:  "",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Don't load todo.json: replay must work when it is unreadable
+		setupApp(cmd, false)
+	},
+}
+
+// journalLogCmd shows recent journal entries
+var journalLogCmd = &cobra.Command{
+	Use:   "log",
+	Short: "",
+	Example: `  todo journal log
+  todo journal log -n 50`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		if err := runJournalLog(ctx, journalLogLimit); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+// journalReplayCmd rebuilds the task files from the journal
+var journalReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "",
+	Long:  "",
+	Example: `  todo journal replay --dry-run
+  todo journal replay
+  todo journal replay --to 42`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		if err := runJournalReplay(ctx, journalReplayTo, journalReplayDry); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(journalCmd)
+	journalCmd.AddCommand(journalLogCmd)
+	journalCmd.AddCommand(journalReplayCmd)
+	journalLogCmd.Flags().IntVarP(&journalLogLimit, "limit", "n", 20, "Number of entries to show (0 shows all)")
+	journalReplayCmd.Flags().IntVar(&journalReplayTo, "to", 0, "Stop after the entry with this sequence number")
+	journalReplayCmd.Flags().BoolVar(&journalReplayDry, "dry-run", false, "Only report what the replay would produce")
+}
+
+// journalStore returns the journaling layer of the context's store
+func journalStore(ctx *AppContext) (*journal.Store, error) {
+	store, ok := domain.As[*journal.Store](ctx.Store)
+	if !ok {
+		return nil, errJournalDisabled
+	}
+	return store, nil
+}
+
+// readJournal returns the journal's events. A damaged trailing entry is
+// reported as a warning and the events before it are used.
+func readJournal(j *journal.Journal) ([]journal.Event, error) {
+	events, err := j.Read()
+	if err != nil {
+		if len(events) == 0 {
+			return nil, err
+		}
+		output.PrintWarning("%s", i18n.T("cmd.journal.warning.damaged", err))
+	}
+	return events, nil
+}
+
+func runJournalLog(ctx *AppContext, limit int) error {
+	store, err := journalStore(ctx)
+	if err != nil {
+		return err
+	}
+	events, err := readJournal(store.Journal())
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		fmt.Println(i18n.T("cmd.journal.log.empty"))
+		return nil
+	}
+
+	start := 0
+	if limit > 0 && len(events) > limit {
+		start = len(events) - limit
+	}
+	for i := len(events) - 1; i >= start; i-- {
+		e := events[i]
+		desc := e.Desc
+		if desc == "" {
+			desc = "-"
+		}
+		fmt.Printf(i18n.T("cmd.journal.log.entry"), e.Seq, e.Time.Local().Format("2006-01-02 15:04:05"), e.Op, desc, len(e.Changes))
+	}
+	return nil
+}
+
+func runJournalReplay(ctx *AppContext, upTo int, dryRun bool) error {
+	store, err := journalStore(ctx)
+	if err != nil {
+		return err
+	}
+	events, err := readJournal(store.Journal())
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("the journal at %s is empty; nothing to replay", store.Journal().Path)
+	}
+	last := events[len(events)-1].Seq
+	if upTo <= 0 || upTo > last {
+		upTo = last
+	}
+
+	target, err := journal.Replay(events, upTo)
+	if err != nil {
+		return fmt.Errorf("failed to replay journal: %w", err)
+	}
+	output.PrintInfo("%s", i18n.T("cmd.journal.replay.result", upTo, len(target.Active), len(target.Backup)))
+	if dryRun {
+		return nil
+	}
+
+	// The current files may be unreadable, so the journal's own final state
+	// is the "before" side of the replay entry
+	head, err := journal.Replay(events, 0)
+	if err != nil {
+		return fmt.Errorf("failed to replay journal: %w", err)
+	}
+
+	desc := fmt.Sprintf("Rebuild task files from journal up to #%d", upTo)
+	backend := store.Unwrap()
+	if recorder, ok := domain.As[domain.OperationRecorder](backend); ok {
+		recorder.BeginOperation(journal.OpReplay, desc)
+	}
+	if err := backend.Save(target.Active, false); err != nil {
+		return fmt.Errorf("failed to save active todos: %w", err)
+	}
+	if err := backend.Save(target.Backup, true); err != nil {
+		return fmt.Errorf("failed to save backup todos: %w", err)
+	}
+
+	changes := append(journal.Diff(journal.StoreBackup, head.Backup, target.Backup),
+		journal.Diff(journal.StoreActive, head.Active, target.Active)...)
+	if _, err := store.Journal().Append(journal.Event{
+		Op:      journal.OpReplay,
+		Desc:    desc,
+		Changes: changes,
+	}); err != nil {
+		output.PrintWarning("%s", i18n.T("cmd.journal.replay.warning.not_journaled", err))
+	}
+
+	output.PrintSuccess("%s", i18n.T("cmd.journal.replay.success", upTo))
+	return nil
+}
@@ -72,7 +72,7 @@ var langSetCmd = &cobra.Command{
 		}
 
 		// Save language preference to config file
-		if err := saveLanguageConfig(langCode); err != nil {
+		if err := setConfigValue("language", langCode); err != nil {
 			fmt.Fprintf(os.Stderr, i18n.T("cmd.lang.error.save_config"), err)
 			os.Exit(1)
 		}
@@ -155,7 +155,8 @@ func listLanguages() {
 	fmt.Println(string(jsonData))
 }
 
-func saveLanguageConfig(langCode string) error {
+// setConfigValue stores key in ~/.todo/config.json, keeping any other settings
+func setConfigValue(key string, value interface{}) error {
 	// Get config directory (use the same as todo files)
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -169,12 +170,14 @@ func saveLanguageConfig(langCode string) error {
 
 	configFile := filepath.Join(configDir, "config.json")
 
-	// Read existing config or create new one
-	type Config struct {
-		Language string `json:"language"`
+	// Read existing config or create new one, keeping any other settings
+	cfg := make(map[string]interface{})
+	if data, err := os.ReadFile(configFile); err == nil {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			cfg = make(map[string]interface{})
+		}
 	}
-
-	cfg := Config{Language: langCode}
+	cfg[key] = value
 
 	// Write config file
 	data, err := json.MarshalIndent(cfg, "", "  ")
@@ -11,10 +11,11 @@ import (
 
 // listCmd represents the list command
 var listCmd = &cobra.Command{
-	Use:     "list",
-	Aliases: []string{"ls"},
-	Short:   "",
-	Long:    "",
+	Use:              "list",
+	Aliases:          []string{"ls"},
+	Short:            "",
+	Long:             "",
+	PersistentPreRun: setupReadOnly,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := getAppContext(cmd)
 		if err := app.List(ctx.Todos); err != nil {
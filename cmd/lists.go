@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/config"
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/logger"
+	"github.com/SongRunqi/go-todo/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+// listsCmd shows the task lists
+var listsCmd = &cobra.Command{
+	Use:   "lists",
+	Short: "",
+	Long:  "",
+	Example: `  todo lists
+  todo --list work ask "prepare the quarterly report by Friday"
+  todo -L home list`,
+	Args:             cobra.NoArgs,
+	PersistentPreRun: setupReadOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		if err := runLists(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listsCmd)
+}
+
+func runLists(ctx *AppContext) error {
+	names, err := listNames(ctx.Config)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		marker := " "
+		count := "?"
+		if name == ctx.Config.List {
+			// The current list is already open and locked
+			marker = "*"
+			count = fmt.Sprintf("%d", len(*ctx.Todos))
+		} else {
+			err := withListStore(name, func(store app.TodoStore) error {
+				todos, err := store.Load(false)
+				if err == nil {
+					count = fmt.Sprintf("%d", len(todos))
+				}
+				return err
+			})
+			if err != nil {
+				logger.Warnf("Failed to read list %s: %v", name, err)
+			}
+		}
+		fmt.Printf(i18n.T("cmd.lists.entry"), marker, name, count)
+	}
+	return nil
+}
+
+// listNames returns the default list followed by the named lists in
+// cfg.ListsDir, sorted by name. The current list is always included.
+func listNames(cfg app.Config) ([]string, error) {
+	var names []string
+	entries, err := os.ReadDir(cfg.ListsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read lists directory: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != config.DefaultList && validator.ValidateListName(e.Name()) == nil {
+			names = append(names, e.Name())
+		}
+	}
+	if cfg.List != config.DefaultList && !slices.Contains(names, cfg.List) {
+		names = append(names, cfg.List)
+	}
+	sort.Strings(names)
+	return append([]string{config.DefaultList}, names...), nil
+}
+
+// withListStore opens and locks the store of another task list for the
+// duration of fn. It must not be used for the list of the current command,
+// whose lock is already held.
+func withListStore(name string, fn func(store app.TodoStore) error) error {
+	if err := validator.ValidateListName(name); err != nil {
+		return err
+	}
+	store, err := app.OpenStore(loadListConfig(name))
+	if err != nil {
+		return fmt.Errorf("failed to open list %s: %w", name, err)
+	}
+	if closer, ok := domain.As[io.Closer](store); ok {
+		defer closer.Close()
+	}
+	if locker, ok := domain.As[app.Locker](store); ok {
+		if err := locker.Lock(); err != nil {
+			return err
+		}
+		defer locker.Unlock()
+	}
+	return fn(store)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/SongRunqi/go-todo/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var moveTo string
+
+// moveCmd moves a task to another list
+var moveCmd = &cobra.Command{
+	Use:   "move <id> --to <list>",
+	Short: "",
+	Long:  "",
+	Example: `  todo move 3 --to work
+  todo --list work move 7 --to home`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.invalid_task_id"), args[0])
+			os.Exit(1)
+		}
+		if err := runMove(ctx, id, moveTo); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(moveCmd)
+	moveCmd.Flags().StringVar(&moveTo, "to", "", "Name of the list to move the task to")
+	_ = moveCmd.MarkFlagRequired("to")
+}
+
+func runMove(ctx *AppContext, id int, to string) error {
+	if err := validator.ValidateListName(to); err != nil {
+		return err
+	}
+	if to == ctx.Config.List {
+		return fmt.Errorf("task %d is already in list %s", id, to)
+	}
+	return withListStore(to, func(target app.TodoStore) error {
+		return moveTask(ctx, id, target, to)
+	})
+}
+
+// moveTask moves task id from the current list to the open target store
+func moveTask(ctx *AppContext, id int, target app.TodoStore, to string) error {
+	newID, err := app.MoveTask(ctx.Todos, id, ctx.Store, ctx.Config.List, target, to)
+	if err != nil {
+		return err
+	}
+	output.PrintSuccess("%s", i18n.T("cmd.move.success", id, to, newID))
+	return nil
+}
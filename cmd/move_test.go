@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/storage"
+)
+
+func TestMoveTask_RenumbersInTargetList(t *testing.T) {
+	ctx, store := newTestContext(t, []app.TodoItem{
+		{TaskID: 1, TaskName: "Stay", Status: "pending"},
+		{TaskID: 2, TaskName: "Go to work", Status: "pending"},
+	})
+	ctx.Config.List = "default"
+	target := storage.NewMemoryStore()
+	if err := target.Save([]app.TodoItem{{TaskID: 1, TaskName: "Existing", Status: "pending"}}, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := moveTask(ctx, 2, target, "work"); err != nil {
+		t.Fatalf("moveTask failed: %v", err)
+	}
+
+	active, _ := store.Load(false)
+	if len(active) != 1 || active[0].TaskID != 1 || len(*ctx.Todos) != 1 {
+		t.Errorf("Expected only task 1 to stay, got %+v", active)
+	}
+	moved, _ := target.Load(false)
+	if len(moved) != 2 || moved[1].TaskID != 2 || moved[1].TaskName != "Go to work" {
+		t.Errorf("Expected the task to be appended to the target as #2, got %+v", moved)
+	}
+
+	if err := moveTask(ctx, 5, target, "work"); err == nil {
+		t.Error("Moving a missing task should fail")
+	}
+}
+
+func TestRunMove_RejectsSameList(t *testing.T) {
+	ctx, _ := newTestContext(t, []app.TodoItem{{TaskID: 1, TaskName: "Task", Status: "pending"}})
+	ctx.Config.List = "work"
+
+	if err := runMove(ctx, 1, "work"); err == nil {
+		t.Error("Moving a task to its own list should fail")
+	}
+	if err := runMove(ctx, 1, "../home"); err == nil {
+		t.Error("An invalid list name should be rejected")
+	}
+}
+
+func TestListNames(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"work", "home", ".hidden"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+	}
+
+	cfg := app.Config{List: "side-project", ListsDir: dir}
+	names, err := listNames(cfg)
+	if err != nil {
+		t.Fatalf("listNames failed: %v", err)
+	}
+	want := []string{"default", "home", "side-project", "work"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var pauseUntil string
+
+// pauseCmd pauses a recurring task
+var pauseCmd = &cobra.Command{
+	Use:   "pause <id> [--until DATE]",
+	Short: "",
+	Long:  "",
+	Example: `  todo pause 3
+  todo pause 3 --until 2026-11-02`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.invalid_task_id"), args[0])
+			os.Exit(1)
+		}
+		if err := runPause(ctx, id, pauseUntil); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pauseCmd)
+	pauseCmd.Flags().StringVar(&pauseUntil, "until", "", "Day to resume the task on (YYYY-MM-DD)")
+}
+
+func runPause(ctx *AppContext, id int, until string) error {
+	var day time.Time
+	if until != "" {
+		var err error
+		if day, err = time.ParseInLocation("2006-01-02", until, time.Local); err != nil {
+			return fmt.Errorf("invalid --until %q, expected YYYY-MM-DD", until)
+		}
+	}
+	err := retryOnConflict(ctx, func() error {
+		return app.PauseRecurring(ctx.Todos, id, day, ctx.Store, ctx.CurrentTime)
+	})
+	if err != nil {
+		return err
+	}
+	if day.IsZero() {
+		output.PrintSuccess("%s", i18n.T("cmd.pause.success", id))
+	} else {
+		output.PrintSuccess("%s", i18n.T("cmd.pause.success_until", id, day.Format("2006-01-02")))
+	}
+	return nil
+}
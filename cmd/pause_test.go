@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+)
+
+// dailyStretch returns a daily task whose occurrence at 09:00 on the day of
+// now is pending
+func dailyStretch(now time.Time) app.TodoItem {
+	first := time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, now.Location())
+	return app.TodoItem{
+		TaskID: 1, TaskName: "Stretch", Status: "active", EndTime: first, DueDate: first.Format("2006-01-02"),
+		IsRecurring: true, RecurringType: "daily", RecurringInterval: 1,
+		RecurrenceRule: "FREQ=DAILY", RecurrenceStart: first,
+		OccurrenceHistory: []app.OccurrenceRecord{{ScheduledTime: first, Status: "pending"}},
+	}
+}
+
+func TestRunPauseAndResume(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+	ctx, store := newTestContext(t, []app.TodoItem{dailyStretch(now)})
+	ctx.CurrentTime = now
+
+	if err := runPause(ctx, 1, ""); err != nil {
+		t.Fatalf("runPause failed: %v", err)
+	}
+	if err := runPause(ctx, 1, ""); err == nil {
+		t.Error("Expected an error pausing a paused task")
+	}
+
+	// Nothing is missed or scheduled while the task is paused
+	later := now.AddDate(0, 0, 3)
+	if changes, _ := app.ReconcileRecurring(ctx.Todos, ctx.Store, later); len(changes) != 0 {
+		t.Errorf("Expected a paused task to be left alone, got %+v", changes)
+	}
+
+	ctx.CurrentTime = later
+	if err := runResume(ctx, 1); err != nil {
+		t.Fatalf("runResume failed: %v", err)
+	}
+	active, _ := store.Load(false)
+	stretch := active[0]
+	next := time.Date(2026, 10, 20, 9, 0, 0, 0, time.Local)
+	if stretch.Status != "active" || !stretch.EndTime.Equal(next) || len(stretch.OccurrenceHistory) != 1 ||
+		!stretch.OccurrenceHistory[0].ScheduledTime.Equal(next) {
+		t.Errorf("Expected the schedule to start afresh on %v, got %+v", next, stretch)
+	}
+	if err := runResume(ctx, 1); err == nil {
+		t.Error("Expected an error resuming an active task")
+	}
+}
+
+func TestRunPause_Until(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+	ctx, store := newTestContext(t, []app.TodoItem{dailyStretch(now)})
+	ctx.CurrentTime = now
+
+	if err := runPause(ctx, 1, "2026-10-17"); err == nil {
+		t.Error("Expected an error pausing until a day that has come")
+	}
+	if err := runPause(ctx, 1, "2026-10-19"); err != nil {
+		t.Fatalf("runPause failed: %v", err)
+	}
+	if changes, _ := app.ReconcileRecurring(ctx.Todos, ctx.Store, now.AddDate(0, 0, 1)); len(changes) != 0 {
+		t.Errorf("Expected the task to stay paused before the 19th, got %+v", changes)
+	}
+
+	// Two days after the pause ended the task resumed from the 19th
+	changes, err := app.ReconcileRecurring(ctx.Todos, ctx.Store, now.AddDate(0, 0, 4))
+	if err != nil {
+		t.Fatalf("ReconcileRecurring failed: %v", err)
+	}
+	next := time.Date(2026, 10, 21, 9, 0, 0, 0, time.Local)
+	if len(changes) != 1 || !changes[0].Resumed || changes[0].Missed != 2 || !changes[0].Next.Equal(next) {
+		t.Fatalf("Expected the task resumed with the 19th and 20th missed, got %+v", changes)
+	}
+	active, _ := store.Load(false)
+	if active[0].Status != "active" || !active[0].PausedUntil.IsZero() || !active[0].EndTime.Equal(next) {
+		t.Errorf("Expected an active task due on %v, got %+v", next, active[0])
+	}
+}
+
+func TestComplete_RejectsPausedAndCancelledTasks(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+	ctx, store := newTestContext(t, []app.TodoItem{dailyStretch(now)})
+	ctx.CurrentTime = now
+
+	if err := runPause(ctx, 1, ""); err != nil {
+		t.Fatalf("runPause failed: %v", err)
+	}
+	if err := app.Complete(ctx.Todos, &app.TodoItem{TaskID: 1}, ctx.Store); err == nil {
+		t.Error("Expected an error completing a paused task")
+	}
+	if err := runCancel(ctx, 1); err != nil {
+		t.Fatalf("runCancel failed: %v", err)
+	}
+	if err := app.Complete(ctx.Todos, &app.TodoItem{TaskID: 1}, ctx.Store); err == nil {
+		t.Error("Expected an error completing a cancelled task")
+	}
+
+	active, _ := store.Load(false)
+	if history := active[0].OccurrenceHistory; len(history) != 0 {
+		t.Errorf("Expected no occurrences to be completed or scheduled, got %+v", history)
+	}
+}
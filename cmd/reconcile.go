@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// reconcileCmd catches recurring tasks up with the current time
+var reconcileCmd = &cobra.Command{
+	Use:     "reconcile",
+	Short:   "",
+	Long:    "",
+	Example: `  todo reconcile`,
+	Args:    cobra.NoArgs,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Skip the automatic reconciling, so this command reports what changes
+		setupApp(cmd, true)
+		applyArchivePolicy(getAppContext(cmd))
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runReconcile(getAppContext(cmd)); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reconcileCmd)
+}
+
+func runReconcile(ctx *AppContext) error {
+	changes, err := app.ReconcileRecurring(ctx.Todos, ctx.Store, ctx.CurrentTime)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		output.PrintInfo("%s", i18n.T("cmd.reconcile.up_to_date"))
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Printf("  #%d %s: %s\n", change.TaskID, change.TaskName, describeReconciliation(change))
+	}
+	output.PrintSuccess("%s", i18n.T("cmd.reconcile.success", len(changes)))
+	return nil
+}
+
+// describeReconciliation summarises what reconciling changed on a task
+func describeReconciliation(change app.Reconciliation) string {
+	var parts []string
+	if change.Resumed {
+		parts = append(parts, "resumed")
+	}
+	if change.Missed > 0 {
+		parts = append(parts, fmt.Sprintf("%d missed", change.Missed))
+	}
+	if change.Added > 0 {
+		parts = append(parts, fmt.Sprintf("%d scheduled", change.Added))
+	}
+	if change.Next.IsZero() {
+		parts = append(parts, "series ended")
+	} else {
+		parts = append(parts, "next "+change.Next.Format("2006-01-02 15:04"))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+)
+
+func TestReconcile_DailyTaskCatchesUp(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+	first := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+	ctx, store := newTestContext(t, []app.TodoItem{{
+		TaskID: 1, TaskName: "Water plants", Status: "active", EndTime: first, DueDate: "2026-10-14",
+		IsRecurring: true, RecurringType: "daily", RecurringInterval: 1,
+		RecurrenceRule: "FREQ=DAILY", RecurrenceStart: first,
+		OccurrenceHistory: []app.OccurrenceRecord{{ScheduledTime: first, Status: "pending"}},
+	}})
+	ctx.CurrentTime = now
+
+	changes, err := app.ReconcileRecurring(ctx.Todos, ctx.Store, now)
+	if err != nil {
+		t.Fatalf("ReconcileRecurring failed: %v", err)
+	}
+	today := first.AddDate(0, 0, 3)
+	if len(changes) != 1 || changes[0].Missed != 3 || changes[0].Added != 1 || !changes[0].Next.Equal(today) {
+		t.Fatalf("Expected 3 missed and today's occurrence next, got %+v", changes)
+	}
+
+	active, _ := store.Load(false)
+	task := active[0]
+	if !task.EndTime.Equal(today) || task.DueDate != "2026-10-17" || len(task.OccurrenceHistory) != 4 {
+		t.Fatalf("Expected the task to move to today, got %+v", task)
+	}
+	for i, occ := range task.OccurrenceHistory {
+		want := "missed"
+		if i == 3 {
+			want = "pending"
+		}
+		if !occ.ScheduledTime.Equal(first.AddDate(0, 0, i)) || occ.Status != want {
+			t.Errorf("Occurrence %d: got %v %s, want %s", i, occ.ScheduledTime, occ.Status, want)
+		}
+	}
+
+	// Today's occurrence stays pending until the day is over
+	if changes, _ := app.ReconcileRecurring(ctx.Todos, ctx.Store, now.Add(11*time.Hour)); len(changes) != 0 {
+		t.Errorf("Expected nothing to reconcile later the same day, got %+v", changes)
+	}
+}
+
+func TestReconcile_WeekdayTaskSkipsPassedPeriods(t *testing.T) {
+	// Saturday; the period four weeks ago is half done and nothing since
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+	ctx, store := newTestContext(t, []app.TodoItem{biweeklyClass(now, 4)})
+	monday := (*ctx.Todos)[0].RecurrenceStart
+
+	changes, err := app.ReconcileRecurring(ctx.Todos, ctx.Store, now)
+	if err != nil {
+		t.Fatalf("ReconcileRecurring failed: %v", err)
+	}
+	next := monday.AddDate(0, 0, 42)
+	if len(changes) != 1 || changes[0].Missed != 5 || changes[0].Added != 2 || !changes[0].Next.Equal(next) {
+		t.Fatalf("Expected 5 missed and the period in two weeks next, got %+v", changes)
+	}
+
+	task, _ := store.Load(false)
+	if !task[0].EndTime.Equal(next) || len(task[0].OccurrenceHistory) != 8 {
+		t.Errorf("Expected EndTime %v with three new periods, got %+v", next, task[0])
+	}
+}
+
+func TestReconcile_EndedRuleCompletesTask(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+	first := time.Date(2026, 10, 5, 9, 0, 0, 0, time.Local)
+	ctx, store := newTestContext(t, []app.TodoItem{
+		{
+			TaskID: 1, TaskName: "Course", Status: "active", EndTime: first.AddDate(0, 0, 1),
+			IsRecurring: true, RecurringType: "daily", RecurringInterval: 1,
+			RecurrenceRule: "FREQ=DAILY;COUNT=2", RecurrenceStart: first,
+			OccurrenceHistory: []app.OccurrenceRecord{
+				{ScheduledTime: first, Status: "completed"},
+				{ScheduledTime: first.AddDate(0, 0, 1), Status: "pending"},
+			},
+		},
+		{TaskID: 2, TaskName: "Paused", Status: "paused", EndTime: first, IsRecurring: true, RecurringType: "daily",
+			OccurrenceHistory: []app.OccurrenceRecord{{ScheduledTime: first, Status: "pending"}}},
+	})
+
+	changes, err := app.ReconcileRecurring(ctx.Todos, ctx.Store, now)
+	if err != nil {
+		t.Fatalf("ReconcileRecurring failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].TaskID != 1 || changes[0].Missed != 1 || !changes[0].Next.IsZero() {
+		t.Fatalf("Expected only task 1 to end with one missed occurrence, got %+v", changes)
+	}
+	active, _ := store.Load(false)
+	if active[0].Status != "completed" || !active[0].CompletedAt.Equal(now) {
+		t.Errorf("Expected the ended series to be completed, got %+v", active[0])
+	}
+	if active[1].OccurrenceHistory[0].Status != "pending" {
+		t.Errorf("Expected the paused task to be left alone, got %+v", active[1])
+	}
+}
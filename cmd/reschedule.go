@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rescheduleDate string
+	rescheduleFrom string
+)
+
+// rescheduleCmd moves a single occurrence of a recurring task
+var rescheduleCmd = &cobra.Command{
+	Use:   "reschedule <id> --date WHEN [--from DATE]",
+	Short: "",
+	Long:  "",
+	Example: `  todo reschedule 3 --date 2026-10-22
+  todo reschedule 3 --from 2026-10-21 --date "2026-10-22 16:00"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.invalid_task_id"), args[0])
+			os.Exit(1)
+		}
+		if err := runReschedule(ctx, id, rescheduleFrom, rescheduleDate); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rescheduleCmd)
+	rescheduleCmd.Flags().StringVar(&rescheduleDate, "date", "", "When to move the occurrence to (YYYY-MM-DD or YYYY-MM-DD HH:MM)")
+	rescheduleCmd.Flags().StringVar(&rescheduleFrom, "from", "", "Day of the occurrence to move (YYYY-MM-DD)")
+	_ = rescheduleCmd.MarkFlagRequired("date")
+}
+
+func runReschedule(ctx *AppContext, id int, from, date string) error {
+	var day time.Time
+	if from != "" {
+		var err error
+		if day, err = time.ParseInLocation("2006-01-02", from, time.Local); err != nil {
+			return fmt.Errorf("invalid --from %q, expected YYYY-MM-DD", from)
+		}
+	}
+	to, keepTime := time.Time{}, false
+	if t, err := time.ParseInLocation("2006-01-02 15:04", date, time.Local); err == nil {
+		to = t
+	} else if t, err := time.ParseInLocation("2006-01-02", date, time.Local); err == nil {
+		to, keepTime = t, true
+	} else {
+		return fmt.Errorf("invalid --date %q, expected YYYY-MM-DD or YYYY-MM-DD HH:MM", date)
+	}
+
+	var moved app.OccurrenceRecord
+	err := retryOnConflict(ctx, func() error {
+		var err error
+		moved, err = app.RescheduleOccurrence(ctx.Todos, id, day, to, keepTime, ctx.Store, ctx.CurrentTime)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	output.PrintSuccess("%s", i18n.T("cmd.reschedule.success",
+		moved.OriginalTime.Format("2006-01-02 15:04"), id, moved.ScheduledTime.Format("2006-01-02 15:04")))
+	printNextOccurrence(ctx, id)
+	return nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+)
+
+func TestRunReschedule_StaysInItsPeriod(t *testing.T) {
+	ctx, store := newTestContext(t, []app.TodoItem{biweeklyClass(time.Now(), 0)})
+	monday := (*ctx.Todos)[0].RecurrenceStart
+	wednesday, tuesday := monday.AddDate(0, 0, 2), monday.AddDate(0, 0, 8)
+
+	// Move Wednesday's class to the Tuesday after, keeping its time
+	if err := runReschedule(ctx, 1, wednesday.Format("2006-01-02"), tuesday.Format("2006-01-02")); err != nil {
+		t.Fatalf("runReschedule failed: %v", err)
+	}
+	active, _ := store.Load(false)
+	moved := active[0].OccurrenceHistory[1]
+	if !moved.ScheduledTime.Equal(tuesday) || !moved.OriginalTime.Equal(wednesday) || !active[0].EndTime.Equal(tuesday) {
+		t.Fatalf("Expected Wednesday moved to %v, got %+v", tuesday, active[0])
+	}
+
+	// Completing it completes the original week, and the next period
+	// follows the schedule rather than the move
+	if err := app.Complete(ctx.Todos, &app.TodoItem{TaskID: 1}, ctx.Store); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	active, _ = store.Load(false)
+	next := monday.AddDate(0, 0, 14)
+	if active[0].CompletionCount != 1 || !active[0].EndTime.Equal(next) {
+		t.Errorf("Expected the period completed and the next one on %v, got %+v", next, active[0])
+	}
+}
+
+func TestRunReschedule_Errors(t *testing.T) {
+	ctx, _ := newTestContext(t, []app.TodoItem{
+		biweeklyClass(time.Now(), 0),
+		{TaskID: 2, TaskName: "Once", Status: "pending"},
+	})
+	monday := (*ctx.Todos)[0].RecurrenceStart
+
+	for name, tt := range map[string]struct {
+		id         int
+		from, date string
+	}{
+		"past date":       {1, "", monday.AddDate(0, 0, -7).Format("2006-01-02")},
+		"occupied time":   {1, "", monday.Format("2006-01-02 15:04")},
+		"no occurrence":   {1, monday.AddDate(0, 0, 1).Format("2006-01-02"), monday.AddDate(0, 0, 9).Format("2006-01-02")},
+		"not recurring":   {2, "", monday.AddDate(0, 0, 9).Format("2006-01-02")},
+		"unreadable date": {1, "", "thursday"},
+	} {
+		if err := runReschedule(ctx, tt.id, tt.from, tt.date); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// resumeCmd resumes a paused recurring task
+var resumeCmd = &cobra.Command{
+	Use:     "resume <id>",
+	Short:   "",
+	Long:    "",
+	Example: `  todo resume 3`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.invalid_task_id"), args[0])
+			os.Exit(1)
+		}
+		if err := runResume(ctx, id); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resumeCmd)
+}
+
+func runResume(ctx *AppContext, id int) error {
+	err := retryOnConflict(ctx, func() error {
+		return app.ResumeRecurring(ctx.Todos, id, ctx.Store, ctx.CurrentTime)
+	})
+	if err != nil {
+		return err
+	}
+	output.PrintSuccess("%s", i18n.T("cmd.resume.success", id))
+	printNextOccurrence(ctx, id)
+	return nil
+}
@@ -2,30 +2,41 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"sync"
 	"time"
 
 	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/domain"
 	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/journal"
 	"github.com/SongRunqi/go-todo/internal/logger"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/SongRunqi/go-todo/internal/validator"
 	"github.com/spf13/cobra"
 )
 
 var (
-	cfgFile string
-	verbose bool
+	cfgFile  string
+	verbose  bool
+	listName string
 )
 
 // AppContext holds the application context shared across commands
 type AppContext struct {
-	Store       *app.FileTodoStore
+	Store       app.TodoStore
 	Todos       *[]app.TodoItem
 	Config      app.Config
 	CurrentTime time.Time
 }
 
+// loadListConfig loads the configuration of the named task list; tests
+// point it at a temporary directory
+var loadListConfig = app.LoadListConfig
+
 var (
 	descriptionsOnce                 sync.Once
 	updateSubcommandDescriptionsFunc func()
@@ -36,61 +47,183 @@ func getAppContext(cmd *cobra.Command) *AppContext {
 	return cmd.Context().Value("appContext").(*AppContext)
 }
 
-// rootCmd represents the base command when called without any subcommands
-var rootCmd = &cobra.Command{
-	Use:   "todo command",
-	Short: "",
-	Long:  "",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// Initialize logger
-		logLevel := os.Getenv("LOG_LEVEL")
-		if logLevel == "" {
-			logLevel = "info"
-		}
-		logger.Init(logLevel)
+// retryOnConflict runs op and, if the save was aborted because the task file
+// changed on disk after it was loaded, reloads the tasks and re-applies op
+// once. Only use it for operations that save a single file, so a partial
+// first attempt cannot be applied twice.
+func retryOnConflict(ctx *AppContext, op func() error) error {
+	err := op()
+	if !errors.Is(err, app.ErrConflict) {
+		return err
+	}
 
-		// Initialize configuration
-		config := app.LoadConfig()
+	output.PrintWarning("%s", i18n.T("cmd.root.warning.conflict_retry"))
+	todos, loadErr := ctx.Store.Load(false)
+	if loadErr != nil {
+		return fmt.Errorf("failed to reload todos after conflict: %w", loadErr)
+	}
+	*ctx.Todos = todos
+	return op()
+}
 
-		// Initialize i18n (may have been initialized in init(), reinit with config language)
-		if config.Language != "" {
-			if err := i18n.SetLanguage(config.Language); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to set language: %v\n", err)
-			}
-		}
+// setupApp initializes logging, configuration and i18n, opens and locks the
+// task store and attaches an AppContext to cmd. When loadTodos is false the
+// active list is not read, for commands that must work even when it is
+// unreadable; ctx.Todos is then empty.
+func setupApp(cmd *cobra.Command, loadTodos bool) {
+	// Initialize logger
+	logLevel := os.Getenv("LOG_LEVEL")
+	if logLevel == "" {
+		logLevel = "info"
+	}
+	logger.Init(logLevel)
 
-		// Update subcommand descriptions (once) after all commands are registered
-		if updateSubcommandDescriptionsFunc != nil {
-			descriptionsOnce.Do(updateSubcommandDescriptionsFunc)
+	// Initialize configuration for the list selected with --list
+	config := loadListConfig(listName)
+	if err := validator.ValidateListName(config.List); err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+		os.Exit(1)
+	}
+
+	// Initialize i18n (may have been initialized in init(), reinit with config language)
+	if config.Language != "" {
+		if err := i18n.SetLanguage(config.Language); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to set language: %v\n", err)
 		}
+	}
 
-		// Initialize store
-		store := &app.FileTodoStore{
-			Path:       config.TodoPath,
-			BackupPath: config.BackupPath,
+	// Update subcommand descriptions (once) after all commands are registered
+	if updateSubcommandDescriptionsFunc != nil {
+		descriptionsOnce.Do(updateSubcommandDescriptionsFunc)
+	}
+
+	// Initialize store
+	store, err := app.OpenStore(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.open_store"), err)
+		os.Exit(1)
+	}
+
+	// Hold the store lock for the whole load-modify-save cycle so
+	// concurrent invocations (Alfred, scripts, terminal) cannot interleave
+	if locker, ok := domain.As[app.Locker](store); ok {
+		if err := locker.Lock(); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.lock"), err)
+			os.Exit(1)
 		}
+	}
 
-		// Load todos
-		loadedTodos, err := store.Load(false)
+	// Load todos
+	loadedTodos := make([]app.TodoItem, 0)
+	if loadTodos {
+		loadedTodos, err = store.Load(false)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.loading_todos"), err)
 			os.Exit(1)
 		}
-		// Allocate a new slice on the heap to avoid dangling pointer
-		todosList := loadedTodos
-		todos := &todosList
-		currentTime := time.Now()
-
-		// Create AppContext and attach it to the command context
-		appCtx := &AppContext{
-			Store:       store,
-			Todos:       todos,
-			Config:      config,
-			CurrentTime: currentTime,
-		}
+	}
+	// Allocate a new slice on the heap to avoid dangling pointer
+	todosList := loadedTodos
+	todos := &todosList
+	currentTime := time.Now()
+
+	// Create AppContext and attach it to the command context
+	appCtx := &AppContext{
+		Store:       store,
+		Todos:       todos,
+		Config:      config,
+		CurrentTime: currentTime,
+	}
+
+	ctx := context.WithValue(cmd.Context(), "appContext", appCtx)
+	cmd.SetContext(ctx)
+}
+
+// maintenanceStore journals the operations saved through it as
+// maintenance, so the automatic upkeep done before a command runs is neither
+// undone in place of the user's last operation nor discards the redo stack
+type maintenanceStore struct {
+	app.TodoStore
+}
+
+// Unwrap returns the wrapped store
+func (s maintenanceStore) Unwrap() app.TodoStore {
+	return s.TodoStore
+}
+
+// BeginOperation labels the saves that follow as maintenance
+func (s maintenanceStore) BeginOperation(op, desc string) {
+	if recorder, ok := domain.As[domain.OperationRecorder](s.TodoStore); ok {
+		recorder.BeginOperation(journal.OpMaintenance, desc)
+	}
+}
+
+// applyArchivePolicy moves completed tasks older than the configured
+// archive age to the backup list before a command sees the active list
+func applyArchivePolicy(ctx *AppContext) {
+	if ctx.Config.ArchiveAfterDays <= 0 {
+		return
+	}
+	archived, err := app.ArchiveCompleted(ctx.Todos, maintenanceStore{ctx.Store}, ctx.CurrentTime, ageInDays(ctx.Config.ArchiveAfterDays))
+	if err != nil {
+		logger.Warnf("Failed to archive completed tasks: %v", err)
+		return
+	}
+	if len(archived) > 0 {
+		logger.Debugf("Archived %d completed tasks", len(archived))
+	}
+}
+
+// applyReconcile catches recurring tasks up with the current time before a
+// command sees the active list, so occurrences from earlier days are missed
+// rather than completed late as if they were current
+func applyReconcile(ctx *AppContext) {
+	changes, err := app.ReconcileRecurring(ctx.Todos, maintenanceStore{ctx.Store}, ctx.CurrentTime)
+	if err != nil {
+		logger.Warnf("Failed to reconcile recurring tasks: %v", err)
+		return
+	}
+	if len(changes) > 0 {
+		logger.Debugf("Reconciled %d recurring tasks", len(changes))
+	}
+}
+
+// setupWithUpkeep sets up the app and brings the active list up to date
+// before the command runs
+func setupWithUpkeep(cmd *cobra.Command, args []string) {
+	setupApp(cmd, true)
+	applyArchivePolicy(getAppContext(cmd))
+	applyReconcile(getAppContext(cmd))
+}
+
+// setupReadOnly sets up the app without the automatic upkeep, which can
+// save, journal and snapshot the lists, for commands that only read them
+func setupReadOnly(cmd *cobra.Command, args []string) {
+	setupApp(cmd, true)
+}
 
-		ctx := context.WithValue(cmd.Context(), "appContext", appCtx)
-		cmd.SetContext(ctx)
+// rootCmd represents the base command when called without any subcommands
+var rootCmd = &cobra.Command{
+	Use:              "todo command",
+	Short:            "",
+	Long:             "",
+	PersistentPreRun: setupWithUpkeep,
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		// Commands that override PersistentPreRun never create an AppContext
+		appCtx, ok := cmd.Context().Value("appContext").(*AppContext)
+		if !ok {
+			return
+		}
+		if locker, ok := domain.As[app.Locker](appCtx.Store); ok {
+			if err := locker.Unlock(); err != nil {
+				logger.Warnf("Failed to release todo lock: %v", err)
+			}
+		}
+		if closer, ok := domain.As[io.Closer](appCtx.Store); ok {
+			if err := closer.Close(); err != nil {
+				logger.Warnf("Failed to close todo store: %v", err)
+			}
+		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
@@ -103,6 +236,10 @@ func Execute() {
 	rootCmd.SilenceErrors = true
 	rootCmd.SilenceUsage = true
 
+	// Commands registered after this package's init() ran have no
+	// descriptions yet, which help output would show empty
+	updateSubcommandDescriptionsFunc()
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -130,6 +267,7 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.todo/config.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().StringVarP(&listName, "list", "L", "", "task list to use (default is \"defaultList\" in config.json, or \"default\")")
 
 	// Define the updateSubcommandDescriptionsFunc after rootCmd is initialized
 	updateSubcommandDescriptionsFunc = func() {
@@ -165,6 +303,9 @@ func init() {
 					case "restore":
 						sc.Short = i18n.T("cmd.back.restore.short")
 						sc.Long = i18n.T("cmd.back.restore.long")
+					case "purge":
+						sc.Short = i18n.T("cmd.back.purge.short")
+						sc.Long = i18n.T("cmd.back.purge.long")
 					}
 				}
 			case "lang":
@@ -184,6 +325,97 @@ func init() {
 						sc.Long = i18n.T("cmd.lang.current.long")
 					}
 				}
+			case "archive":
+				c.Short = i18n.T("cmd.archive.short")
+				c.Long = i18n.T("cmd.archive.long")
+			case "cancel":
+				c.Short = i18n.T("cmd.cancel.short")
+				c.Long = i18n.T("cmd.cancel.long")
+			case "db":
+				c.Short = i18n.T("cmd.db.short")
+				c.Long = i18n.T("cmd.db.long")
+				// Set db subcommands
+				for _, sc := range c.Commands() {
+					switch sc.Name() {
+					case "import":
+						sc.Short = i18n.T("cmd.db.import.short")
+						sc.Long = i18n.T("cmd.db.import.long")
+					}
+				}
+			case "doctor":
+				c.Short = i18n.T("cmd.doctor.short")
+				c.Long = i18n.T("cmd.doctor.long")
+			case "encrypt":
+				c.Short = i18n.T("cmd.encrypt.short")
+				c.Long = i18n.T("cmd.encrypt.long")
+			case "decrypt":
+				c.Short = i18n.T("cmd.decrypt.short")
+				c.Long = i18n.T("cmd.decrypt.long")
+			case "export":
+				c.Short = i18n.T("cmd.export.short")
+				c.Long = i18n.T("cmd.export.long")
+			case "history":
+				c.Short = i18n.T("cmd.history.short")
+				c.Long = i18n.T("cmd.history.long")
+			case "import":
+				c.Short = i18n.T("cmd.import.short")
+				c.Long = i18n.T("cmd.import.long")
+			case "journal":
+				c.Short = i18n.T("cmd.journal.short")
+				c.Long = i18n.T("cmd.journal.long")
+				// Set journal subcommands
+				for _, sc := range c.Commands() {
+					switch sc.Name() {
+					case "log":
+						sc.Short = i18n.T("cmd.journal.log.short")
+					case "replay":
+						sc.Short = i18n.T("cmd.journal.replay.short")
+						sc.Long = i18n.T("cmd.journal.replay.long")
+					}
+				}
+			case "lists":
+				c.Short = i18n.T("cmd.lists.short")
+				c.Long = i18n.T("cmd.lists.long")
+			case "move":
+				c.Short = i18n.T("cmd.move.short")
+				c.Long = i18n.T("cmd.move.long")
+			case "pause":
+				c.Short = i18n.T("cmd.pause.short")
+				c.Long = i18n.T("cmd.pause.long")
+			case "reconcile":
+				c.Short = i18n.T("cmd.reconcile.short")
+				c.Long = i18n.T("cmd.reconcile.long")
+			case "reschedule":
+				c.Short = i18n.T("cmd.reschedule.short")
+				c.Long = i18n.T("cmd.reschedule.long")
+			case "resume":
+				c.Short = i18n.T("cmd.resume.short")
+				c.Long = i18n.T("cmd.resume.long")
+			case "skip":
+				c.Short = i18n.T("cmd.skip.short")
+				c.Long = i18n.T("cmd.skip.long")
+			case "snapshot":
+				c.Short = i18n.T("cmd.snapshot.short")
+				c.Long = i18n.T("cmd.snapshot.long")
+				// Set snapshot subcommands
+				for _, sc := range c.Commands() {
+					switch sc.Name() {
+					case "list":
+						sc.Short = i18n.T("cmd.snapshot.list.short")
+					case "restore":
+						sc.Short = i18n.T("cmd.snapshot.restore.short")
+						sc.Long = i18n.T("cmd.snapshot.restore.long")
+					}
+				}
+			case "sync":
+				c.Short = i18n.T("cmd.sync.short")
+				c.Long = i18n.T("cmd.sync.long")
+			case "undo":
+				c.Short = i18n.T("cmd.undo.short")
+				c.Long = i18n.T("cmd.undo.long")
+			case "redo":
+				c.Short = i18n.T("cmd.redo.short")
+				c.Long = i18n.T("cmd.redo.long")
 			}
 		}
 	}
@@ -198,6 +430,7 @@ var completionCmd = &cobra.Command{
 	Short:                 "",
 	Long:                  "",
 	DisableFlagsInUseLine: true,
+	PersistentPreRun:      setupReadOnly,
 	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
 	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
 	Run: func(cmd *cobra.Command, args []string) {
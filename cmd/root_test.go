@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/spf13/cobra"
+)
+
+// useTestConfig points the configuration setupApp loads at a temporary
+// directory holding todos, journaled and without snapshots
+func useTestConfig(t *testing.T, todos []app.TodoItem) *app.Config {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := app.LoadListConfig("")
+	cfg.Storage = "file"
+	cfg.TodoPath = filepath.Join(dir, "todo.json")
+	cfg.BackupPath = filepath.Join(dir, "todo_back.json")
+	cfg.JournalPath = filepath.Join(dir, "journal.jsonl")
+	cfg.SnapshotDir = ""
+	cfg.Encrypt = false
+	cfg.GitHistory = false
+	cfg.ArchiveAfterDays = 0
+
+	store, err := app.OpenStore(cfg)
+	if err != nil {
+		t.Fatalf("OpenStore failed: %v", err)
+	}
+	if err := store.Save(todos, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	saved := loadListConfig
+	t.Cleanup(func() { loadListConfig = saved })
+	loadListConfig = func(string) app.Config { return cfg }
+	return &cfg
+}
+
+// invoke runs the persistent hooks of cmd around run, as Execute would
+func invoke(t *testing.T, cmd *cobra.Command, run func(ctx *AppContext) error) {
+	t.Helper()
+	cmd.SetContext(context.Background())
+	// Like cobra, run the hook of the closest command that has one
+	parent := cmd
+	for parent.PersistentPreRun == nil {
+		parent = parent.Parent()
+	}
+	parent.PersistentPreRun(cmd, nil)
+	defer rootCmd.PersistentPostRun(cmd, nil)
+	if err := run(getAppContext(cmd)); err != nil {
+		t.Fatalf("%s failed: %v", cmd.Name(), err)
+	}
+}
+
+func TestPersistentPreRun_ArchivingIsNotUndone(t *testing.T) {
+	cfg := useTestConfig(t, []app.TodoItem{
+		{TaskID: 1, TaskName: "Done", Status: "completed", CompletedAt: time.Now().AddDate(0, 0, -30)},
+		{TaskID: 2, TaskName: "Oops", Status: "pending"},
+	})
+	invoke(t, deleteCmd, func(ctx *AppContext) error {
+		return runDelete(ctx, 2, "active")
+	})
+
+	// The next command archives task 1 before it runs
+	cfg.ArchiveAfterDays = 7
+	invoke(t, completeCmd, func(ctx *AppContext) error {
+		if len(*ctx.Todos) != 0 {
+			t.Errorf("Expected task 1 to be archived before the command, got %+v", *ctx.Todos)
+		}
+		return nil
+	})
+
+	// Undo reverts the delete, not the archiving
+	invoke(t, undoCmd, func(ctx *AppContext) error {
+		return runUndo(ctx, 1, false)
+	})
+	invoke(t, listCmd, func(ctx *AppContext) error {
+		if len(*ctx.Todos) != 1 || (*ctx.Todos)[0].TaskID != 2 || (*ctx.Todos)[0].Status != "pending" {
+			t.Errorf("Expected only task 2 back in the active list, got %+v", *ctx.Todos)
+		}
+		backup, err := ctx.Store.Load(true)
+		if len(backup) != 1 || backup[0].TaskID != 1 {
+			t.Errorf("Expected task 1 to stay archived, got %+v", backup)
+		}
+		return err
+	})
+
+	// Nor does archiving before redo discard it
+	invoke(t, redoCmd, func(ctx *AppContext) error {
+		return runUndo(ctx, 1, true)
+	})
+	invoke(t, listCmd, func(ctx *AppContext) error {
+		if len(*ctx.Todos) != 0 {
+			t.Errorf("Expected task 2 to be deleted again, got %+v", *ctx.Todos)
+		}
+		return nil
+	})
+}
+
+func TestPersistentPreRun_ReconcilingIsNotUndone(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, time.Local)
+	task := app.TodoItem{
+		TaskID: 1, TaskName: "Water plants", Status: "active", EndTime: today, DueDate: today.Format("2006-01-02"),
+		IsRecurring: true, RecurringType: "daily", RecurringInterval: 1,
+		RecurrenceRule: "FREQ=DAILY", RecurrenceStart: today,
+		OccurrenceHistory: []app.OccurrenceRecord{{ScheduledTime: today, Status: "pending"}},
+	}
+	cfg := useTestConfig(t, []app.TodoItem{task, {TaskID: 2, TaskName: "Oops", Status: "pending"}})
+	invoke(t, deleteCmd, func(ctx *AppContext) error {
+		return runDelete(ctx, 2, "active")
+	})
+
+	// Days pass and the task falls behind; move its schedule back instead
+	plain := *cfg
+	plain.JournalPath = ""
+	store, err := app.OpenStore(plain)
+	if err != nil {
+		t.Fatalf("OpenStore failed: %v", err)
+	}
+	first := today.AddDate(0, 0, -3)
+	task.EndTime, task.DueDate, task.RecurrenceStart = first, first.Format("2006-01-02"), first
+	task.OccurrenceHistory = []app.OccurrenceRecord{{ScheduledTime: first, Status: "pending"}}
+	if err := store.Save([]app.TodoItem{task}, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// The next command catches the task up before it runs, and undo still
+	// reverts the delete
+	invoke(t, completeCmd, func(ctx *AppContext) error {
+		if n := len((*ctx.Todos)[0].OccurrenceHistory); n != 4 {
+			t.Errorf("Expected the task to be caught up before the command, got %d occurrences", n)
+		}
+		return nil
+	})
+	invoke(t, undoCmd, func(ctx *AppContext) error {
+		return runUndo(ctx, 1, false)
+	})
+	invoke(t, listCmd, func(ctx *AppContext) error {
+		if len(*ctx.Todos) != 2 || (*ctx.Todos)[1].TaskID != 2 || (*ctx.Todos)[1].Status != "pending" {
+			t.Fatalf("Expected task 2 back in the active list, got %+v", *ctx.Todos)
+		}
+		if task := (*ctx.Todos)[0]; len(task.OccurrenceHistory) != 4 || !task.EndTime.Equal(today) {
+			t.Errorf("Expected task 1 to stay caught up, got %+v", task)
+		}
+		return nil
+	})
+}
+
+func TestPersistentPreRun_ReadOnlyCommandsSkipUpkeep(t *testing.T) {
+	first := time.Now().AddDate(0, 0, -3)
+	cfg := useTestConfig(t, []app.TodoItem{
+		{TaskID: 1, TaskName: "Done", Status: "completed", CompletedAt: time.Now().AddDate(0, 0, -30)},
+		{
+			TaskID: 2, TaskName: "Water plants", Status: "active", EndTime: first, DueDate: first.Format("2006-01-02"),
+			IsRecurring: true, RecurringType: "daily", RecurringInterval: 1,
+			RecurrenceRule: "FREQ=DAILY", RecurrenceStart: first,
+			OccurrenceHistory: []app.OccurrenceRecord{{ScheduledTime: first, Status: "pending"}},
+		},
+	})
+	cfg.ArchiveAfterDays = 7
+	cfg.SnapshotDir = t.TempDir()
+	cfg.SnapshotKeep = 5
+	journaled, err := os.ReadFile(cfg.JournalPath)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	// Reading the tasks neither archives nor reconciles them, so nothing
+	// is saved, journaled or snapshotted
+	for _, cmd := range []*cobra.Command{listCmd, getCmd, listsCmd, exportCmd, copyCmd, backCmd, backGetCmd} {
+		invoke(t, cmd, func(ctx *AppContext) error {
+			if len(*ctx.Todos) != 2 || len((*ctx.Todos)[1].OccurrenceHistory) != 1 {
+				t.Errorf("%s: expected the tasks as saved, got %+v", cmd.CommandPath(), *ctx.Todos)
+			}
+			return nil
+		})
+	}
+	if data, err := os.ReadFile(cfg.JournalPath); err != nil || !bytes.Equal(data, journaled) {
+		t.Errorf("Expected no journal entries, got %s (%v)", data, err)
+	}
+	if entries, err := os.ReadDir(cfg.SnapshotDir); err != nil || len(entries) != 0 {
+		t.Errorf("Expected no snapshots, got %v (%v)", entries, err)
+	}
+
+	// Changing them still brings the list up to date first
+	invoke(t, completeCmd, func(ctx *AppContext) error {
+		if len(*ctx.Todos) != 1 || len((*ctx.Todos)[0].OccurrenceHistory) != 4 {
+			t.Errorf("Expected task 1 archived and task 2 caught up, got %+v", *ctx.Todos)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var skipDate string
+
+// skipCmd skips a single occurrence of a recurring task
+var skipCmd = &cobra.Command{
+	Use:   "skip <id> [--date DATE]",
+	Short: "",
+	Long:  "",
+	Example: `  todo skip 3
+  todo skip 3 --date 2026-10-21`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.invalid_task_id"), args[0])
+			os.Exit(1)
+		}
+		if err := runSkip(ctx, id, skipDate); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(skipCmd)
+	skipCmd.Flags().StringVar(&skipDate, "date", "", "Day of the occurrence to skip (YYYY-MM-DD)")
+}
+
+func runSkip(ctx *AppContext, id int, date string) error {
+	var day time.Time
+	if date != "" {
+		var err error
+		if day, err = time.ParseInLocation("2006-01-02", date, time.Local); err != nil {
+			return fmt.Errorf("invalid --date %q, expected YYYY-MM-DD", date)
+		}
+	}
+	var skipped app.OccurrenceRecord
+	err := retryOnConflict(ctx, func() error {
+		var err error
+		skipped, err = app.SkipOccurrence(ctx.Todos, id, day, ctx.Store, ctx.CurrentTime)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	output.PrintSuccess("%s", i18n.T("cmd.skip.success", id, skipped.ScheduledTime.Format("2006-01-02 15:04")))
+	printNextOccurrence(ctx, id)
+	return nil
+}
+
+// printNextOccurrence tells where recurring task id goes next
+func printNextOccurrence(ctx *AppContext, id int) {
+	for _, task := range *ctx.Todos {
+		if task.TaskID != id {
+			continue
+		}
+		if task.Status != "active" {
+			output.PrintInfo("%s", i18n.T("cmd.skip.ended", id, task.Status))
+		} else if !task.EndTime.IsZero() {
+			output.PrintInfo("%s", i18n.T("cmd.skip.next", task.EndTime.Format("2006-01-02 15:04")))
+		}
+		return
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+)
+
+func TestRunSkip_LastOccurrenceCompletesPeriod(t *testing.T) {
+	ctx, store := newTestContext(t, []app.TodoItem{biweeklyClass(time.Now(), 0)})
+	monday := (*ctx.Todos)[0].RecurrenceStart
+
+	if err := runSkip(ctx, 1, monday.AddDate(0, 0, 2).Format("2006-01-02")); err != nil {
+		t.Fatalf("runSkip failed: %v", err)
+	}
+
+	active, _ := store.Load(false)
+	class := active[0]
+	if class.OccurrenceHistory[1].Status != "skipped" {
+		t.Errorf("Expected Wednesday to be skipped, got %+v", class.OccurrenceHistory[1])
+	}
+	next := monday.AddDate(0, 0, 14)
+	if class.CompletionCount != 1 || !class.EndTime.Equal(next) || len(class.OccurrenceHistory) != 4 {
+		t.Errorf("Expected the period to count and the next one on %v, got %+v", next, class)
+	}
+}
+
+func TestRunSkip_DailyTask(t *testing.T) {
+	today := time.Now().Truncate(time.Minute)
+	ctx, store := newTestContext(t, []app.TodoItem{{
+		TaskID: 1, TaskName: "Run", Status: "active", EndTime: today,
+		IsRecurring: true, RecurringType: "daily", RecurringInterval: 1, RecurrenceRule: "FREQ=DAILY", RecurrenceStart: today,
+		OccurrenceHistory: []app.OccurrenceRecord{{ScheduledTime: today, Status: "pending"}},
+	}})
+
+	if err := runSkip(ctx, 1, ""); err != nil {
+		t.Fatalf("runSkip failed: %v", err)
+	}
+	active, _ := store.Load(false)
+	run := active[0]
+	tomorrow := today.AddDate(0, 0, 1)
+	if run.CompletionCount != 0 || !run.EndTime.Equal(tomorrow) || run.OccurrenceHistory[0].Status != "skipped" {
+		t.Errorf("Expected today skipped and tomorrow next without a completion, got %+v", run)
+	}
+
+	if err := runSkip(ctx, 1, today.AddDate(0, 0, 5).Format("2006-01-02")); err == nil {
+		t.Error("Expected an error skipping a day without a pending occurrence")
+	}
+	if err := runSkip(ctx, 1, "next week"); err == nil {
+		t.Error("Expected an error for an unreadable date")
+	}
+}
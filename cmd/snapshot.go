@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/journal"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/SongRunqi/go-todo/internal/repository"
+	"github.com/SongRunqi/go-todo/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotRestoreYes bool
+	errSnapshotsOff    = errors.New("snapshots are disabled (snapshotKeep and snapshotDays are both 0)")
+)
+
+// maxDiffLines limits how many changed tasks a restore lists individually
+const maxDiffLines = 20
+
+// snapshotCmd groups the snapshot commands
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "",
+	Long:  "",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Skip the automatic upkeep, so a restore brings back the snapshot
+		// as it was
+		setupApp(cmd, true)
+	},
+}
+
+// snapshotListCmd lists the stored snapshots
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		if err := runSnapshotList(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+// snapshotRestoreCmd replaces a task file with a snapshot
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <name>",
+	Short: "",
+	Long:  "",
+	Example: `  todo snapshot restore todo-20250110-093000.000.json
+  todo snapshot restore todo_back-20250110-093000.000.json --yes`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		if err := runSnapshotRestore(ctx, args[0], snapshotRestoreYes); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+	snapshotRestoreCmd.Flags().BoolVarP(&snapshotRestoreYes, "yes", "y", false, "Restore without asking for confirmation")
+}
+
+// snapshotManager returns the configured snapshot manager
+func snapshotManager(ctx *AppContext) (*snapshot.Manager, error) {
+	manager := repository.OpenSnapshots(ctx.Config)
+	if manager == nil {
+		return nil, errSnapshotsOff
+	}
+	return manager, nil
+}
+
+// loadSnapshot reads and decodes the tasks stored in s, decrypting them
+// with the store's cipher when encryption is enabled
+func loadSnapshot(ctx *AppContext, s snapshot.Snapshot) ([]domain.TodoItem, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	todos, err := repository.ParseTodos(data, storeCipher(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s is unreadable: %w", s.Name, err)
+	}
+	return todos, nil
+}
+
+func runSnapshotList(ctx *AppContext) error {
+	manager, err := snapshotManager(ctx)
+	if err != nil {
+		return err
+	}
+	snapshots, err := manager.List("")
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		fmt.Printf(i18n.T("cmd.snapshot.list.empty"), manager.Dir)
+		return nil
+	}
+
+	for _, s := range snapshots {
+		tasks := "?"
+		if todos, err := loadSnapshot(ctx, s); err == nil {
+			tasks = fmt.Sprintf("%d", len(todos))
+		}
+		fmt.Printf(i18n.T("cmd.snapshot.list.entry"), s.Name, s.Time.Format("2006-01-02 15:04:05"), tasks, s.Size)
+	}
+	return nil
+}
+
+func runSnapshotRestore(ctx *AppContext, name string, yes bool) error {
+	manager, err := snapshotManager(ctx)
+	if err != nil {
+		return err
+	}
+	s, err := manager.Find(name)
+	if err != nil {
+		return err
+	}
+
+	var backup bool
+	switch s.Source {
+	case filepath.Base(ctx.Config.TodoPath):
+		backup = false
+	case filepath.Base(ctx.Config.BackupPath):
+		backup = true
+	default:
+		return fmt.Errorf("snapshot %s is of %s, which is neither the active nor the backup task file", s.Name, s.Source)
+	}
+
+	restored, err := loadSnapshot(ctx, s)
+	if err != nil {
+		return err
+	}
+	current := *ctx.Todos
+	if backup {
+		if current, err = ctx.Store.Load(true); err != nil {
+			return fmt.Errorf("failed to load backup: %w", err)
+		}
+	}
+
+	list := journal.StoreActive
+	if backup {
+		list = journal.StoreBackup
+	}
+	changes := journal.Diff(list, current, restored)
+	if len(changes) == 0 {
+		output.PrintInfo("%s", i18n.T("cmd.snapshot.restore.unchanged", list, s.Name))
+		return nil
+	}
+
+	printRestoreSummary(s, list, changes)
+	if !yes {
+		fmt.Print(i18n.T("cmd.snapshot.restore.confirm"))
+		var response string
+		fmt.Scanln(&response)
+		if response := strings.ToLower(response); response != "y" && response != "yes" {
+			output.PrintInfo("%s", i18n.T("cmd.snapshot.restore.cancelled"))
+			return nil
+		}
+	}
+
+	if recorder, ok := domain.As[domain.OperationRecorder](ctx.Store); ok {
+		recorder.BeginOperation("snapshot", fmt.Sprintf("Restore %s list from snapshot %s", list, s.Name))
+	}
+	if err := ctx.Store.Save(restored, backup); err != nil {
+		return fmt.Errorf("failed to save restored todos: %w", err)
+	}
+	if !backup {
+		*ctx.Todos = restored
+	}
+
+	output.PrintSuccess("%s", i18n.T("cmd.snapshot.restore.success", list, s.Name))
+	return nil
+}
+
+// printRestoreSummary shows what restoring s would change
+func printRestoreSummary(s snapshot.Snapshot, list string, changes []journal.Change) {
+	added, removed, changed := countChanges(changes)
+	fmt.Printf(i18n.T("cmd.snapshot.restore.summary"), s.Name, s.Time.Format("2006-01-02 15:04:05"), list)
+	fmt.Printf(i18n.T("cmd.snapshot.restore.counts"), added, removed, changed)
+	printChanges(changes)
+}
+
+// countChanges counts the tasks added, removed and changed by changes
+func countChanges(changes []journal.Change) (added, removed, changed int) {
+	for _, c := range changes {
+		switch {
+		case c.Before == nil:
+			added++
+		case c.After == nil:
+			removed++
+		default:
+			changed++
+		}
+	}
+	return added, removed, changed
+}
+
+// printChanges lists changes, up to maxDiffLines of them
+func printChanges(changes []journal.Change) {
+	for i, c := range changes {
+		if i == maxDiffLines {
+			fmt.Printf(i18n.T("cmd.snapshot.restore.more"), len(changes)-maxDiffLines)
+			break
+		}
+		fmt.Printf("   - %s\n", c.Describe())
+	}
+}
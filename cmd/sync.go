@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/journal"
+	"github.com/SongRunqi/go-todo/internal/merge"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/SongRunqi/go-todo/internal/replica"
+	"github.com/SongRunqi/go-todo/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncPrefer string
+	syncDryRun bool
+	errSyncOff = errors.New(`sync is off; set "syncDir" in ~/.todo/config.json or TODO_SYNC_DIR to a folder shared between your devices`)
+)
+
+// syncCmd merges the task lists of other devices
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "",
+	Long:  "",
+	Example: `  todo sync
+  todo sync --dry-run
+  todo sync --prefer local`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := getAppContext(cmd)
+		resolve := promptConflict
+		switch syncPrefer {
+		case "":
+		case "local":
+			resolve = merge.Prefer(merge.KeepLocal)
+		case "remote":
+			resolve = merge.Prefer(merge.KeepRemote)
+		default:
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), fmt.Errorf("invalid --prefer %q (use local|remote)", syncPrefer))
+			os.Exit(1)
+		}
+		if err := runSync(ctx, resolve, syncDryRun); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().StringVar(&syncPrefer, "prefer", "", "Settle every conflict with this side: local|remote")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Only report what the merge would change")
+}
+
+func runSync(ctx *AppContext, resolve merge.Resolver, dryRun bool) error {
+	cfg := ctx.Config
+	if cfg.SyncDir == "" {
+		return errSyncOff
+	}
+	if err := validator.ValidateDeviceName(cfg.Device); err != nil {
+		return err
+	}
+	dir := filepath.Join(cfg.SyncDir, cfg.List)
+	c := storeCipher(ctx)
+
+	peers, err := replica.Peers(dir, cfg.Device)
+	if err != nil {
+		return err
+	}
+
+	localActive := *ctx.Todos
+	localBackup, err := ctx.Store.Load(true)
+	if err != nil {
+		return fmt.Errorf("failed to load backup: %w", err)
+	}
+	active, backup := localActive, localBackup
+	// Renumbered tasks get IDs from the store, which also knows the IDs of
+	// tasks that are gone; without an allocator, the next after both lists
+	newID := func() (int, error) {
+		return app.NextTaskID(ctx.Store, append(slices.Clone(active), backup...))
+	}
+
+	remotes := make(map[string]*replica.Replica, len(peers))
+	var conflicts []merge.Conflict
+	var renumbered []merge.Renumbered
+	for _, peer := range peers {
+		remote, err := replica.Read(replica.Path(dir, peer), c)
+		if err != nil {
+			return err
+		}
+		base, err := replica.Read(replica.Path(cfg.SyncStateDir, peer), c)
+		if err != nil {
+			return err
+		}
+		if base == nil {
+			base = &replica.Replica{}
+		}
+
+		mergedBackup, err := merge.Merge(journal.StoreBackup, base.Backup, backup, remote.Backup, resolve, newID)
+		if err != nil {
+			return fmt.Errorf("failed to merge %s: %w", peer, err)
+		}
+		backup = mergedBackup.Tasks
+		mergedActive, err := merge.Merge(journal.StoreActive, base.Active, active, remote.Active, resolve, newID)
+		if err != nil {
+			return fmt.Errorf("failed to merge %s: %w", peer, err)
+		}
+		active = mergedActive.Tasks
+		conflicts = append(append(conflicts, mergedBackup.Conflicts...), mergedActive.Conflicts...)
+		renumbered = append(append(renumbered, mergedBackup.Renumbered...), mergedActive.Renumbered...)
+		remotes[peer] = remote
+	}
+
+	backupChanges := journal.Diff(journal.StoreBackup, localBackup, backup)
+	activeChanges := journal.Diff(journal.StoreActive, localActive, active)
+	changes := append(backupChanges, activeChanges...)
+	printSyncSummary(peers, changes, conflicts, renumbered)
+	if dryRun {
+		return nil
+	}
+
+	if len(changes) > 0 {
+		if recorder, ok := domain.As[domain.OperationRecorder](ctx.Store); ok {
+			recorder.BeginOperation("sync", fmt.Sprintf("Sync with %s", strings.Join(peers, ", ")))
+		}
+		if len(backupChanges) > 0 {
+			if err := ctx.Store.Save(backup, true); err != nil {
+				return fmt.Errorf("failed to save backup todos: %w", err)
+			}
+		}
+		if len(activeChanges) > 0 {
+			if err := ctx.Store.Save(active, false); err != nil {
+				return fmt.Errorf("failed to save active todos: %w", err)
+			}
+			*ctx.Todos = active
+		}
+	}
+
+	// Publish our state, then remember what we merged from each peer as the
+	// ancestor for the next sync with it
+	own := replica.Replica{Device: cfg.Device, Time: time.Now(), Active: active, Backup: backup}
+	if err := replica.Write(replica.Path(dir, cfg.Device), own, c); err != nil {
+		return err
+	}
+	for peer, remote := range remotes {
+		if err := replica.Write(replica.Path(cfg.SyncStateDir, peer), *remote, c); err != nil {
+			return err
+		}
+	}
+
+	output.PrintSuccess("%s", i18n.T("cmd.sync.published", cfg.List, replica.Path(dir, cfg.Device)))
+	return nil
+}
+
+// printSyncSummary reports what merging the peers changed locally
+func printSyncSummary(peers []string, changes []journal.Change, conflicts []merge.Conflict, renumbered []merge.Renumbered) {
+	if len(peers) == 0 {
+		output.PrintInfo("%s", i18n.T("cmd.sync.no_peers"))
+		return
+	}
+	added, removed, changed := countChanges(changes)
+	fmt.Printf(i18n.T("cmd.sync.merged"),
+		strings.Join(peers, ", "), added, removed, changed, len(conflicts))
+	printChanges(changes)
+	for _, r := range renumbered {
+		fmt.Printf(i18n.T("cmd.sync.renumbered"), r.TaskName, r.From, r.To, r.List)
+	}
+}
+
+// promptConflict asks on the terminal which side of a conflict to keep
+func promptConflict(c merge.Conflict) (merge.Choice, error) {
+	fmt.Printf(i18n.T("cmd.sync.conflict"), c)
+	if c.Field != "" {
+		fmt.Printf(i18n.T("cmd.sync.conflict.base"), orNone(c.Base))
+	}
+	fmt.Printf(i18n.T("cmd.sync.conflict.local"), orNone(c.Local))
+	fmt.Printf(i18n.T("cmd.sync.conflict.remote"), orNone(c.Remote))
+
+	for {
+		fmt.Print(i18n.T("cmd.sync.conflict.prompt"))
+		var response string
+		if _, err := fmt.Scanln(&response); err != nil && response == "" {
+			return merge.KeepLocal, fmt.Errorf("%w: %s (rerun with --prefer local|remote)", merge.ErrUnresolved, c)
+		}
+		switch strings.ToLower(response) {
+		case "l", "local":
+			return merge.KeepLocal, nil
+		case "r", "remote":
+			return merge.KeepRemote, nil
+		}
+	}
+}
+
+// orNone renders a conflicting value, or "(deleted)" for a missing one
+func orNone(value []byte) string {
+	if value == nil {
+		return "(deleted)"
+	}
+	return string(value)
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/merge"
+	"github.com/SongRunqi/go-todo/internal/replica"
+)
+
+func newSyncContext(t *testing.T, syncDir, device string, todos []app.TodoItem) *AppContext {
+	t.Helper()
+	ctx, _ := newTestContext(t, todos)
+	ctx.Config.List = "default"
+	ctx.Config.SyncDir = syncDir
+	ctx.Config.Device = device
+	ctx.Config.SyncStateDir = t.TempDir()
+	return ctx
+}
+
+func TestRunSync_MergesDevices(t *testing.T) {
+	syncDir := t.TempDir()
+	shared := []app.TodoItem{{TaskID: 1, TaskName: "Report", Status: "pending"}}
+	laptop := newSyncContext(t, syncDir, "laptop", shared)
+	phone := newSyncContext(t, syncDir, "phone", shared)
+
+	for _, ctx := range []*AppContext{laptop, phone, laptop} {
+		if err := runSync(ctx, nil, false); err != nil {
+			t.Fatalf("runSync failed: %v", err)
+		}
+	}
+
+	// Each device changes something different
+	(*laptop.Todos)[0].Status = "completed"
+	if err := laptop.Store.Save(*laptop.Todos, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	*phone.Todos = append(*phone.Todos, app.TodoItem{TaskID: 2, TaskName: "Call mom", Status: "pending"})
+	if err := phone.Store.Save(*phone.Todos, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	for _, ctx := range []*AppContext{laptop, phone, laptop} {
+		if err := runSync(ctx, nil, false); err != nil {
+			t.Fatalf("runSync failed: %v", err)
+		}
+	}
+
+	for name, ctx := range map[string]*AppContext{"laptop": laptop, "phone": phone} {
+		todos, _ := ctx.Store.Load(false)
+		if len(todos) != 2 || todos[0].Status != "completed" || todos[1].TaskName != "Call mom" {
+			t.Errorf("Expected %s to have both changes, got %+v", name, todos)
+		}
+	}
+
+	r, err := replica.Read(replica.Path(filepath.Join(syncDir, "default"), "phone"), nil)
+	if err != nil || r == nil || len(r.Active) != 2 {
+		t.Errorf("Expected the phone replica to be published, got %+v, %v", r, err)
+	}
+}
+
+func TestRunSync_RenumbersThroughTheStore(t *testing.T) {
+	syncDir := t.TempDir()
+	shared := []app.TodoItem{{TaskID: 1, TaskName: "Report", Status: "pending"}}
+	laptop := newSyncContext(t, syncDir, "laptop", shared)
+	phone := newSyncContext(t, syncDir, "phone", shared)
+	for _, ctx := range []*AppContext{laptop, phone, laptop} {
+		if err := runSync(ctx, nil, false); err != nil {
+			t.Fatalf("runSync failed: %v", err)
+		}
+	}
+
+	// The laptop handed out #3 to a task purged since; both create a #2
+	for _, want := range []int{2, 3} {
+		if id, _ := app.NextTaskID(laptop.Store, *laptop.Todos); id != want {
+			t.Fatalf("Expected ID %d, got %d", want, id)
+		}
+	}
+	*laptop.Todos = append(*laptop.Todos, app.TodoItem{TaskID: 2, TaskName: "Buy milk", Status: "pending"})
+	_ = laptop.Store.Save(*laptop.Todos, false)
+	*phone.Todos = append(*phone.Todos, app.TodoItem{TaskID: 2, TaskName: "Call mom", Status: "pending"})
+	_ = phone.Store.Save(*phone.Todos, false)
+
+	for _, ctx := range []*AppContext{phone, laptop} {
+		if err := runSync(ctx, nil, false); err != nil {
+			t.Fatalf("runSync failed: %v", err)
+		}
+	}
+	todos, _ := laptop.Store.Load(false)
+	if len(todos) != 3 || todos[2].TaskName != "Call mom" || todos[2].TaskID != 4 {
+		t.Errorf("Expected the phone's task to become #4, got %+v", todos)
+	}
+}
+
+func TestRunSync_RenumberedTaskFollowsItsUUID(t *testing.T) {
+	syncDir := t.TempDir()
+	laptop := newSyncContext(t, syncDir, "laptop", []app.TodoItem{{TaskID: 5, UUID: "uf", TaskName: "foo", Status: "pending"}})
+	phone := newSyncContext(t, syncDir, "phone", []app.TodoItem{{TaskID: 5, UUID: "ub", TaskName: "bar", Status: "pending"}})
+
+	// Each device renumbers the other's task
+	for _, ctx := range []*AppContext{laptop, phone, laptop} {
+		if err := runSync(ctx, nil, false); err != nil {
+			t.Fatalf("runSync failed: %v", err)
+		}
+	}
+	phoneTodos, _ := phone.Store.Load(false)
+	if len(phoneTodos) != 2 || phoneTodos[1].UUID != "uf" || phoneTodos[1].TaskID != 6 {
+		t.Fatalf("Expected foo to be #6 on the phone, got %+v", phoneTodos)
+	}
+
+	// An edit to foo on the laptop reaches foo on the phone, not bar
+	(*laptop.Todos)[0].TaskDesc = "edited"
+	_ = laptop.Store.Save(*laptop.Todos, false)
+	for _, ctx := range []*AppContext{laptop, phone} {
+		if err := runSync(ctx, nil, false); err != nil {
+			t.Fatalf("runSync failed: %v", err)
+		}
+	}
+	phoneTodos, _ = phone.Store.Load(false)
+	if len(phoneTodos) != 2 || phoneTodos[0].TaskName != "bar" || phoneTodos[0].TaskDesc != "" ||
+		phoneTodos[1].TaskName != "foo" || phoneTodos[1].TaskDesc != "edited" || phoneTodos[1].TaskID != 6 {
+		t.Errorf("Expected the edit on foo (#6) only, got %+v", phoneTodos)
+	}
+}
+
+func TestRunSync_Conflict(t *testing.T) {
+	syncDir := t.TempDir()
+	shared := []app.TodoItem{{TaskID: 1, TaskName: "Report", TaskDesc: "draft", Status: "pending"}}
+	laptop := newSyncContext(t, syncDir, "laptop", shared)
+	phone := newSyncContext(t, syncDir, "phone", shared)
+	for _, ctx := range []*AppContext{laptop, phone, laptop} {
+		if err := runSync(ctx, nil, false); err != nil {
+			t.Fatalf("runSync failed: %v", err)
+		}
+	}
+
+	(*laptop.Todos)[0].TaskDesc = "laptop"
+	_ = laptop.Store.Save(*laptop.Todos, false)
+	(*phone.Todos)[0].TaskDesc = "phone"
+	_ = phone.Store.Save(*phone.Todos, false)
+	if err := runSync(phone, nil, false); err != nil {
+		t.Fatalf("runSync failed: %v", err)
+	}
+
+	if err := runSync(laptop, nil, false); err == nil {
+		t.Fatal("Expected an unresolved conflict to abort the sync")
+	}
+	if err := runSync(laptop, merge.Prefer(merge.KeepRemote), false); err != nil {
+		t.Fatalf("runSync failed: %v", err)
+	}
+	if todos, _ := laptop.Store.Load(false); todos[0].TaskDesc != "phone" {
+		t.Errorf("Expected the remote description, got %q", todos[0].TaskDesc)
+	}
+}
+
+func TestRunSync_Off(t *testing.T) {
+	ctx, _ := newTestContext(t, nil)
+	if err := runSync(ctx, nil, false); err != errSyncOff {
+		t.Errorf("Expected errSyncOff, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/journal"
+	"github.com/SongRunqi/go-todo/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// undoCmd reverts the most recent task mutations
+var undoCmd = &cobra.Command{
+	Use:   "undo [n]",
+	Short: "",
+	Long:  "",
+	Example: `  todo undo
+  todo undo 3`,
+	Args: cobra.MaximumNArgs(1),
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Skip the automatic upkeep, which would change the lists between
+		// the operation being reverted and its reversal
+		setupApp(cmd, true)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		runUndoCommand(cmd, args, false)
+	},
+}
+
+// redoCmd re-applies operations reverted by undo
+var redoCmd = &cobra.Command{
+	Use:   "redo [n]",
+	Short: "",
+	Long:  "",
+	Example: `  todo redo
+  todo redo 2`,
+	Args: cobra.MaximumNArgs(1),
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Skip the automatic upkeep, which would change the lists between
+		// the operation being reverted and its reversal
+		setupApp(cmd, true)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		runUndoCommand(cmd, args, true)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(redoCmd)
+}
+
+func runUndoCommand(cmd *cobra.Command, args []string, redo bool) {
+	ctx := getAppContext(cmd)
+	steps := 1
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), fmt.Errorf("invalid count %q (must be a positive number)", args[0]))
+			os.Exit(1)
+		}
+		steps = n
+	}
+	if err := runUndo(ctx, steps, redo); err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
+		os.Exit(1)
+	}
+}
+
+// runUndo reverts (or, with redo, re-applies) up to steps operations. Each
+// step is saved and journaled on its own, so a later failure keeps the
+// steps already taken.
+func runUndo(ctx *AppContext, steps int, redo bool) error {
+	op, verb, done, messages := journal.OpUndo, "undo", "Undid", "cmd.undo"
+	if redo {
+		op, verb, done, messages = journal.OpRedo, "redo", "Redid", "cmd.redo"
+	}
+
+	store, err := journalStore(ctx)
+	if err != nil {
+		return err
+	}
+	events, err := readJournal(store.Journal())
+	if err != nil {
+		return err
+	}
+	history := journal.NewHistory(events)
+
+	backup, err := ctx.Store.Load(true)
+	if err != nil {
+		return fmt.Errorf("failed to load backup: %w", err)
+	}
+	state := journal.State{Active: *ctx.Todos, Backup: backup}
+
+	for i := 0; i < steps; i++ {
+		batch, ok := history.NextUndo()
+		changes := batch.Inverse()
+		if redo {
+			batch, ok = history.NextRedo()
+			changes = batch.Changes()
+		}
+		if !ok {
+			if i == 0 {
+				return fmt.Errorf("nothing to %s", verb)
+			}
+			output.PrintInfo("%s", i18n.T(messages+".nothing_more"))
+			break
+		}
+
+		desc := batch.Desc
+		if desc == "" {
+			desc = batch.Op
+		}
+		if err := state.ApplyStrict(changes); err != nil {
+			return fmt.Errorf("cannot %s #%d (%s): %w", verb, batch.ID, desc, err)
+		}
+
+		store.BeginReversal(op, fmt.Sprintf("%s %s", done, desc), batch.ID)
+		if err := saveTouchedLists(ctx, state, changes); err != nil {
+			return err
+		}
+		if redo {
+			history.MarkRedone(batch)
+		} else {
+			history.MarkUndone(batch)
+		}
+
+		output.PrintSuccess("%s", i18n.T(messages+".success", batch.ID, desc))
+		for _, c := range changes {
+			fmt.Printf("   - %s\n", c.Describe())
+		}
+	}
+	return nil
+}
+
+// saveTouchedLists saves the lists of state that changes modify, backup
+// first, and updates ctx.Todos with the new active list
+func saveTouchedLists(ctx *AppContext, state journal.State, changes []journal.Change) error {
+	touched := make(map[string]bool)
+	for _, c := range changes {
+		touched[c.Store] = true
+	}
+	if touched[journal.StoreBackup] {
+		if err := ctx.Store.Save(state.Backup, true); err != nil {
+			return fmt.Errorf("failed to save backup todos: %w", err)
+		}
+	}
+	if touched[journal.StoreActive] {
+		if err := ctx.Store.Save(state.Active, false); err != nil {
+			return fmt.Errorf("failed to save active todos: %w", err)
+		}
+		*ctx.Todos = state.Active
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/journal"
+)
+
+func TestRunUndo_RevertsAndRedoesDelete(t *testing.T) {
+	ctx, mem := newTestContext(t, []app.TodoItem{
+		{TaskID: 1, TaskName: "Keep", Status: "pending"},
+		{TaskID: 2, TaskName: "Oops", Status: "pending"},
+	})
+	store := journal.NewStore(mem, journal.New(filepath.Join(t.TempDir(), "journal.jsonl")))
+	ctx.Store = store
+	todos, err := store.Load(false)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	ctx.Todos = &todos
+
+	if err := runDelete(ctx, 2, "active"); err != nil {
+		t.Fatalf("runDelete failed: %v", err)
+	}
+
+	if err := runUndo(ctx, 1, false); err != nil {
+		t.Fatalf("undo failed: %v", err)
+	}
+	active, _ := mem.Load(false)
+	if len(active) != 2 || active[1].TaskID != 2 || active[1].Status != "pending" {
+		t.Errorf("Expected task 2 back in the active list, got %+v", active)
+	}
+	if n := mem.Size(true); n != 0 {
+		t.Errorf("Expected the backup entry to be removed, got %d tasks", n)
+	}
+	if len(*ctx.Todos) != 2 {
+		t.Errorf("Expected ctx.Todos to be updated, got %+v", *ctx.Todos)
+	}
+
+	if err := runUndo(ctx, 1, false); err == nil {
+		t.Error("Expected nothing left to undo")
+	}
+
+	if err := runUndo(ctx, 1, true); err != nil {
+		t.Fatalf("redo failed: %v", err)
+	}
+	active, _ = mem.Load(false)
+	if len(active) != 1 || active[0].TaskID != 1 {
+		t.Errorf("Expected task 2 to be deleted again, got %+v", active)
+	}
+}
@@ -19,7 +19,10 @@ var updateCmd = &cobra.Command{
 		ctx := getAppContext(cmd)
 		content := args[0]
 
-		if err := app.UpdateTask(ctx.Todos, content, ctx.Store); err != nil {
+		err := retryOnConflict(ctx, func() error {
+			return app.UpdateTask(ctx.Todos, content, ctx.Store)
+		})
+		if err != nil {
 			fmt.Fprintf(os.Stderr, i18n.T("cmd.root.error.general"), err)
 			os.Exit(1)
 		}
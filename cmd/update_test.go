@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/app"
+)
+
+func TestUpdateTask_KeepsRecurrence(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+	stretch := dailyStretch(now)
+	stretch.PausedUntil = now.AddDate(0, 0, 7)
+	stretch.Status = "paused"
+	ctx, store := newTestContext(t, []app.TodoItem{stretch})
+
+	update := `{"taskId": 1, "taskName": "Stretch", "taskDesc": "Ten minutes", "status": "paused"}`
+	if err := app.UpdateTask(ctx.Todos, update, ctx.Store); err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+	active, _ := store.Load(false)
+	got := active[0]
+	if got.TaskDesc != "Ten minutes" || got.Status != "paused" {
+		t.Errorf("Expected the update to apply, got %+v", got)
+	}
+	if !got.IsRecurring || got.RecurrenceRule != stretch.RecurrenceRule || !got.RecurrenceStart.Equal(stretch.RecurrenceStart) ||
+		!got.PausedUntil.Equal(stretch.PausedUntil) || len(got.OccurrenceHistory) != 1 {
+		t.Errorf("Expected the recurrence to be kept, got %+v", got)
+	}
+
+	// Statuses of single tasks do not apply to a series
+	if err := app.UpdateTask(ctx.Todos, `{"taskId": 1, "taskName": "Stretch", "status": "in_progress"}`, ctx.Store); err == nil {
+		t.Error("Expected an error setting a recurring task in progress")
+	}
+}
@@ -59,19 +59,19 @@ func runUpgrade() {
 	spinner.Stop()
 
 	if err != nil {
-		output.PrintError(i18n.T("upgrade.check_failed", err.Error()))
+		output.PrintError("%s", i18n.T("upgrade.check_failed", err.Error()))
 		os.Exit(1)
 	}
 
 	currentVersion := version.GetInfo().Short()
 
 	if !hasUpdate {
-		output.PrintSuccess(i18n.T("upgrade.up_to_date", currentVersion))
+		output.PrintSuccess("%s", i18n.T("upgrade.up_to_date", currentVersion))
 		return
 	}
 
 	latestVersion := release.TagName
-	output.PrintInfo(i18n.T("upgrade.new_version_available", currentVersion, latestVersion))
+	output.PrintInfo("%s", i18n.T("upgrade.new_version_available", currentVersion, latestVersion))
 
 	if checkOnly {
 		fmt.Println()
@@ -91,7 +91,7 @@ func runUpgrade() {
 	fmt.Scanln(&response)
 
 	if response != "y" && response != "Y" && response != "yes" && response != "Yes" {
-		output.PrintInfo(i18n.T("upgrade.cancelled"))
+		output.PrintInfo("%s", i18n.T("upgrade.cancelled"))
 		return
 	}
 
@@ -103,10 +103,10 @@ func runUpgrade() {
 	spinner.Stop()
 
 	if err != nil {
-		output.PrintError(i18n.T("upgrade.failed", err.Error()))
+		output.PrintError("%s", i18n.T("upgrade.failed", err.Error()))
 		os.Exit(1)
 	}
 
-	output.PrintSuccess(i18n.T("upgrade.success", latestVersion))
-	output.PrintInfo(i18n.T("upgrade.restart_required"))
+	output.PrintSuccess("%s", i18n.T("upgrade.success", latestVersion))
+	output.PrintInfo("%s", i18n.T("upgrade.restart_required"))
 }
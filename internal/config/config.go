@@ -4,6 +4,14 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Storage backends accepted in Config.Storage
+const (
+	StorageFile   = "file"
+	StorageSQLite = "sqlite"
 )
 
 // Config holds application configuration
@@ -15,14 +23,88 @@ type Config struct {
 	LLMBaseURL  string
 	Language    string
 	AIProvider  string // AI provider: deepseek, openai, anthropic
+	Storage     string // Storage backend: file, sqlite
+	DBPath      string // SQLite database path (used when Storage is sqlite)
+	JournalPath string // Operation journal; empty disables journaling
+
+	SnapshotDir  string // Directory for rotating snapshots of the task files
+	SnapshotKeep int    // Number of most recent snapshots to keep
+	SnapshotDays int    // Keep one snapshot per day for this many days
+
+	Encrypt           bool   // Encrypt the task files, journal and snapshots at rest
+	PassphraseCommand string // Command printing the encryption passphrase (TODO_PASSPHRASE wins)
+
+	List     string // Selected task list; DefaultList is the one stored in TodoPath
+	ListsDir string // Directory holding one subdirectory per named list
+
+	GitHistory bool   // Commit the task files to a git repository after every save
+	GitDir     string // Working tree of that repository, shared by all lists
+
+	SyncDir      string // Shared folder holding every device's replica; empty disables sync
+	Device       string // Name of this device's replica
+	SyncStateDir string // Local directory holding the common ancestor for each peer
+
+	ArchiveAfterDays int // Move completed tasks this many days old to the backup list; 0 disables
+
+	KeepDeletedDays   int // "todo back purge" removes deleted tasks older than this; 0 keeps them
+	KeepCompletedDays int // "todo back purge" removes completed tasks older than this; 0 keeps them
 }
 
+// DefaultList names the task list kept directly in TodoPath and BackupPath
+const DefaultList = "default"
+
+// Default snapshot retention
+const (
+	DefaultSnapshotKeep = 20
+	DefaultSnapshotDays = 30
+)
+
 var (
 	cfg Config
 )
 
-// Load loads configuration from environment variables with fallback defaults
+// Load loads configuration from environment variables with fallback
+// defaults, for the default task list configured by "defaultList" in
+// config.json or TODO_LIST
 func Load() Config {
+	return LoadList("")
+}
+
+// LoadList loads the configuration for the named task list. An empty name
+// selects the configured default list.
+func LoadList(name string) Config {
+	base := loadBase()
+	if name == "" {
+		name = base.List
+	}
+	return base.forList(name)
+}
+
+// forList points the per-list paths of the base configuration at the
+// directory of the named list
+func (c Config) forList(name string) Config {
+	c.List = name
+	if name == DefaultList {
+		return c
+	}
+
+	dir := filepath.Join(c.ListsDir, name)
+	c.TodoPath = filepath.Join(dir, "todo.json")
+	c.BackupPath = filepath.Join(dir, "todo_back.json")
+	c.DBPath = filepath.Join(dir, "todo.db")
+	if c.JournalPath != "" {
+		c.JournalPath = filepath.Join(dir, "journal.jsonl")
+	}
+	if c.SnapshotDir != "" {
+		c.SnapshotDir = filepath.Join(dir, "snapshots")
+	}
+	c.SyncStateDir = filepath.Join(dir, "sync")
+	return c
+}
+
+// loadBase loads the configuration shared by all lists, with the paths of
+// the default list
+func loadBase() Config {
 	// Load from config file if it exists
 	if cfg != (Config{}) {
 		return cfg
@@ -36,6 +118,7 @@ func Load() Config {
 	// Default paths in user's home directory
 	defaultTodoPath := filepath.Join(homeDir, ".todo", "todo.json")
 	defaultBackupPath := filepath.Join(homeDir, ".todo", "todo_back.json")
+	defaultDBPath := filepath.Join(homeDir, ".todo", "todo.db")
 
 	// Load from environment variables or use defaults
 	todoPath := getEnvOrDefault("TODO_PATH", defaultTodoPath)
@@ -49,26 +132,114 @@ func Load() Config {
 		backupPath = "todo_back.json"
 	}
 
+	// The journal lives next to the task file
+	journalPath := getEnvOrDefault("TODO_JOURNAL_PATH", filepath.Join(filepath.Dir(todoPath), "journal.jsonl"))
+
+	// Snapshots also live next to the task file
+	snapshotDir := filepath.Join(filepath.Dir(todoPath), "snapshots")
+	snapshotKeep := DefaultSnapshotKeep
+	snapshotDays := DefaultSnapshotDays
+	encrypt := false
+	passphraseCommand := ""
+	defaultList := DefaultList
+	gitHistory := false
+	syncDir := ""
+	device := defaultDevice()
+	archiveAfterDays := 0
+	keepDeletedDays := 0
+	keepCompletedDays := 0
+
 	// Load AI/LLM configuration
 	aiProvider := getEnvOrDefault("AI_PROVIDER", "deepseek")
 	apiKey := os.Getenv("API_KEY")
-	model := getEnvOrDefault("LLM_MODEL", "") // Empty means use provider default
+	model := getEnvOrDefault("LLM_MODEL", "")         // Empty means use provider default
 	llmBaseURL := getEnvOrDefault("LLM_BASE_URL", "") // Empty means use provider default
 
 	// Load language configuration
 	// Priority: 1. Config file 2. Auto-detect
 	language := ""
+	storage := StorageFile
+	dbPath := defaultDBPath
 	if fileConfig := loadConfigFile(homeDir); fileConfig != nil {
 		language = fileConfig.Language
+		if fileConfig.Storage != "" {
+			storage = fileConfig.Storage
+		}
+		if fileConfig.DBPath != "" {
+			dbPath = fileConfig.DBPath
+		}
+		if fileConfig.SnapshotDir != "" {
+			snapshotDir = fileConfig.SnapshotDir
+		}
+		if fileConfig.SnapshotKeep != nil {
+			snapshotKeep = *fileConfig.SnapshotKeep
+		}
+		if fileConfig.SnapshotDays != nil {
+			snapshotDays = *fileConfig.SnapshotDays
+		}
+		encrypt = fileConfig.Encrypt
+		passphraseCommand = fileConfig.PassphraseCommand
+		gitHistory = fileConfig.GitHistory
+		syncDir = fileConfig.SyncDir
+		if fileConfig.Device != "" {
+			device = fileConfig.Device
+		}
+		if fileConfig.DefaultList != "" {
+			defaultList = fileConfig.DefaultList
+		}
+		archiveAfterDays = fileConfig.ArchiveAfterDays
+		keepDeletedDays = fileConfig.KeepDeletedDays
+		keepCompletedDays = fileConfig.KeepCompletedDays
 	}
+
+	// Storage configuration: environment overrides the config file
+	storage = getEnvOrDefault("TODO_STORAGE", storage)
+	dbPath = getEnvOrDefault("TODO_DB_PATH", dbPath)
+	snapshotDir = getEnvOrDefault("TODO_SNAPSHOT_DIR", snapshotDir)
+	snapshotKeep = getEnvIntOrDefault("TODO_SNAPSHOT_KEEP", snapshotKeep)
+	snapshotDays = getEnvIntOrDefault("TODO_SNAPSHOT_DAYS", snapshotDays)
+	encrypt = getEnvBoolOrDefault("TODO_ENCRYPT", encrypt)
+	passphraseCommand = getEnvOrDefault("TODO_PASSPHRASE_CMD", passphraseCommand)
+	defaultList = getEnvOrDefault("TODO_LIST", defaultList)
+	gitHistory = getEnvBoolOrDefault("TODO_GIT_HISTORY", gitHistory)
+	syncDir = getEnvOrDefault("TODO_SYNC_DIR", syncDir)
+	device = getEnvOrDefault("TODO_DEVICE", device)
+	archiveAfterDays = getEnvIntOrDefault("TODO_ARCHIVE_AFTER_DAYS", archiveAfterDays)
+	keepDeletedDays = getEnvIntOrDefault("TODO_KEEP_DELETED_DAYS", keepDeletedDays)
+	keepCompletedDays = getEnvIntOrDefault("TODO_KEEP_COMPLETED_DAYS", keepCompletedDays)
 	cfg = Config{
-		TodoPath:   todoPath,
-		BackupPath: backupPath,
-		APIKey:     apiKey,
-		Model:      model,
-		LLMBaseURL: llmBaseURL,
-		Language:   language,
-		AIProvider: aiProvider,
+		TodoPath:    todoPath,
+		BackupPath:  backupPath,
+		APIKey:      apiKey,
+		Model:       model,
+		LLMBaseURL:  llmBaseURL,
+		Language:    language,
+		AIProvider:  aiProvider,
+		Storage:     storage,
+		DBPath:      dbPath,
+		JournalPath: journalPath,
+
+		SnapshotDir:  snapshotDir,
+		SnapshotKeep: snapshotKeep,
+		SnapshotDays: snapshotDays,
+
+		Encrypt:           encrypt,
+		PassphraseCommand: passphraseCommand,
+
+		List:     defaultList,
+		ListsDir: filepath.Join(filepath.Dir(todoPath), "lists"),
+
+		GitHistory: gitHistory,
+		GitDir:     filepath.Dir(todoPath),
+
+		SyncDir:      syncDir,
+		Device:       device,
+		SyncStateDir: filepath.Join(filepath.Dir(todoPath), "sync"),
+
+		ArchiveAfterDays: archiveAfterDays,
+
+		KeepDeletedDays:   keepDeletedDays,
+		KeepCompletedDays: keepCompletedDays,
 	}
 	return cfg
 }
@@ -95,6 +266,47 @@ func loadConfigFile(homeDir string) *fileConfig {
 // fileConfig represents the structure of the config.json file
 type fileConfig struct {
 	Language string `json:"language"`
+	Storage  string `json:"storage,omitempty"`
+	DBPath   string `json:"dbPath,omitempty"`
+
+	// Pointers distinguish an explicit 0 (disable) from an absent key
+	SnapshotDir  string `json:"snapshotDir,omitempty"`
+	SnapshotKeep *int   `json:"snapshotKeep,omitempty"`
+	SnapshotDays *int   `json:"snapshotDays,omitempty"`
+
+	Encrypt           bool   `json:"encrypt,omitempty"`
+	PassphraseCommand string `json:"passphraseCommand,omitempty"`
+
+	DefaultList string `json:"defaultList,omitempty"`
+	GitHistory  bool   `json:"gitHistory,omitempty"`
+	SyncDir     string `json:"syncDir,omitempty"`
+	Device      string `json:"device,omitempty"`
+
+	ArchiveAfterDays  int `json:"archiveAfterDays,omitempty"`
+	KeepDeletedDays   int `json:"keepDeletedDays,omitempty"`
+	KeepCompletedDays int `json:"keepCompletedDays,omitempty"`
+}
+
+// defaultDevice derives a device name from the host name, keeping only the
+// characters allowed in file names
+func defaultDevice() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return "device"
+	}
+	host, _, _ = strings.Cut(host, ".")
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '-'
+	}, host)
+	name = strings.Trim(name, "-")
+	if name == "" || len(name) > 64 {
+		return "device"
+	}
+	return name
 }
 
 // getEnvOrDefault returns the value of an environment variable or a default value
@@ -104,3 +316,25 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvIntOrDefault returns the integer value of an environment variable, or
+// defaultValue if it is unset or not a number
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+// getEnvBoolOrDefault returns the boolean value of an environment variable
+// (1/0, true/false), or defaultValue if it is unset or not a boolean
+func getEnvBoolOrDefault(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,399 @@
+// Package doctor finds inconsistencies in task lists and repairs the ones
+// that have an unambiguous fix.
+//
+// Every check both detects and repairs its problem, so Check and Repair can
+// never disagree about what is wrong: Repair runs the same checks as Check
+// with fixing turned on.
+package doctor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/journal"
+	"github.com/SongRunqi/go-todo/internal/migration"
+	"github.com/SongRunqi/go-todo/internal/rrule"
+	"github.com/SongRunqi/go-todo/internal/validator"
+	"github.com/google/uuid"
+)
+
+// Issue is one problem found in a task list
+type Issue struct {
+	List    string // journal.StoreActive or journal.StoreBackup
+	TaskID  int    // zero for problems with the list as a whole
+	Problem string
+	Fixable bool
+}
+
+func (i Issue) String() string {
+	if i.TaskID == 0 {
+		return fmt.Sprintf("%s list: %s", i.List, i.Problem)
+	}
+	return fmt.Sprintf("%s list, task %d: %s", i.List, i.TaskID, i.Problem)
+}
+
+// dateLayout is the format of TodoItem.DueDate
+const dateLayout = "2006-01-02"
+
+// periodKey matches the due dates of compacted summary tasks (2024-W03, 2024-01)
+var periodKey = regexp.MustCompile(`^\d{4}-(W\d{2}|\d{2})$`)
+
+// Check reports the problems in the active and backup lists. now is the
+// time against which the next occurrence of recurring tasks is judged.
+func Check(active, backup []domain.TodoItem, now time.Time) []Issue {
+	d := &doctor{now: now}
+	d.examine(active, backup)
+	return d.issues
+}
+
+// Repair fixes every fixable problem in the active and backup lists in
+// place and returns the problems it fixed. nextID hands out IDs for tasks
+// whose ID is invalid or taken.
+func Repair(active, backup []domain.TodoItem, now time.Time, nextID func() (int, error)) ([]Issue, error) {
+	d := &doctor{now: now, fix: true, nextID: nextID}
+	d.examine(active, backup)
+	if d.err != nil {
+		return nil, d.err
+	}
+	var fixed []Issue
+	for _, issue := range d.issues {
+		if issue.Fixable {
+			fixed = append(fixed, issue)
+		}
+	}
+	return fixed, nil
+}
+
+type doctor struct {
+	now    time.Time
+	fix    bool
+	nextID func() (int, error)
+	err    error
+	issues []Issue
+}
+
+func (d *doctor) report(list string, task *domain.TodoItem, fixable bool, format string, args ...interface{}) {
+	d.issues = append(d.issues, Issue{List: list, TaskID: task.TaskID, Problem: fmt.Sprintf(format, args...), Fixable: fixable})
+}
+
+func (d *doctor) examine(active, backup []domain.TodoItem) {
+	lists := []struct {
+		name  string
+		todos []domain.TodoItem
+	}{
+		{journal.StoreActive, active},
+		{journal.StoreBackup, backup},
+	}
+
+	// IDs and UUIDs are checked over both lists, active tasks first, so the
+	// active task keeps its ID when it clashes with a completed one
+	ids := make(map[int]string)
+	uuids := make(map[string]bool)
+	for _, l := range lists {
+		for i := range l.todos {
+			d.checkIdentity(l.name, &l.todos[i], ids, uuids)
+		}
+	}
+	for _, l := range lists {
+		for i := range l.todos {
+			task := &l.todos[i]
+			d.checkStatus(l.name, task)
+			if task.IsRecurring {
+				d.checkRule(l.name, task)
+				d.checkOccurrences(l.name, task)
+			} else {
+				d.checkRecurringFields(l.name, task)
+			}
+			d.checkDueDate(l.name, task)
+		}
+	}
+}
+
+func (d *doctor) checkIdentity(list string, task *domain.TodoItem, ids map[int]string, uuids map[string]bool) {
+	problem := ""
+	if err := validator.ValidateTaskID(task.TaskID); err != nil {
+		problem = fmt.Sprintf("invalid task ID %d", task.TaskID)
+	} else if other, taken := ids[task.TaskID]; taken {
+		problem = fmt.Sprintf("task ID %d is also used by another task in the %s list", task.TaskID, other)
+	}
+	if problem != "" {
+		if d.fix && d.err == nil {
+			id, err := d.nextID()
+			if err != nil {
+				d.err = fmt.Errorf("failed to allocate task ID: %w", err)
+				return
+			}
+			problem += fmt.Sprintf("; renumbered to %d", id)
+			task.TaskID = id
+		}
+		d.report(list, task, true, "%s", problem)
+	}
+	ids[task.TaskID] = list
+
+	switch {
+	case task.UUID == "":
+		d.report(list, task, true, "missing UUID")
+	case uuids[task.UUID]:
+		d.report(list, task, true, "UUID %s is shared with another task", task.UUID)
+	default:
+		uuids[task.UUID] = true
+		return
+	}
+	if d.fix {
+		task.UUID = uuid.NewString()
+		uuids[task.UUID] = true
+	}
+}
+
+func (d *doctor) checkStatus(list string, task *domain.TodoItem) {
+	validate := validator.ValidateStatus
+	if task.IsRecurring {
+		validate = validator.ValidateRecurringStatus
+	}
+	if validate(task.Status) == nil {
+		return
+	}
+
+	normalized := validator.NormalizeStatus(task.Status)
+	if task.IsRecurring {
+		normalized = strings.ToLower(strings.TrimSpace(task.Status))
+	}
+	if validate(normalized) != nil {
+		d.report(list, task, false, "invalid status %q", task.Status)
+		return
+	}
+	d.report(list, task, true, "status %q should be %q", task.Status, normalized)
+	if d.fix {
+		task.Status = normalized
+	}
+}
+
+// checkRule checks the recurrence rule a recurring task follows
+func (d *doctor) checkRule(list string, task *domain.TodoItem) {
+	if task.RecurrenceRule != "" {
+		if err := validator.ValidateRecurrenceRule(task.RecurrenceRule); err != nil {
+			d.report(list, task, false, "%v", err)
+		}
+		return
+	}
+	if _, err := rrule.FromFields(task.RecurringType, task.RecurringInterval, task.RecurringWeekdays); err != nil {
+		d.report(list, task, false, "recurring task has no recurrence rule and %v", err)
+		return
+	}
+	d.report(list, task, true, "recurring task has no recurrence rule")
+	if d.fix {
+		migration.AddRecurrenceRule(task)
+	}
+}
+
+// checkOccurrences checks the occurrence history of a recurring task
+func (d *doctor) checkOccurrences(list string, task *domain.TodoItem) {
+	if len(task.CurrentPeriodCompletions) > 0 {
+		d.report(list, task, true, "still has legacy currentPeriodCompletions")
+		if d.fix {
+			migration.ConvertPeriodCompletions(task)
+		}
+	}
+	if len(task.OccurrenceHistory) == 0 {
+		if task.EndTime.IsZero() {
+			d.report(list, task, false, "recurring task has no occurrence history and no next occurrence")
+			return
+		}
+		d.report(list, task, true, "recurring task has no occurrence history")
+		if !d.fix {
+			return
+		}
+		migration.ConvertPeriodCompletions(task)
+	}
+
+	history := task.OccurrenceHistory
+	for _, occ := range history {
+		if err := validator.ValidateOccurrenceStatus(occ.Status); err != nil {
+			d.report(list, task, false, "occurrence at %s has invalid status %q", occ.ScheduledTime.Format("2006-01-02 15:04"), occ.Status)
+		}
+	}
+	if !sort.SliceIsSorted(history, func(i, j int) bool { return history[i].ScheduledTime.Before(history[j].ScheduledTime) }) {
+		d.report(list, task, true, "occurrence history is out of order")
+		if d.fix {
+			sortOccurrences(history)
+		}
+	}
+	if deduped := dedupeOccurrences(history); len(deduped) != len(history) {
+		d.report(list, task, true, "%d occurrences are scheduled at the same time as another", len(history)-len(deduped))
+		if d.fix {
+			task.OccurrenceHistory = deduped
+		}
+	}
+
+	if task.Status != "active" && task.Status != "paused" {
+		return
+	}
+	// EndTime is the next occurrence, which must be pending
+	var pending []domain.OccurrenceRecord
+	for _, occ := range task.OccurrenceHistory {
+		if occ.Status == "pending" {
+			if occ.ScheduledTime.Equal(task.EndTime) {
+				return
+			}
+			pending = append(pending, occ)
+		}
+	}
+	if len(pending) == 0 {
+		if task.EndTime.IsZero() || hasOccurrenceAt(task.OccurrenceHistory, task.EndTime) {
+			d.report(list, task, false, "active recurring task has no pending occurrence")
+			return
+		}
+		d.report(list, task, true, "next occurrence %s is missing from the history", task.EndTime.Format("2006-01-02 15:04"))
+		if d.fix {
+			task.OccurrenceHistory = append(task.OccurrenceHistory, domain.OccurrenceRecord{ScheduledTime: task.EndTime, Status: "pending"})
+			sortOccurrences(task.OccurrenceHistory)
+		}
+		return
+	}
+
+	next := pending[len(pending)-1]
+	for _, occ := range pending {
+		if !occ.ScheduledTime.Before(d.now) {
+			next = occ
+			break
+		}
+	}
+	d.report(list, task, true, "endTime %s is not a pending occurrence; the next one is %s",
+		task.EndTime.Format("2006-01-02 15:04"), next.ScheduledTime.Format("2006-01-02 15:04"))
+	if d.fix {
+		task.EndTime = next.ScheduledTime
+		task.DueDate = next.ScheduledTime.Format(dateLayout)
+	}
+}
+
+// checkRecurringFields reports recurring-task data left on a task that is
+// not recurring
+func (d *doctor) checkRecurringFields(list string, task *domain.TodoItem) {
+	if len(task.CurrentPeriodCompletions) > 0 {
+		d.report(list, task, true, "non-recurring task has legacy currentPeriodCompletions")
+		if d.fix {
+			task.CurrentPeriodCompletions = nil
+		}
+	}
+	if task.RecurringType != "" || task.RecurrenceRule != "" || len(task.RecurringWeekdays) > 0 || len(task.OccurrenceHistory) > 0 {
+		d.report(list, task, false, "non-recurring task has recurring fields; set isRecurring or clear them")
+	}
+}
+
+func (d *doctor) checkDueDate(list string, task *domain.TodoItem) {
+	if task.DueDate == "" || periodKey.MatchString(task.DueDate) {
+		return
+	}
+	if _, err := time.Parse(dateLayout, task.DueDate); err != nil {
+		if task.EndTime.IsZero() {
+			d.report(list, task, false, "dueDate %q is not a date", task.DueDate)
+			return
+		}
+		d.report(list, task, true, "dueDate %q is not a date; endTime is %s", task.DueDate, task.EndTime.Format(dateLayout))
+	} else if task.EndTime.IsZero() || task.DueDate == task.EndTime.Format(dateLayout) {
+		return
+	} else {
+		d.report(list, task, true, "dueDate %s does not match endTime %s", task.DueDate, task.EndTime.Format(dateLayout))
+	}
+	if d.fix {
+		task.DueDate = task.EndTime.Format(dateLayout)
+	}
+}
+
+// CheckJSON reports problems in the content of a task file that loading it
+// would hide or fail on: entries that do not decode as tasks and fields
+// todo does not know, which the next save drops.
+func CheckJSON(list string, data []byte) []Issue {
+	var entries []json.RawMessage
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return []Issue{{List: list, Problem: fmt.Sprintf("file is not valid JSON: %v", err)}}
+		}
+	} else {
+		var envelope struct {
+			Tasks []json.RawMessage `json:"tasks"`
+		}
+		if err := json.Unmarshal(trimmed, &envelope); err != nil {
+			return []Issue{{List: list, Problem: fmt.Sprintf("file is not valid JSON: %v", err)}}
+		}
+		entries = envelope.Tasks
+	}
+
+	known := knownFields()
+	var issues []Issue
+	for i, entry := range entries {
+		var task domain.TodoItem
+		if err := json.Unmarshal(entry, &task); err != nil {
+			issues = append(issues, Issue{List: list, TaskID: task.TaskID, Problem: fmt.Sprintf("entry %d cannot be read: %v", i+1, err)})
+			continue
+		}
+		var fields map[string]json.RawMessage
+		_ = json.Unmarshal(entry, &fields)
+		var unknown []string
+		for name := range fields {
+			if !known[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			issues = append(issues, Issue{List: list, TaskID: task.TaskID, Fixable: true,
+				Problem: fmt.Sprintf("unknown fields %s are dropped on the next save", strings.Join(unknown, ", "))})
+		}
+	}
+	return issues
+}
+
+// knownFields returns the JSON names of the TodoItem fields
+func knownFields() map[string]bool {
+	known := make(map[string]bool)
+	t := reflect.TypeOf(domain.TodoItem{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		known[name] = true
+	}
+	return known
+}
+
+// dedupeOccurrences drops occurrences scheduled at the same time as an
+// earlier one, keeping the one that records the most progress
+func dedupeOccurrences(history []domain.OccurrenceRecord) []domain.OccurrenceRecord {
+	rank := map[string]int{"pending": 0, "missed": 1, "skipped": 2, "completed": 3}
+	index := make(map[int64]int)
+	deduped := make([]domain.OccurrenceRecord, 0, len(history))
+	for _, occ := range history {
+		key := occ.ScheduledTime.UnixNano()
+		if i, ok := index[key]; ok {
+			if rank[occ.Status] > rank[deduped[i].Status] {
+				deduped[i] = occ
+			}
+			continue
+		}
+		index[key] = len(deduped)
+		deduped = append(deduped, occ)
+	}
+	return deduped
+}
+
+func sortOccurrences(history []domain.OccurrenceRecord) {
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].ScheduledTime.Before(history[j].ScheduledTime)
+	})
+}
+
+func hasOccurrenceAt(history []domain.OccurrenceRecord, t time.Time) bool {
+	for _, occ := range history {
+		if occ.ScheduledTime.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package doctor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+)
+
+var now = time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+func counter(start int) func() (int, error) {
+	return func() (int, error) {
+		start++
+		return start, nil
+	}
+}
+
+func hasIssue(issues []Issue, taskID int, problem string) bool {
+	for _, issue := range issues {
+		if issue.TaskID == taskID && strings.Contains(issue.Problem, problem) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheck_HealthyLists(t *testing.T) {
+	active := []domain.TodoItem{
+		{TaskID: 1, UUID: "a", TaskName: "Report", Status: "pending", DueDate: "2026-10-15", EndTime: time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC)},
+		{
+			TaskID: 2, UUID: "b", TaskName: "Stretch", Status: "active", IsRecurring: true, RecurringType: "daily",
+			RecurrenceRule: "FREQ=DAILY", EndTime: now, DueDate: "2026-10-14",
+			OccurrenceHistory: []domain.OccurrenceRecord{
+				{ScheduledTime: now.AddDate(0, 0, -1), Status: "completed"},
+				{ScheduledTime: now, Status: "pending"},
+			},
+		},
+	}
+	backup := []domain.TodoItem{{TaskID: 3, UUID: "c", TaskName: "Summary", Status: "completed", DueDate: "2026-W41"}}
+
+	if issues := Check(active, backup, now); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+}
+
+func TestRepair_IdentityAndStatus(t *testing.T) {
+	active := []domain.TodoItem{
+		{TaskID: 1, UUID: "a", TaskName: "Keep", Status: "todo"},
+		{TaskID: 0, UUID: "b", TaskName: "No ID", Status: "pending"},
+	}
+	backup := []domain.TodoItem{
+		{TaskID: 1, UUID: "a", TaskName: "Copy", Status: "completed"},
+		{TaskID: 4, TaskName: "Odd", Status: "whatever"},
+	}
+
+	issues := Check(active, backup, now)
+	for _, want := range []struct {
+		id      int
+		problem string
+	}{{1, `status "todo" should be "pending"`}, {0, "invalid task ID"}, {1, "also used"}, {1, "UUID a is shared"}, {4, "missing UUID"}, {4, `invalid status "whatever"`}} {
+		if !hasIssue(issues, want.id, want.problem) {
+			t.Errorf("Expected issue %q for task %d, got %v", want.problem, want.id, issues)
+		}
+	}
+
+	fixed, err := Repair(active, backup, now, counter(4))
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	if len(fixed) != len(issues)-1 {
+		t.Errorf("Expected every issue but the invalid status to be fixed, got %v", fixed)
+	}
+	if active[0].Status != "pending" || active[1].TaskID != 5 || backup[0].TaskID != 6 || backup[0].UUID == "a" || backup[1].UUID == "" {
+		t.Errorf("Unexpected repair: %+v %+v", active, backup)
+	}
+	if remaining := Check(active, backup, now); len(remaining) != 1 || remaining[0].Fixable {
+		t.Errorf("Expected only the invalid status to remain, got %v", remaining)
+	}
+}
+
+func TestRepair_RecurringTask(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 9, 0, 0, 0, time.UTC) }
+	active := []domain.TodoItem{
+		{
+			TaskID: 1, UUID: "a", TaskName: "Water plants", Status: "active", IsRecurring: true, RecurringType: "daily",
+			EndTime: day(13), DueDate: "2026-10-12",
+			OccurrenceHistory: []domain.OccurrenceRecord{
+				{ScheduledTime: day(15), Status: "pending"},
+				{ScheduledTime: day(12), Status: "pending"},
+				{ScheduledTime: day(12), Status: "completed"},
+			},
+		},
+		{TaskID: 2, UUID: "b", TaskName: "Run", Status: "active", IsRecurring: true, RecurringType: "daily", EndTime: day(14), DueDate: "2026-10-14"},
+		{TaskID: 3, UUID: "c", TaskName: "Plain", Status: "pending", CurrentPeriodCompletions: []string{"2026-10-01"}, DueDate: "Friday", EndTime: day(16)},
+	}
+
+	fixed, err := Repair(active, nil, now, counter(3))
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	for _, want := range []string{"out of order", "same time", "not a pending occurrence", "no occurrence history", "no recurrence rule", "legacy currentPeriodCompletions", "not a date"} {
+		if !containsProblem(fixed, want) {
+			t.Errorf("Expected a fix for %q, got %v", want, fixed)
+		}
+	}
+
+	water := active[0]
+	if len(water.OccurrenceHistory) != 2 || water.OccurrenceHistory[0].Status != "completed" || !water.EndTime.Equal(day(15)) || water.DueDate != "2026-10-15" {
+		t.Errorf("Unexpected repair of the recurring task: %+v", water)
+	}
+	if water.RecurrenceRule != "FREQ=DAILY" || !water.RecurrenceStart.Equal(day(12)) {
+		t.Errorf("Expected a daily rule from the first occurrence, got %q from %v", water.RecurrenceRule, water.RecurrenceStart)
+	}
+	if len(active[1].OccurrenceHistory) != 1 || active[1].OccurrenceHistory[0].Status != "pending" {
+		t.Errorf("Expected the next occurrence to be added, got %+v", active[1].OccurrenceHistory)
+	}
+	if active[2].CurrentPeriodCompletions != nil || active[2].DueDate != "2026-10-16" {
+		t.Errorf("Unexpected repair of the plain task: %+v", active[2])
+	}
+	if remaining := Check(active, nil, now); len(remaining) != 0 {
+		t.Errorf("Expected no issues left, got %v", remaining)
+	}
+}
+
+func TestCheck_InvalidRecurrenceRule(t *testing.T) {
+	active := []domain.TodoItem{{
+		TaskID: 1, UUID: "a", TaskName: "Hourly", Status: "active", IsRecurring: true, RecurringType: "daily",
+		RecurrenceRule: "FREQ=HOURLY", EndTime: now, DueDate: "2026-10-14",
+		OccurrenceHistory: []domain.OccurrenceRecord{{ScheduledTime: now, Status: "pending"}},
+	}}
+	issues := Check(active, nil, now)
+	if len(issues) != 1 || issues[0].Fixable || !strings.Contains(issues[0].Problem, "HOURLY") {
+		t.Errorf("Expected one unfixable rule issue, got %v", issues)
+	}
+}
+
+func containsProblem(issues []Issue, problem string) bool {
+	for _, issue := range issues {
+		if strings.Contains(issue.Problem, problem) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheckJSON(t *testing.T) {
+	data := []byte(`{"version": 3, "tasks": [
+		{"taskId": 1, "taskName": "Fine"},
+		{"taskId": 2, "taskName": "Extra", "priority": "high"},
+		{"taskId": "three"}
+	]}`)
+
+	issues := CheckJSON("active", data)
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %v", issues)
+	}
+	if issues[0].TaskID != 2 || !issues[0].Fixable || !strings.Contains(issues[0].Problem, "priority") {
+		t.Errorf("Expected the unknown field to be reported, got %v", issues[0])
+	}
+	if issues[1].Fixable || !strings.Contains(issues[1].Problem, "entry 3") {
+		t.Errorf("Expected the unreadable entry to be reported, got %v", issues[1])
+	}
+
+	if issues := CheckJSON("active", []byte(`{"tasks": [`)); len(issues) != 1 || issues[0].Fixable {
+		t.Errorf("Expected broken JSON to be reported, got %v", issues)
+	}
+}
@@ -5,6 +5,7 @@ import "time"
 // TodoItem represents a task item in the todo list
 type TodoItem struct {
 	TaskID     int       `json:"taskId"`
+	UUID       string    `json:"uuid,omitempty"` // Stable identity that survives renumbering, for sync and external references
 	CreateTime time.Time `json:"createTime"`
 	EndTime    time.Time `json:"endTime"` // For recurring tasks: next scheduled occurrence time
 	User       string    `json:"user"`
@@ -13,17 +14,27 @@ type TodoItem struct {
 	Status     string    `json:"status"` // For recurring tasks: active, paused, completed, cancelled. For non-recurring: pending, completed
 	DueDate    string    `json:"dueDate"`
 	Urgent     string    `json:"urgent"`
+	Project    string    `json:"project,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+
+	// Lifecycle times of finished and deleted tasks
+	CompletedAt time.Time `json:"completedAt,omitzero"` // When the task was completed
+	ArchivedAt  time.Time `json:"archivedAt,omitzero"`  // When the task was moved to the backup list by the archive policy
+	DeletedAt   time.Time `json:"deletedAt,omitzero"`   // When the task was deleted
 
 	// Event duration (for tasks with specific time ranges, e.g., "2pm to 3pm")
 	EventDuration time.Duration `json:"eventDuration,omitempty"` // Duration of the event (e.g., 1 hour)
 
 	// Recurring task fields
-	IsRecurring       bool   `json:"isRecurring,omitempty"`       // Whether this is a recurring task
-	RecurringType     string `json:"recurringType,omitempty"`     // daily, weekly, monthly, yearly
-	RecurringInterval int    `json:"recurringInterval,omitempty"` // Interval (e.g., every 2 days, every 3 weeks)
-	RecurringWeekdays []int  `json:"recurringWeekdays,omitempty"` // For weekly: specific weekdays (0=Sun, 1=Mon...6=Sat). Empty means all days.
-	RecurringMaxCount int    `json:"recurringMaxCount,omitempty"` // Maximum number of times to repeat (0 = infinite)
-	CompletionCount   int    `json:"completionCount,omitempty"`   // Number of periods completed
+	IsRecurring       bool      `json:"isRecurring,omitempty"`       // Whether this is a recurring task
+	RecurringType     string    `json:"recurringType,omitempty"`     // daily, weekly, monthly, yearly
+	RecurringInterval int       `json:"recurringInterval,omitempty"` // Interval (e.g., every 2 days, every 3 weeks)
+	RecurringWeekdays []int     `json:"recurringWeekdays,omitempty"` // For weekly: specific weekdays (0=Sun, 1=Mon...6=Sat). Empty means all days.
+	RecurringMaxCount int       `json:"recurringMaxCount,omitempty"` // Maximum number of times to repeat (0 = infinite)
+	RecurrenceRule    string    `json:"recurrenceRule,omitempty"`    // RFC 5545 RRULE the occurrences follow, e.g. FREQ=MONTHLY;BYDAY=-1FR
+	RecurrenceStart   time.Time `json:"recurrenceStart,omitzero"`    // First occurrence of the rule (its DTSTART), which COUNT and INTERVAL count from
+	CompletionCount   int       `json:"completionCount,omitempty"`   // Number of periods completed
+	PausedUntil       time.Time `json:"pausedUntil,omitzero"`        // When a paused recurring task resumes by itself (zero: when resumed by hand)
 
 	// Occurrence tracking for recurring tasks
 	OccurrenceHistory []OccurrenceRecord `json:"occurrenceHistory,omitempty"` // History of all scheduled occurrences
@@ -38,6 +49,7 @@ type OccurrenceRecord struct {
 	Status        string    `json:"status"`                // pending, completed, missed, skipped
 	CompletedAt   time.Time `json:"completedAt,omitempty"` // Actual completion time (may differ from scheduled time if done late)
 	Notes         string    `json:"notes,omitempty"`       // Optional notes for this occurrence
+	OriginalTime  time.Time `json:"originalTime,omitzero"` // Where the series scheduled a rescheduled occurrence
 }
 
 // TodoStore defines the interface for todo storage operations
@@ -45,3 +57,46 @@ type TodoStore interface {
 	Load(backup bool) ([]TodoItem, error)
 	Save(todoItems []TodoItem, backup bool) error
 }
+
+// Locker is implemented by stores that can hold an exclusive lock across a
+// whole load-modify-save cycle. Callers should type-assert for it, since not
+// every store needs one (e.g. in-memory stores).
+type Locker interface {
+	Lock() error
+	Unlock() error
+}
+
+// OperationRecorder is implemented by stores that group the saves of one
+// user-visible operation (e.g. deleting a task, which writes both lists)
+type OperationRecorder interface {
+	BeginOperation(op, desc string)
+}
+
+// IDAllocator is implemented by stores that hand out task IDs. An ID is
+// unique across the active and backup lists and is never handed out twice,
+// even after the task that had it is gone.
+type IDAllocator interface {
+	NextID() (int, error)
+}
+
+// Unwrapper is implemented by stores that decorate another store
+type Unwrapper interface {
+	Unwrap() TodoStore
+}
+
+// As returns the first store in the Unwrap chain of store, starting with
+// store itself, that implements T
+func As[T any](store TodoStore) (T, bool) {
+	for store != nil {
+		if t, ok := store.(T); ok {
+			return t, true
+		}
+		u, ok := store.(Unwrapper)
+		if !ok {
+			break
+		}
+		store = u.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,258 @@
+// Package encryption seals task data at rest with AES-256-GCM using a key
+// derived from a passphrase with PBKDF2-SHA256.
+//
+// A sealed blob is laid out as
+//
+//	magic (8) | iterations (4, big endian) | salt (16) | nonce (12) | ciphertext+tag
+//
+// The header is authenticated as additional data, so tampering with any
+// part of the blob is detected on decryption.
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Magic marks encrypted data
+var Magic = []byte("TODOENC\x01")
+
+const (
+	// DefaultIterations is the PBKDF2 work factor used for new data
+	DefaultIterations = 600000
+
+	saltSize   = 16
+	nonceSize  = 12
+	keySize    = 32
+	headerSize = 8 + 4 + saltSize + nonceSize
+)
+
+var (
+	// ErrEncrypted is returned when encrypted data is read without a passphrase
+	ErrEncrypted = errors.New("data is encrypted; set TODO_PASSPHRASE or TODO_PASSPHRASE_CMD")
+	// ErrDecrypt is returned when data cannot be authenticated, usually
+	// because the passphrase is wrong
+	ErrDecrypt = errors.New("failed to decrypt: wrong passphrase or corrupted data")
+	// ErrNoPassphrase is returned when encryption is needed but no passphrase is configured
+	ErrNoPassphrase = errors.New("no passphrase configured; set TODO_PASSPHRASE or TODO_PASSPHRASE_CMD")
+)
+
+// IsEncrypted reports whether data starts with the encryption header
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, Magic)
+}
+
+// Cipher encrypts and decrypts data with keys derived from one passphrase.
+// Key derivation is deliberately slow, so derived keys are cached per salt,
+// and new data reuses the salt of the first data decrypted, so one command
+// normally derives a single key.
+type Cipher struct {
+	passphrase []byte
+	iterations int
+
+	mu        sync.Mutex
+	keys      map[string][]byte
+	writeSalt []byte
+}
+
+// New returns a Cipher for passphrase
+func New(passphrase string) *Cipher {
+	return &Cipher{
+		passphrase: []byte(passphrase),
+		iterations: DefaultIterations,
+		keys:       make(map[string][]byte),
+	}
+}
+
+// key returns the key for salt and iterations, deriving it on first use
+func (c *Cipher) key(salt []byte, iterations int) ([]byte, error) {
+	cacheKey := fmt.Sprintf("%x/%d", salt, iterations)
+	if key, ok := c.keys[cacheKey]; ok {
+		return key, nil
+	}
+	key, err := pbkdf2.Key(sha256.New, string(c.passphrase), salt, iterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	c.keys[cacheKey] = key
+	return key, nil
+}
+
+// Encrypt seals plaintext
+func (c *Cipher) Encrypt(plaintext []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.writeSalt == nil {
+		c.writeSalt = make([]byte, saltSize)
+		if _, err := rand.Read(c.writeSalt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+	}
+	key, err := c.key(c.writeSalt, c.iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, headerSize)
+	header = append(header, Magic...)
+	header = binary.BigEndian.AppendUint32(header, uint32(c.iterations))
+	header = append(header, c.writeSalt...)
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	header = append(header, nonce...)
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(header, nonce, plaintext, header), nil
+}
+
+// Decrypt opens data sealed by Encrypt
+func (c *Cipher) Decrypt(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, errors.New("data is not encrypted")
+	}
+	if len(data) < headerSize {
+		return nil, ErrDecrypt
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	iterations := int(binary.BigEndian.Uint32(data[8:12]))
+	salt := data[12 : 12+saltSize]
+	nonce := data[12+saltSize : headerSize]
+	if iterations <= 0 {
+		return nil, ErrDecrypt
+	}
+
+	key, err := c.key(salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, data[headerSize:], data[:headerSize])
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	// Keep writing with the salt already in use, so its key stays cached
+	if c.writeSalt == nil && iterations == c.iterations {
+		c.writeSalt = append([]byte(nil), salt...)
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// Open returns data unchanged if it is plaintext, or decrypted with c if it
+// is encrypted. A nil Cipher cannot read encrypted data.
+func Open(c *Cipher, data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	if c == nil {
+		return nil, ErrEncrypted
+	}
+	return c.Decrypt(data)
+}
+
+// Seal returns data encrypted with c, or unchanged if c is nil
+func Seal(c *Cipher, data []byte) ([]byte, error) {
+	if c == nil {
+		return data, nil
+	}
+	return c.Encrypt(data)
+}
+
+// ConvertFile re-encodes the file at path: its content is read with from
+// (plaintext is accepted as is) and written back sealed with to, or as
+// plaintext when to is nil. Encrypted files are made private (0600).
+// A missing file is not an error.
+func ConvertFile(path string, from, to *Cipher) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	plaintext, err := Open(from, data)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	if data, err = Seal(to, plaintext); err != nil {
+		return err
+	}
+
+	perm := os.FileMode(0644)
+	if to != nil {
+		perm = 0600
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp, perm); err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// Passphrase returns the passphrase from TODO_PASSPHRASE, or else the output
+// of command (normally from TODO_PASSPHRASE_CMD or the config file), e.g.
+// "pass show todo" or "security find-generic-password -s todo -w".
+func Passphrase(command string) (string, error) {
+	if passphrase := os.Getenv("TODO_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	if command == "" {
+		return "", ErrNoPassphrase
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("passphrase command failed: %w", err)
+	}
+	passphrase := strings.TrimRight(string(out), "\r\n")
+	if passphrase == "" {
+		return "", errors.New("passphrase command printed nothing")
+	}
+	return passphrase, nil
+}
@@ -0,0 +1,132 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestCipher returns a Cipher with a cheap work factor to keep tests fast
+func newTestCipher(passphrase string) *Cipher {
+	c := New(passphrase)
+	c.iterations = 1000
+	return c
+}
+
+func TestCipher_RoundTrip(t *testing.T) {
+	c := newTestCipher("correct horse")
+	plaintext := []byte(`{"version":2,"tasks":[]}`)
+
+	sealed, err := c.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if !IsEncrypted(sealed) || bytes.Contains(sealed, plaintext) {
+		t.Fatalf("Expected sealed data, got %q", sealed)
+	}
+
+	// A fresh Cipher with the same passphrase must derive the same key
+	opened, err := newTestCipher("correct horse").Decrypt(sealed)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("Expected %q, got %q", plaintext, opened)
+	}
+}
+
+func TestCipher_WrongPassphrase(t *testing.T) {
+	sealed, err := newTestCipher("right").Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if _, err := newTestCipher("wrong").Decrypt(sealed); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("Expected ErrDecrypt, got %v", err)
+	}
+}
+
+func TestCipher_DetectsTampering(t *testing.T) {
+	c := newTestCipher("pass")
+	sealed, err := c.Encrypt([]byte("secret task"))
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	for _, i := range []int{len(Magic) + 1, headerSize - 1, len(sealed) - 1} {
+		tampered := append([]byte(nil), sealed...)
+		tampered[i] ^= 0x01
+		if _, err := c.Decrypt(tampered); !errors.Is(err, ErrDecrypt) {
+			t.Errorf("Flipping byte %d: expected ErrDecrypt, got %v", i, err)
+		}
+	}
+	if _, err := c.Decrypt(sealed[:headerSize-1]); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Truncated data: expected ErrDecrypt, got %v", err)
+	}
+}
+
+func TestOpenAndSeal(t *testing.T) {
+	plain := []byte("[]")
+	if got, err := Open(nil, plain); err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("Open should pass plaintext through, got %q, %v", got, err)
+	}
+	if got, err := Seal(nil, plain); err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("Seal without a cipher should be a no-op, got %q, %v", got, err)
+	}
+
+	sealed, err := Seal(newTestCipher("pass"), plain)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if _, err := Open(nil, sealed); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("Expected ErrEncrypted without a cipher, got %v", err)
+	}
+}
+
+func TestConvertFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.json")
+	if err := os.WriteFile(path, []byte("[]"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	c := newTestCipher("pass")
+
+	if err := ConvertFile(path, c, c); err != nil {
+		t.Fatalf("Encrypting failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); !IsEncrypted(data) {
+		t.Errorf("Expected an encrypted file, got %q", data)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected mode 0600, got %o", perm)
+	}
+
+	if err := ConvertFile(path, c, nil); err != nil {
+		t.Fatalf("Decrypting failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "[]" {
+		t.Errorf("Expected the original content back, got %q", data)
+	}
+	if err := ConvertFile(filepath.Join(t.TempDir(), "missing"), c, nil); err != nil {
+		t.Errorf("A missing file should be ignored, got %v", err)
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	t.Setenv("TODO_PASSPHRASE", "")
+	if _, err := Passphrase(""); !errors.Is(err, ErrNoPassphrase) {
+		t.Errorf("Expected ErrNoPassphrase, got %v", err)
+	}
+	if got, err := Passphrase("echo from-command"); err != nil || got != "from-command" {
+		t.Errorf("Expected the command output, got %q, %v", got, err)
+	}
+
+	t.Setenv("TODO_PASSPHRASE", "from-env")
+	if got, err := Passphrase("echo from-command"); err != nil || got != "from-env" {
+		t.Errorf("Expected TODO_PASSPHRASE to win, got %q, %v", got, err)
+	}
+}
@@ -0,0 +1,215 @@
+// Package exporter writes tasks in formats other tools read: todo.txt, CSV,
+// a Markdown checklist, JSON Lines and iCalendar.
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+)
+
+// Formats lists the formats Write understands
+var Formats = []string{"todotxt", "csv", "markdown", "jsonl", "ics"}
+
+// Filter selects the tasks to export. Zero fields select everything.
+type Filter struct {
+	Statuses []string  // Keep tasks with one of these statuses
+	From, To time.Time // Keep tasks due within [From, To]
+}
+
+// Match reports whether f selects task. A date range only selects tasks
+// that have an end time.
+func (f Filter) Match(task domain.TodoItem) bool {
+	if len(f.Statuses) > 0 {
+		found := false
+		for _, status := range f.Statuses {
+			if task.Status == status {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !f.From.IsZero() || !f.To.IsZero() {
+		if task.EndTime.IsZero() {
+			return false
+		}
+		if !f.From.IsZero() && task.EndTime.Before(f.From) {
+			return false
+		}
+		if !f.To.IsZero() && task.EndTime.After(f.To) {
+			return false
+		}
+	}
+	return true
+}
+
+// Write writes todos to w in the given format
+func Write(format string, w io.Writer, todos []domain.TodoItem) error {
+	switch format {
+	case "todotxt":
+		return writeTodoTxt(w, todos)
+	case "csv":
+		return writeCSV(w, todos)
+	case "markdown":
+		return writeMarkdown(w, todos)
+	case "jsonl":
+		return writeJSONLines(w, todos)
+	case "ics":
+		return writeICS(w, todos)
+	}
+	return fmt.Errorf("unknown export format %q, must be one of: %s", format, strings.Join(Formats, ", "))
+}
+
+// todoTxtPriority maps urgencies onto todo.txt priority letters
+var todoTxtPriority = map[string]string{"urgent": "A", "high": "B", "medium": "C", "low": "D"}
+
+// writeTodoTxt writes one todo.txt line per task. Statuses todo.txt has no
+// notion of, such as in_progress or deleted, are kept as a status: tag.
+func writeTodoTxt(w io.Writer, todos []domain.TodoItem) error {
+	for _, task := range todos {
+		var parts []string
+		done := task.Status == "completed"
+		priority := todoTxtPriority[task.Urgent]
+		if done {
+			parts = append(parts, "x")
+			if !task.CompletedAt.IsZero() {
+				parts = append(parts, task.CompletedAt.Format("2006-01-02"))
+			}
+		} else if priority != "" {
+			parts = append(parts, "("+priority+")")
+		}
+		if !task.CreateTime.IsZero() && (!done || !task.CompletedAt.IsZero()) {
+			parts = append(parts, task.CreateTime.Format("2006-01-02"))
+		}
+
+		parts = append(parts, oneLine(task.TaskName))
+		if task.Project != "" {
+			parts = append(parts, "+"+todoTxtWord(task.Project))
+		}
+		for _, tag := range task.Tags {
+			parts = append(parts, "@"+todoTxtWord(tag))
+		}
+		if !task.EndTime.IsZero() {
+			parts = append(parts, "due:"+task.EndTime.Format("2006-01-02"))
+		}
+		if done && priority != "" {
+			parts = append(parts, "pri:"+priority)
+		}
+		if task.Status != "pending" && task.Status != "completed" && task.Status != "" {
+			parts = append(parts, "status:"+task.Status)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(parts, " ")); err != nil {
+			return fmt.Errorf("failed to write todo.txt: %w", err)
+		}
+	}
+	return nil
+}
+
+// csvHeader names the columns written by writeCSV, which the CSV importer
+// reads back
+var csvHeader = []string{"id", "uuid", "name", "description", "status", "due", "priority", "project", "tags",
+	"user", "created", "completed", "recurring"}
+
+func writeCSV(w io.Writer, todos []domain.TodoItem) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for _, task := range todos {
+		recurring := ""
+		if task.IsRecurring {
+			recurring = task.RecurringType
+			if task.RecurringInterval > 1 {
+				recurring = fmt.Sprintf("every %d %s", task.RecurringInterval, task.RecurringType)
+			}
+		}
+		record := []string{
+			strconv.Itoa(task.TaskID), task.UUID, task.TaskName, task.TaskDesc, task.Status,
+			formatTime(task.EndTime, "2006-01-02 15:04"), task.Urgent, task.Project, strings.Join(task.Tags, ", "),
+			task.User, formatTime(task.CreateTime, time.RFC3339), formatTime(task.CompletedAt, time.RFC3339), recurring,
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// writeMarkdown writes a checklist with one item per task, details after
+// the name and the description indented below it
+func writeMarkdown(w io.Writer, todos []domain.TodoItem) error {
+	for _, task := range todos {
+		box := "[ ]"
+		if task.Status == "completed" {
+			box = "[x]"
+		}
+		line := fmt.Sprintf("- %s %s", box, oneLine(task.TaskName))
+		var details []string
+		if !task.EndTime.IsZero() {
+			details = append(details, "due "+task.EndTime.Format("2006-01-02 15:04"))
+		}
+		if task.Urgent != "" {
+			details = append(details, task.Urgent)
+		}
+		if task.Status == "deleted" || task.Status == "in_progress" {
+			details = append(details, task.Status)
+		}
+		if task.Project != "" {
+			details = append(details, "+"+task.Project)
+		}
+		for _, tag := range task.Tags {
+			details = append(details, "#"+todoTxtWord(tag))
+		}
+		if len(details) > 0 {
+			line += " (" + strings.Join(details, ", ") + ")"
+		}
+		if desc := strings.TrimSpace(task.TaskDesc); desc != "" {
+			line += "\n  " + strings.ReplaceAll(desc, "\n", "\n  ")
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("failed to write Markdown: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeJSONLines writes each task as it is stored, one JSON object per line
+func writeJSONLines(w io.Writer, todos []domain.TodoItem) error {
+	encoder := json.NewEncoder(w)
+	for _, task := range todos {
+		if err := encoder.Encode(task); err != nil {
+			return fmt.Errorf("failed to write JSON Lines: %w", err)
+		}
+	}
+	return nil
+}
+
+func formatTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// oneLine joins the lines of s, for formats with one task per line
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// todoTxtWord turns a project or tag into a single word
+func todoTxtWord(s string) string {
+	return strings.Join(strings.Fields(s), "_")
+}
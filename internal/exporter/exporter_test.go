@@ -0,0 +1,128 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/importer"
+)
+
+func sampleTasks() []domain.TodoItem {
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.Local)
+	return []domain.TodoItem{
+		{TaskID: 1, TaskName: "Call mom", Status: "pending", Urgent: "urgent", Project: "Family", Tags: []string{"phone"},
+			CreateTime: created, EndTime: time.Date(2026, 10, 20, 18, 0, 0, 0, time.Local), DueDate: "2026-10-20"},
+		{TaskID: 2, TaskName: "Pay rent", TaskDesc: "Transfer\nby friday", Status: "completed", Urgent: "high",
+			CreateTime: created, CompletedAt: time.Date(2026, 10, 5, 12, 0, 0, 0, time.Local)},
+		{TaskID: 3, TaskName: "Write report", Status: "in_progress", Tags: []string{"deep work"}},
+	}
+}
+
+func TestWrite_TodoTxtRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write("todotxt", &buf, sampleTasks()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := "(A) 2026-10-01 Call mom +Family @phone due:2026-10-20\n" +
+		"x 2026-10-05 2026-10-01 Pay rent pri:B\n" +
+		"Write report @deep_work status:in_progress\n"
+	if buf.String() != want {
+		t.Fatalf("Unexpected todo.txt:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	result, err := importer.Read("todotxt", &buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	call, rent := result.Tasks[0], result.Tasks[1]
+	if call.TaskName != "Call mom" || call.Urgent != "urgent" || call.Project != "Family" || call.DueDate != "2026-10-20" {
+		t.Errorf("Task did not survive a round trip: %+v", call)
+	}
+	if rent.Status != "completed" || rent.Urgent != "high" || rent.CompletedAt.Format("2006-01-02") != "2026-10-05" {
+		t.Errorf("Completed task did not survive a round trip: %+v", rent)
+	}
+}
+
+func TestWrite_CSVRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write("csv", &buf, sampleTasks()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), strings.Join(csvHeader, ",")+"\n") {
+		t.Fatalf("Expected the header first, got:\n%s", buf.String())
+	}
+
+	result, err := importer.Read("csv", &buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(result.Tasks) != 3 {
+		t.Fatalf("Expected 3 tasks, got %+v", result)
+	}
+	call, rent, report := result.Tasks[0], result.Tasks[1], result.Tasks[2]
+	if call.Urgent != "urgent" || call.Project != "Family" || call.EndTime.Hour() != 18 || len(call.Tags) != 1 {
+		t.Errorf("Task did not survive a round trip: %+v", call)
+	}
+	if rent.TaskDesc != "Transfer\nby friday" || rent.Status != "completed" || rent.CompletedAt.IsZero() {
+		t.Errorf("Completed task did not survive a round trip: %+v", rent)
+	}
+	if report.Status != "in_progress" {
+		t.Errorf("Expected the status to survive a round trip, got %+v", report)
+	}
+}
+
+func TestWrite_MarkdownAndJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write("markdown", &buf, sampleTasks()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := "- [ ] Call mom (due 2026-10-20 18:00, urgent, +Family, #phone)\n" +
+		"- [x] Pay rent (high)\n  Transfer\n  by friday\n" +
+		"- [ ] Write report (in_progress, #deep_work)\n"
+	if buf.String() != want {
+		t.Errorf("Unexpected Markdown:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := Write("jsonl", &buf, sampleTasks()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q", buf.String())
+	}
+	var task domain.TodoItem
+	if err := json.Unmarshal([]byte(lines[1]), &task); err != nil || task.TaskID != 2 || task.TaskDesc != "Transfer\nby friday" {
+		t.Errorf("Unexpected JSON line %q (%v)", lines[1], err)
+	}
+
+	if err := Write("xml", &buf, nil); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	tasks := sampleTasks()
+	from := time.Date(2026, 10, 20, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []bool
+	}{
+		{"empty", Filter{}, []bool{true, true, true}},
+		{"status", Filter{Statuses: []string{"completed", "in_progress"}}, []bool{false, true, true}},
+		{"range", Filter{From: from, To: from.AddDate(0, 0, 1)}, []bool{true, false, false}},
+		{"before range", Filter{From: from.AddDate(0, 0, 1)}, []bool{false, false, false}},
+	}
+	for _, tt := range tests {
+		for i, task := range tasks {
+			if got := tt.filter.Match(task); got != tt.want[i] {
+				t.Errorf("%s: Match(%s) = %v, want %v", tt.name, task.TaskName, got, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/rrule"
+)
+
+// icsPriorities maps urgencies onto iCalendar priorities, 1 being the highest
+var icsPriorities = map[string]int{"urgent": 1, "high": 3, "medium": 5, "low": 9}
+
+// icsWriter writes content lines, folding them at 75 octets as RFC 5545
+// requires, and remembers the first write error
+type icsWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (iw *icsWriter) line(name, value string) {
+	if iw.err != nil {
+		return
+	}
+	_, iw.err = io.WriteString(iw.w, foldICS(name+":"+value)+"\r\n")
+}
+
+// writeICS writes an iCalendar file. Tasks with an event duration become
+// events (VEVENT) starting at their end time; other tasks become to-dos
+// (VTODO) due then. Recurring tasks carry an RRULE starting at their first
+// occurrence; missed and skipped occurrences are excluded with EXDATE, and
+// completed occurrences of to-dos are written as completed instances.
+//
+// Times are written without a time zone, so calendar apps show them at the
+// same wall-clock time the task was scheduled at.
+func writeICS(w io.Writer, todos []domain.TodoItem) error {
+	iw := &icsWriter{w: w}
+	iw.line("BEGIN", "VCALENDAR")
+	iw.line("VERSION", "2.0")
+	iw.line("PRODID", "-//SongRunqi//go-todo//EN")
+	iw.line("CALSCALE", "GREGORIAN")
+	stamp := time.Now()
+	for _, task := range todos {
+		writeICSTask(iw, task, stamp)
+	}
+	iw.line("END", "VCALENDAR")
+	if iw.err != nil {
+		return fmt.Errorf("failed to write iCalendar: %w", iw.err)
+	}
+	return nil
+}
+
+func writeICSTask(iw *icsWriter, task domain.TodoItem, stamp time.Time) {
+	event := task.EventDuration > 0
+	component := "VTODO"
+	if event {
+		component = "VEVENT"
+	}
+	uid := task.UUID
+	if uid == "" {
+		uid = fmt.Sprintf("task-%d@go-todo", task.TaskID)
+	}
+	start := task.EndTime
+	if task.IsRecurring {
+		start = task.RecurrenceStart
+		if start.IsZero() {
+			start = seriesStart(task)
+		}
+	}
+
+	iw.line("BEGIN", component)
+	iw.line("UID", uid)
+	iw.line("DTSTAMP", icsUTC(stamp))
+	if !task.CreateTime.IsZero() {
+		iw.line("CREATED", icsUTC(task.CreateTime))
+	}
+	iw.line("SUMMARY", escapeICS(task.TaskName))
+	if task.TaskDesc != "" {
+		iw.line("DESCRIPTION", escapeICS(task.TaskDesc))
+	}
+	if !start.IsZero() {
+		writeICSTime(iw, start, event, task.EventDuration)
+	}
+	iw.line("STATUS", icsStatus(task.Status, event))
+	if !event && task.Status == "completed" && !task.CompletedAt.IsZero() {
+		iw.line("COMPLETED", icsUTC(task.CompletedAt))
+	}
+	if priority, ok := icsPriorities[task.Urgent]; ok {
+		iw.line("PRIORITY", strconv.Itoa(priority))
+	}
+	if len(task.Tags) > 0 {
+		categories := make([]string, len(task.Tags))
+		for i, tag := range task.Tags {
+			categories[i] = escapeICS(tag)
+		}
+		iw.line("CATEGORIES", strings.Join(categories, ","))
+	}
+
+	rule := icsRRule(task, start)
+	if rule != "" {
+		iw.line("RRULE", rule)
+		for _, occ := range task.OccurrenceHistory {
+			if occ.Status == "missed" || occ.Status == "skipped" {
+				iw.line("EXDATE", icsLocal(seriesTime(occ)))
+			}
+		}
+	}
+	iw.line("END", component)
+
+	// Events simply take place, but a completed to-do instance is recorded
+	// as an override of that instance, as is a rescheduled instance of either
+	if rule == "" {
+		return
+	}
+	for _, occ := range task.OccurrenceHistory {
+		moved := !occ.OriginalTime.IsZero() && (occ.Status == "pending" || occ.Status == "completed")
+		done := !event && occ.Status == "completed"
+		if !moved && !done {
+			continue
+		}
+		iw.line("BEGIN", component)
+		iw.line("UID", uid)
+		iw.line("DTSTAMP", icsUTC(stamp))
+		iw.line("RECURRENCE-ID", icsLocal(seriesTime(occ)))
+		iw.line("SUMMARY", escapeICS(task.TaskName))
+		writeICSTime(iw, occ.ScheduledTime, event, task.EventDuration)
+		if done {
+			iw.line("STATUS", "COMPLETED")
+			if !occ.CompletedAt.IsZero() {
+				iw.line("COMPLETED", icsUTC(occ.CompletedAt))
+			}
+		}
+		iw.line("END", component)
+	}
+}
+
+// writeICSTime writes when an event takes place or a to-do is due. A to-do
+// only gets a DUE: its DTSTART would be the same time, which RFC 5545 does
+// not allow, and without a DTSTART its RRULE repeats from the DUE.
+func writeICSTime(iw *icsWriter, at time.Time, event bool, duration time.Duration) {
+	if event {
+		iw.line("DTSTART", icsLocal(at))
+		iw.line("DURATION", icsDuration(duration))
+	} else {
+		iw.line("DUE", icsLocal(at))
+	}
+}
+
+// seriesTime returns the time the series scheduled occ for, which for a
+// rescheduled occurrence is where it was before it moved
+func seriesTime(occ domain.OccurrenceRecord) time.Time {
+	if occ.OriginalTime.IsZero() {
+		return occ.ScheduledTime
+	}
+	return occ.OriginalTime
+}
+
+// seriesStart returns the first scheduled occurrence of a recurring task,
+// which the RRULE counts from
+func seriesStart(task domain.TodoItem) time.Time {
+	start := task.EndTime
+	for _, occ := range task.OccurrenceHistory {
+		if t := seriesTime(occ); start.IsZero() || t.Before(start) {
+			start = t
+		}
+	}
+	return start
+}
+
+// icsRRule builds the RRULE of a recurring task starting at start, or ""
+// for tasks that do not recur in a way iCalendar can express
+func icsRRule(task domain.TodoItem, start time.Time) string {
+	if !task.IsRecurring || start.IsZero() {
+		return ""
+	}
+	var rule *rrule.Rule
+	var err error
+	if task.RecurrenceRule != "" {
+		rule, err = rrule.Parse(task.RecurrenceRule)
+	} else {
+		rule, err = rrule.FromFields(task.RecurringType, task.RecurringInterval, task.RecurringWeekdays)
+	}
+	if err != nil {
+		return ""
+	}
+	// A rule with an end of its own is written as it is
+	if rule.Count > 0 || !rule.Until.IsZero() {
+		return rule.String()
+	}
+
+	days := weekdaySet(task)
+	if task.RecurringMaxCount > 0 {
+		rule.Count = task.RecurringMaxCount
+		if len(days) > 0 {
+			// The maximum counts periods; the first one only has the days
+			// from the start on
+			rule.Count *= len(days)
+			for _, day := range days {
+				if day < int(start.Weekday()) {
+					rule.Count--
+				}
+			}
+		}
+	} else if task.Status == "completed" || task.Status == "cancelled" {
+		// A finished series ends at its last scheduled occurrence, written
+		// as a floating time like DTSTART and DUE
+		until := start
+		for _, occ := range task.OccurrenceHistory {
+			if t := seriesTime(occ); t.After(until) {
+				until = t
+			}
+		}
+		rule.Until = time.Date(until.Year(), until.Month(), until.Day(),
+			until.Hour(), until.Minute(), until.Second(), 0, time.Local)
+	}
+	return rule.String()
+}
+
+// weekdaySet returns the distinct valid weekdays of a weekday-weekly task
+// in order, or nil for other tasks
+func weekdaySet(task domain.TodoItem) []int {
+	if task.RecurringType != "weekly" {
+		return nil
+	}
+	var seen [7]bool
+	for _, day := range task.RecurringWeekdays {
+		if day >= 0 && day < 7 {
+			seen[day] = true
+		}
+	}
+	var days []int
+	for day, ok := range seen {
+		if ok {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// icsStatus maps a task status onto the STATUS of a VTODO or VEVENT
+func icsStatus(status string, event bool) string {
+	switch status {
+	case "deleted", "cancelled":
+		return "CANCELLED"
+	case "completed":
+		if !event {
+			return "COMPLETED"
+		}
+	case "in_progress":
+		if !event {
+			return "IN-PROCESS"
+		}
+	}
+	if event {
+		return "CONFIRMED"
+	}
+	return "NEEDS-ACTION"
+}
+
+// icsLocal formats t as a floating local time
+func icsLocal(t time.Time) string {
+	return t.Format("20060102T150405")
+}
+
+// icsUTC formats t as a UTC time, as DTSTAMP, CREATED and COMPLETED require
+func icsUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icsDuration formats d as an iCalendar duration such as PT1H30M
+func icsDuration(d time.Duration) string {
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	seconds := (d - minutes*time.Minute) / time.Second
+
+	out := "P"
+	if days > 0 {
+		out += fmt.Sprintf("%dD", days)
+	}
+	if hours > 0 || minutes > 0 || seconds > 0 {
+		out += "T"
+		if hours > 0 {
+			out += fmt.Sprintf("%dH", hours)
+		}
+		if minutes > 0 {
+			out += fmt.Sprintf("%dM", minutes)
+		}
+		if seconds > 0 {
+			out += fmt.Sprintf("%dS", seconds)
+		}
+	}
+	if out == "P" {
+		return "PT0S"
+	}
+	return out
+}
+
+// escapeICS escapes a TEXT value
+func escapeICS(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "").Replace(s)
+}
+
+// foldICS splits a content line longer than 75 octets into continuation
+// lines, without breaking a UTF-8 sequence
+func foldICS(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := utf8.RuneLen(r)
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
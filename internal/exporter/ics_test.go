@@ -0,0 +1,203 @@
+package exporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+)
+
+// icsComponents splits an iCalendar file into its unfolded components
+func icsComponents(t *testing.T, data string) [][]string {
+	t.Helper()
+	if !strings.HasPrefix(data, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(data, "END:VCALENDAR\r\n") {
+		t.Fatalf("Expected a VCALENDAR, got:\n%s", data)
+	}
+	var components [][]string
+	var current []string
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n ", ""), "\r\n") {
+		switch {
+		case line == "BEGIN:VTODO" || line == "BEGIN:VEVENT":
+			current = []string{line}
+		case line == "END:VTODO" || line == "END:VEVENT":
+			components = append(components, append(current, line))
+			current = nil
+		case current != nil:
+			current = append(current, line)
+		}
+	}
+	return components
+}
+
+func hasLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWrite_ICSRecurringEvent(t *testing.T) {
+	// A class on Mondays and Wednesdays at 14:00 for 3 weeks, starting on a
+	// Wednesday, with the first Wednesday missed
+	wed := time.Date(2026, 10, 14, 14, 0, 0, 0, time.Local)
+	class := domain.TodoItem{
+		TaskID: 1, UUID: "class-uuid", TaskName: "Math class", Status: "active", EndTime: wed.AddDate(0, 0, 5),
+		EventDuration: 90 * time.Minute, IsRecurring: true, RecurringType: "weekly", RecurringInterval: 1,
+		RecurringWeekdays: []int{3, 1}, RecurringMaxCount: 3,
+		OccurrenceHistory: []domain.OccurrenceRecord{
+			{ScheduledTime: wed, Status: "missed"},
+			{ScheduledTime: wed.AddDate(0, 0, 5), Status: "pending"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write("ics", &buf, []domain.TodoItem{class}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	components := icsComponents(t, buf.String())
+	if len(components) != 1 || components[0][0] != "BEGIN:VEVENT" {
+		t.Fatalf("Expected one VEVENT, got %q", components)
+	}
+	for _, want := range []string{
+		"UID:class-uuid", "SUMMARY:Math class", "DTSTART:20261014T140000", "DURATION:PT1H30M", "STATUS:CONFIRMED",
+		"RRULE:FREQ=WEEKLY;WKST=SU;BYDAY=MO,WE;COUNT=5", "EXDATE:20261014T140000",
+	} {
+		if !hasLine(components[0], want) {
+			t.Errorf("Expected %q in %q", want, components[0])
+		}
+	}
+}
+
+func TestWrite_ICSRescheduledOccurrence(t *testing.T) {
+	// Wednesday's class moved to Thursday at 16:00
+	mon := time.Date(2026, 10, 19, 14, 0, 0, 0, time.Local)
+	class := domain.TodoItem{
+		TaskID: 1, UUID: "class-uuid", TaskName: "Math class", Status: "active", EndTime: mon,
+		EventDuration: time.Hour, IsRecurring: true, RecurringType: "weekly", RecurringInterval: 1,
+		RecurringWeekdays: []int{1, 3}, RecurrenceRule: "FREQ=WEEKLY;BYDAY=MO,WE", RecurrenceStart: mon,
+		OccurrenceHistory: []domain.OccurrenceRecord{
+			{ScheduledTime: mon, Status: "pending"},
+			{ScheduledTime: mon.AddDate(0, 0, 3).Add(2 * time.Hour), Status: "pending", OriginalTime: mon.AddDate(0, 0, 2)},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write("ics", &buf, []domain.TodoItem{class}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	components := icsComponents(t, buf.String())
+	if len(components) != 2 || components[1][0] != "BEGIN:VEVENT" {
+		t.Fatalf("Expected the series and one moved instance, got %q", components)
+	}
+	for _, want := range []string{
+		"UID:class-uuid", "RECURRENCE-ID:20261021T140000", "DTSTART:20261022T160000", "DURATION:PT1H",
+	} {
+		if !hasLine(components[1], want) {
+			t.Errorf("Expected %q in %q", want, components[1])
+		}
+	}
+}
+
+func TestWrite_ICSRecurringTodo(t *testing.T) {
+	start := time.Date(2026, 10, 1, 8, 0, 0, 0, time.Local)
+	done := start.Add(30 * time.Minute)
+	report := domain.TodoItem{
+		TaskID: 2, TaskName: "Report; draft, final", TaskDesc: "Line one\nLine two", Status: "cancelled",
+		Urgent: "high", Tags: []string{"work"}, EndTime: start.AddDate(0, 0, 4),
+		IsRecurring: true, RecurringType: "daily", RecurringInterval: 2,
+		OccurrenceHistory: []domain.OccurrenceRecord{
+			{ScheduledTime: start, Status: "completed", CompletedAt: done},
+			{ScheduledTime: start.AddDate(0, 0, 2), Status: "skipped"},
+			{ScheduledTime: start.AddDate(0, 0, 4), Status: "pending"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write("ics", &buf, []domain.TodoItem{report}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	components := icsComponents(t, buf.String())
+	if len(components) != 2 {
+		t.Fatalf("Expected the series and one completed instance, got %q", components)
+	}
+	series, instance := components[0], components[1]
+	for _, want := range []string{
+		"UID:task-2@go-todo", `SUMMARY:Report\; draft\, final`, `DESCRIPTION:Line one\nLine two`,
+		"DUE:20261001T080000", "STATUS:CANCELLED", "PRIORITY:3", "CATEGORIES:work",
+		"RRULE:FREQ=DAILY;INTERVAL=2;UNTIL=20261005T080000", "EXDATE:20261003T080000",
+	} {
+		if !hasLine(series, want) {
+			t.Errorf("Expected %q in %q", want, series)
+		}
+	}
+	for _, want := range []string{
+		"UID:task-2@go-todo", "RECURRENCE-ID:20261001T080000", "DUE:20261001T080000", "STATUS:COMPLETED", "COMPLETED:" + icsUTC(done),
+	} {
+		if !hasLine(instance, want) {
+			t.Errorf("Expected %q in %q", want, instance)
+		}
+	}
+	// A to-do is due when it starts; RFC 5545 wants DUE after DTSTART
+	for _, component := range components {
+		for _, line := range component {
+			if strings.HasPrefix(line, "DTSTART") {
+				t.Errorf("Expected no DTSTART in a to-do, got %q", component)
+			}
+		}
+	}
+}
+
+func TestWrite_ICSRecurrenceRule(t *testing.T) {
+	first := time.Date(2026, 10, 30, 17, 0, 0, 0, time.Local)
+	payday := domain.TodoItem{
+		TaskID: 4, TaskName: "Payday", Status: "active", EndTime: first.AddDate(0, 0, 28),
+		IsRecurring: true, RecurringType: "monthly", RecurringInterval: 1, RecurringMaxCount: 12,
+		RecurrenceRule: "FREQ=MONTHLY;BYDAY=-1FR", RecurrenceStart: first,
+	}
+	var buf bytes.Buffer
+	if err := Write("ics", &buf, []domain.TodoItem{payday}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	components := icsComponents(t, buf.String())
+	for _, want := range []string{"DUE:20261030T170000", "RRULE:FREQ=MONTHLY;BYDAY=-1FR;COUNT=12"} {
+		if !hasLine(components[0], want) {
+			t.Errorf("Expected %q in %q", want, components[0])
+		}
+	}
+}
+
+func TestWrite_ICSFoldsLongLines(t *testing.T) {
+	task := domain.TodoItem{TaskID: 3, TaskName: strings.Repeat("日本語", 20), Status: "pending"}
+	var buf bytes.Buffer
+	if err := Write("ics", &buf, []domain.TodoItem{task}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Line longer than 75 octets: %q", line)
+		}
+	}
+	components := icsComponents(t, buf.String())
+	if !hasLine(components[0], "SUMMARY:"+task.TaskName) || !hasLine(components[0], "STATUS:NEEDS-ACTION") {
+		t.Errorf("Unexpected VTODO %q", components[0])
+	}
+}
+
+func TestICSDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                          "PT0S",
+		time.Hour:                  "PT1H",
+		25*time.Hour + time.Minute: "P1DT1H1M",
+		48 * time.Hour:             "P2D",
+		90 * time.Second:           "PT1M30S",
+	}
+	for d, want := range tests {
+		if got := icsDuration(d); got != want {
+			t.Errorf("icsDuration(%v) = %s, want %s", d, got, want)
+		}
+	}
+}
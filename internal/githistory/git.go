@@ -0,0 +1,187 @@
+// Package githistory commits the task files into a local git repository
+// after every save, so their history can be inspected with any git tool and
+// pushed to a remote of the user's choosing.
+package githistory
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoGit is returned when the git executable cannot be found
+var ErrNoGit = errors.New("git history needs the git command, which was not found in PATH")
+
+// Repo is a git working tree holding the task files
+type Repo struct {
+	Dir string
+}
+
+// Commit is one commit touching a task file
+type Commit struct {
+	Hash    string
+	Time    time.Time
+	Subject string
+}
+
+// Open returns the repository rooted at dir. The repository itself is
+// created by the first commit.
+func Open(dir string) (*Repo, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, ErrNoGit
+	}
+	return &Repo{Dir: dir}, nil
+}
+
+// Exists reports whether the repository has been initialised
+func (r *Repo) Exists() bool {
+	_, err := os.Stat(filepath.Join(r.Dir, ".git"))
+	return err == nil
+}
+
+// Commit records the current content of paths with message and returns the
+// new commit hash, or "" if the files were unchanged. If HEAD is still the
+// commit named by amend, that commit is replaced instead, so several saves
+// belonging to one operation end up in a single commit.
+func (r *Repo) Commit(message, amend string, paths ...string) (string, error) {
+	if !r.Exists() {
+		if _, err := r.git("init", "--quiet"); err != nil {
+			return "", err
+		}
+	}
+
+	rel, err := r.relative(paths)
+	if err != nil {
+		return "", err
+	}
+	existing := make([]string, 0, len(rel))
+	for i, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			existing = append(existing, rel[i])
+		}
+	}
+	if len(existing) == 0 {
+		return "", nil
+	}
+	if _, err := r.git(append([]string{"add", "--"}, existing...)...); err != nil {
+		return "", err
+	}
+
+	// Nothing staged: the save did not change the files
+	if _, err := r.git(append([]string{"diff", "--cached", "--quiet", "--"}, existing...)...); err == nil {
+		return "", nil
+	}
+
+	args := []string{"commit", "--quiet", "--no-verify", "-m", message}
+	if amend != "" && r.head() == amend {
+		args = append(args, "--amend")
+	}
+	if _, err := r.git(append(append(args, "--"), existing...)...); err != nil {
+		return "", err
+	}
+	return r.head(), nil
+}
+
+// head returns the hash of HEAD, or "" before the first commit
+func (r *Repo) head() string {
+	out, err := r.git("rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// Log returns the commits that changed any of paths, oldest first
+func (r *Repo) Log(paths ...string) ([]Commit, error) {
+	if !r.Exists() {
+		return nil, nil
+	}
+	rel, err := r.relative(paths)
+	if err != nil {
+		return nil, err
+	}
+	out, err := r.git(append([]string{"log", "--reverse", "--format=%H%x00%at%x00%s", "--"}, rel...)...)
+	if err != nil {
+		// A repository without commits has no HEAD yet
+		if strings.Contains(err.Error(), "does not have any commits") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		seconds, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected git log output %q", line)
+		}
+		commits = append(commits, Commit{Hash: fields[0], Time: time.Unix(seconds, 0), Subject: fields[2]})
+	}
+	return commits, nil
+}
+
+// Show returns the content of path as of commit, and false if the file did
+// not exist in that commit
+func (r *Repo) Show(commit, path string) ([]byte, bool, error) {
+	rel, err := r.relative([]string{path})
+	if err != nil {
+		return nil, false, err
+	}
+	spec := commit + ":" + rel[0]
+	if _, err := r.git("cat-file", "-e", spec); err != nil {
+		return nil, false, nil
+	}
+	out, err := r.git("show", spec)
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
+
+// relative converts paths to slash-separated paths relative to the repository
+func (r *Repo) relative(paths []string) ([]string, error) {
+	rel := make([]string, len(paths))
+	for i, p := range paths {
+		rp, err := filepath.Rel(r.Dir, p)
+		if err != nil || strings.HasPrefix(rp, "..") {
+			return nil, fmt.Errorf("%s is outside the history repository %s", p, r.Dir)
+		}
+		rel[i] = filepath.ToSlash(rp)
+	}
+	return rel, nil
+}
+
+// git runs a git command in the repository. Commits use a fixed identity
+// when the user has not configured one, so they never fail for that reason.
+func (r *Repo) git(args ...string) ([]byte, error) {
+	name := args[0]
+	if name == "commit" && !r.hasIdentity() {
+		args = append([]string{"-c", "user.name=todo", "-c", "user.email=todo@localhost"}, args...)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return out, fmt.Errorf("git %s failed: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// hasIdentity reports whether git knows who to attribute commits to
+func (r *Repo) hasIdentity() bool {
+	cmd := exec.Command("git", "config", "user.email")
+	cmd.Dir = r.Dir
+	return cmd.Run() == nil
+}
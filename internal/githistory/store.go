@@ -0,0 +1,113 @@
+package githistory
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/logger"
+)
+
+// Store wraps a file-backed TodoStore and commits the task files to a git
+// repository after every successful Save. All saves of one operation (see
+// BeginOperation) are folded into a single commit described by the operation.
+type Store struct {
+	inner domain.TodoStore
+	repo  *Repo
+	paths []string // active and backup task files
+
+	started   bool // the files as found on first load have been committed
+	op, desc  string
+	opSeq     int    // incremented by BeginOperation
+	committed int    // opSeq of the operation last committed
+	lastHash  string // the commit made for it
+}
+
+// NewStore returns a wrapper around inner committing the task files at
+// path and backupPath to repo
+func NewStore(inner domain.TodoStore, repo *Repo, path, backupPath string) *Store {
+	return &Store{inner: inner, repo: repo, paths: []string{path, backupPath}}
+}
+
+// Unwrap returns the wrapped store
+func (s *Store) Unwrap() domain.TodoStore {
+	return s.inner
+}
+
+// Repo returns the repository the task files are committed to
+func (s *Store) Repo() *Repo {
+	return s.repo
+}
+
+// BeginOperation sets the commit message for the saves that follow, up to
+// the next call
+func (s *Store) BeginOperation(op, desc string) {
+	s.op = op
+	s.desc = desc
+	s.opSeq++
+	if recorder, ok := domain.As[domain.OperationRecorder](s.inner); ok {
+		recorder.BeginOperation(op, desc)
+	}
+}
+
+// Load loads todos from the wrapped store
+func (s *Store) Load(backup bool) ([]domain.TodoItem, error) {
+	return s.inner.Load(backup)
+}
+
+// Save saves todos to the wrapped store and commits the task files. A failed
+// commit is logged but does not fail the save, which has already happened.
+func (s *Store) Save(todos []domain.TodoItem, backup bool) error {
+	if !s.started {
+		s.started = true
+		s.commitExisting()
+	}
+	if err := s.inner.Save(todos, backup); err != nil {
+		return err
+	}
+
+	amend := ""
+	if s.opSeq > 0 && s.committed == s.opSeq {
+		amend = s.lastHash
+	}
+	hash, err := s.repo.Commit(s.message(backup), amend, s.paths...)
+	if err != nil {
+		logger.Warnf("Failed to commit task files to git: %v", err)
+		return nil
+	}
+	if hash != "" {
+		s.committed = s.opSeq
+		s.lastHash = hash
+		logger.Debugf("Committed task files as %s", hash)
+	}
+	return nil
+}
+
+// commitExisting commits the task files as they are before the first save,
+// so the history starts from the tasks that predate it and changes made
+// outside todo (e.g. hand edits) are kept apart from the operation
+func (s *Store) commitExisting() {
+	message := "Record changes made outside todo"
+	if !s.repo.Exists() {
+		message = "Start task history"
+	}
+	if _, err := s.repo.Commit(message, "", s.paths...); err != nil {
+		logger.Warnf("Failed to commit task files to git: %v", err)
+	}
+}
+
+// message describes the current operation, or the saved file for saves
+// made outside an operation
+func (s *Store) message(backup bool) string {
+	switch {
+	case s.desc != "":
+		return s.desc
+	case s.op != "":
+		return s.op
+	}
+	path := s.paths[0]
+	if backup {
+		path = s.paths[1]
+	}
+	return fmt.Sprintf("Update %s", filepath.Base(path))
+}
@@ -0,0 +1,135 @@
+package githistory
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+)
+
+// fileStore is a minimal TodoStore writing each list to a JSON file
+type fileStore struct {
+	paths [2]string
+}
+
+func (f *fileStore) path(backup bool) string {
+	if backup {
+		return f.paths[1]
+	}
+	return f.paths[0]
+}
+
+func (f *fileStore) Load(backup bool) ([]domain.TodoItem, error) {
+	var todos []domain.TodoItem
+	data, err := os.ReadFile(f.path(backup))
+	if os.IsNotExist(err) {
+		return todos, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return todos, json.Unmarshal(data, &todos)
+}
+
+func (f *fileStore) Save(todos []domain.TodoItem, backup bool) error {
+	data, err := json.Marshal(todos)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.path(backup), data, 0644)
+}
+
+func newTestStore(t *testing.T) (*Store, *fileStore) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	inner := &fileStore{paths: [2]string{filepath.Join(dir, "todo.json"), filepath.Join(dir, "todo_back.json")}}
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	return NewStore(inner, repo, inner.paths[0], inner.paths[1]), inner
+}
+
+func subjects(t *testing.T, s *Store) []string {
+	t.Helper()
+	commits, err := s.Repo().Log(s.paths...)
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	var out []string
+	for _, c := range commits {
+		out = append(out, c.Subject)
+	}
+	return out
+}
+
+func TestStore_CommitsOnePerOperation(t *testing.T) {
+	store, inner := newTestStore(t)
+	if err := inner.Save([]domain.TodoItem{{TaskID: 12, TaskName: "Write weekly report", Status: "pending"}}, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	store.BeginOperation("complete", "Complete task 12: Write weekly report")
+	if err := store.Save([]domain.TodoItem{{TaskID: 12, TaskName: "Write weekly report", Status: "completed"}}, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// A delete saves both lists, but becomes a single commit
+	store.BeginOperation("delete", "Delete task 12: Write weekly report")
+	if err := store.Save([]domain.TodoItem{{TaskID: 12, TaskName: "Write weekly report", Status: "deleted"}}, true); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := store.Save(nil, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Saving unchanged content makes no commit
+	store.BeginOperation("update", "Update nothing")
+	if err := store.Save(nil, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	want := "Start task history|Complete task 12: Write weekly report|Delete task 12: Write weekly report"
+	if got := strings.Join(subjects(t, store), "|"); got != want {
+		t.Errorf("Expected commits %q, got %q", want, got)
+	}
+
+	commits, _ := store.Repo().Log(store.paths...)
+	data, ok, err := store.Repo().Show(commits[1].Hash, inner.paths[0])
+	if err != nil || !ok || !strings.Contains(string(data), `"status":"completed"`) {
+		t.Errorf("Expected todo.json as of the complete commit, got %s, %v, %v", data, ok, err)
+	}
+	if _, ok, err := store.Repo().Show(commits[0].Hash, inner.paths[1]); ok || err != nil {
+		t.Errorf("todo_back.json did not exist in the first commit, got %v, %v", ok, err)
+	}
+}
+
+func TestStore_KeepsOutsideChangesApart(t *testing.T) {
+	store, inner := newTestStore(t)
+	store.BeginOperation("create", "Create task 1: First")
+	if err := store.Save([]domain.TodoItem{{TaskID: 1, TaskName: "First"}}, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// A hand edit, then a new command
+	if err := inner.Save([]domain.TodoItem{{TaskID: 1, TaskName: "Edited"}}, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	next := NewStore(inner, store.Repo(), inner.paths[0], inner.paths[1])
+	next.BeginOperation("create", "Create task 2: Second")
+	if err := next.Save([]domain.TodoItem{{TaskID: 1, TaskName: "Edited"}, {TaskID: 2, TaskName: "Second"}}, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	want := "Create task 1: First|Record changes made outside todo|Create task 2: Second"
+	if got := strings.Join(subjects(t, store), "|"); got != want {
+		t.Errorf("Expected commits %q, got %q", want, got)
+	}
+}
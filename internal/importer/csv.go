@@ -0,0 +1,135 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/validator"
+)
+
+// csvColumns maps the header names accepted for each task field, written
+// lower-case without spaces, dashes or underscores
+var csvColumns = map[string]string{
+	"name": "name", "title": "name", "task": "name", "taskname": "name", "summary": "name", "subject": "name",
+	"description": "desc", "desc": "desc", "taskdesc": "desc", "notes": "desc", "note": "desc", "details": "desc",
+	"status": "status", "state": "status",
+	"due": "due", "duedate": "due", "deadline": "due", "endtime": "due",
+	"priority": "priority", "urgency": "priority", "urgent": "priority",
+	"project": "project", "tags": "tags", "tag": "tags", "labels": "tags", "contexts": "tags",
+	"user": "user", "owner": "user", "assignee": "user",
+	"created": "created", "createdat": "created", "createtime": "created", "entry": "created",
+	"completed": "completed", "completedat": "completed", "completiondate": "completed", "end": "completed",
+}
+
+// readCSV reads a CSV file whose first row names the columns. Column names
+// are matched loosely (see csvColumns); a file without a name column uses
+// the description as the task name, as Taskwarrior's CSV does. Tags are
+// separated by commas, semicolons or spaces.
+func readCSV(r io.Reader) (*Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return &Result{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	result := &Result{}
+	columns := make(map[string]int)
+	for i, name := range header {
+		key := strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+		if i == 0 {
+			// Spreadsheets often start the file with a byte order mark
+			key = strings.TrimPrefix(key, "\ufeff")
+		}
+		field, ok := csvColumns[key]
+		if !ok {
+			result.warnf("column %q is not a task field; ignored", name)
+			continue
+		}
+		if _, dup := columns[field]; !dup {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns["name"]; !ok {
+		desc, ok := columns["desc"]
+		if !ok {
+			return nil, fmt.Errorf("CSV header has no name, title or description column")
+		}
+		columns["name"] = desc
+		delete(columns, "desc")
+	}
+
+	row := 1
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		row++
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		get := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		task := domain.TodoItem{
+			TaskName: get("name"),
+			TaskDesc: get("desc"),
+			Status:   "pending",
+			Project:  get("project"),
+			User:     get("user"),
+		}
+		if task.TaskName == "" {
+			result.warnf("row %d: task has no name; skipped", row)
+			continue
+		}
+		if status := get("status"); status != "" {
+			task.Status = validator.NormalizeStatus(status)
+		}
+		if task.Status == "deleted" {
+			result.warnf("row %d (%s) is deleted; skipped", row, task.TaskName)
+			continue
+		}
+		for _, tag := range strings.FieldsFunc(get("tags"), func(r rune) bool { return r == ',' || r == ';' || r == ' ' }) {
+			addTag(&task, strings.TrimLeft(tag, "#@+"))
+		}
+		if urgent, ok := parsePriority(get("priority")); ok {
+			task.Urgent = urgent
+		} else {
+			result.warnf("row %d (%s): unknown priority %q; not imported", row, task.TaskName, get("priority"))
+		}
+		if due := get("due"); due != "" {
+			t, dateOnly, err := parseDate(due)
+			if err != nil {
+				result.warnf("row %d (%s): %v; the due date was not imported", row, task.TaskName, err)
+			} else {
+				setDue(&task, t, dateOnly)
+			}
+		}
+		if created := get("created"); created != "" {
+			if t, _, err := parseDate(created); err == nil {
+				task.CreateTime = t
+			}
+		}
+		if completed := get("completed"); completed != "" && task.Status == "completed" {
+			if t, _, err := parseDate(completed); err == nil {
+				task.CompletedAt = t
+			}
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+	return result, nil
+}
@@ -0,0 +1,563 @@
+package importer
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/rrule"
+)
+
+// icsProperty is one content line of an iCalendar component
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// icsComponent is a VTODO or VEVENT with its properties, excluding those of
+// nested components such as alarms
+type icsComponent struct {
+	kind  string
+	props []icsProperty
+}
+
+func (c *icsComponent) get(name string) (icsProperty, bool) {
+	for _, p := range c.props {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return icsProperty{}, false
+}
+
+func (c *icsComponent) all(name string) []icsProperty {
+	var props []icsProperty
+	for _, p := range c.props {
+		if p.name == name {
+			props = append(props, p)
+		}
+	}
+	return props
+}
+
+// icsWeekdays maps iCalendar weekday codes onto RecurringWeekdays values
+var icsWeekdays = map[string]int{"SU": 0, "MO": 1, "TU": 2, "WE": 3, "TH": 4, "FR": 5, "SA": 6}
+
+// readICS reads the to-dos (VTODO) and events (VEVENT) of an iCalendar file.
+// An event starts at its end time and lasts its DTEND or DURATION; a to-do is
+// due at its DUE, or else its DTSTART. RRULEs repeat from the DTSTART, or
+// for a to-do without one from its DUE, and make recurring tasks: simple
+// rules built from FREQ, INTERVAL, BYDAY, COUNT and UNTIL set the recurring
+// fields, and others are kept as the task's recurrence rule. Rules todo
+// cannot evaluate, such as hourly ones, are imported as one-off tasks with
+// a warning. Cancelled entries and modified instances of a series
+// (RECURRENCE-ID) are skipped.
+func readICS(r io.Reader) (*Result, error) {
+	components, err := parseICS(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	overrides := 0
+	for _, c := range components {
+		if _, ok := c.get("RECURRENCE-ID"); ok {
+			overrides++
+			continue
+		}
+		summary, _ := c.get("SUMMARY")
+		name := strings.TrimSpace(unescapeICS(summary.value))
+		label := fmt.Sprintf("%s %q", c.kind, name)
+		if name == "" {
+			result.warnf("%s without a SUMMARY; skipped", c.kind)
+			continue
+		}
+
+		task := domain.TodoItem{TaskName: name, Status: "pending"}
+		status, _ := c.get("STATUS")
+		switch strings.ToUpper(status.value) {
+		case "CANCELLED":
+			result.warnf("%s is cancelled; skipped", label)
+			continue
+		case "COMPLETED":
+			if c.kind == "VTODO" {
+				task.Status = "completed"
+			}
+		case "IN-PROCESS":
+			task.Status = "in_progress"
+		}
+		if desc, ok := c.get("DESCRIPTION"); ok {
+			task.TaskDesc = strings.TrimSpace(unescapeICS(desc.value))
+		}
+		if priority, ok := c.get("PRIORITY"); ok {
+			task.Urgent = icsUrgency(priority.value)
+		}
+		for _, categories := range c.all("CATEGORIES") {
+			for _, tag := range splitICSList(categories.value) {
+				addTag(&task, unescapeICS(tag))
+			}
+		}
+		if created, ok := c.get("CREATED"); ok {
+			if t, _, err := icsTime(created); err == nil {
+				task.CreateTime = t.In(time.Local)
+			}
+		}
+		if completed, ok := c.get("COMPLETED"); ok {
+			if t, _, err := icsTime(completed); err == nil {
+				task.CompletedAt = t.In(time.Local)
+			}
+		}
+
+		var start time.Time
+		var dateOnly bool
+		if dtstart, ok := c.get("DTSTART"); ok {
+			if start, dateOnly, err = icsTime(dtstart); err != nil {
+				result.warnf("%s: %v; skipped", label, err)
+				continue
+			}
+		}
+		if c.kind == "VEVENT" {
+			if start.IsZero() {
+				result.warnf("%s has no DTSTART; skipped", label)
+				continue
+			}
+			task.EndTime = start.In(time.Local)
+			task.DueDate = task.EndTime.Format("2006-01-02")
+			duration, err := icsEventDuration(&c, start)
+			if err != nil {
+				result.warnf("%s: %v; imported without a duration", label, err)
+			}
+			task.EventDuration = duration
+		} else {
+			due, dueDateOnly := start, dateOnly
+			if dueProp, ok := c.get("DUE"); ok {
+				if due, dueDateOnly, err = icsTime(dueProp); err != nil {
+					result.warnf("%s: %v; imported without a due date", label, err)
+					due = time.Time{}
+				}
+			}
+			if !due.IsZero() {
+				setDue(&task, due.In(time.Local), dueDateOnly)
+			}
+		}
+
+		rules := c.all("RRULE")
+		if len(rules) > 0 {
+			if task.EndTime.IsZero() {
+				result.warnf("%s repeats but has no start; imported as a one-off task", label)
+			} else if err := icsRecurrence(&task, rules[0].value, start, result, label); err != nil {
+				// Rules the recurring fields cannot describe are kept whole
+				if err := icsRule(&task, rules[0].value, start); err != nil {
+					result.warnf("%s: %v; imported as a one-off task", label, err)
+				}
+			}
+			if len(rules) > 1 {
+				result.warnf("%s has %d RRULEs; only the first was imported", label, len(rules))
+			}
+		}
+		if len(c.all("RDATE")) > 0 {
+			result.warnf("%s: extra dates (RDATE) were not imported", label)
+		}
+		if exdates := c.all("EXDATE"); len(exdates) > 0 && task.IsRecurring {
+			result.warnf("%s: excluded dates (EXDATE) were not imported", label)
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+	if overrides > 0 {
+		result.warnf("%d modified instances of repeating entries (RECURRENCE-ID) were skipped", overrides)
+	}
+	return result, nil
+}
+
+// icsRecurrence makes task recur by rule, an RRULE value. start is the
+// DTSTART the rule repeats from, in the zone of the file, which BYDAY refers
+// to. Parts todo cannot express are returned as an error, leaving task
+// unchanged.
+func icsRecurrence(task *domain.TodoItem, rule string, start time.Time, result *Result, label string) error {
+	parts := make(map[string]string)
+	for _, part := range strings.Split(rule, ";") {
+		key, value, _ := strings.Cut(part, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		switch key {
+		case "FREQ", "INTERVAL", "BYDAY", "WKST", "BYMONTHDAY", "BYMONTH", "COUNT", "UNTIL":
+		default:
+			return fmt.Errorf("%s in RRULE is not supported", key)
+		}
+		parts[key] = strings.ToUpper(strings.TrimSpace(value))
+	}
+	if start.IsZero() {
+		start = task.EndTime
+	}
+
+	freq := parts["FREQ"]
+	recurringType := map[string]string{"DAILY": "daily", "WEEKLY": "weekly", "MONTHLY": "monthly", "YEARLY": "yearly"}[freq]
+	if recurringType == "" {
+		return fmt.Errorf("repeating with FREQ=%s is not supported", freq)
+	}
+	interval := 1
+	if value, ok := parts["INTERVAL"]; ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid INTERVAL %q", value)
+		}
+		interval = n
+	}
+
+	var weekdays []int
+	if value, ok := parts["BYDAY"]; ok {
+		if freq == "DAILY" && interval == 1 {
+			// Every day on some weekdays is every week on those weekdays
+			recurringType = "weekly"
+		} else if freq != "WEEKLY" {
+			return fmt.Errorf("BYDAY with FREQ=%s is not supported", freq)
+		}
+		// Weekdays are local to the task, which may fall on another day than
+		// in the zone of the file
+		shift := int(start.In(time.Local).Weekday()) - int(start.Weekday())
+		seen := make(map[int]bool)
+		for _, code := range strings.Split(value, ",") {
+			day, ok := icsWeekdays[code]
+			if !ok {
+				return fmt.Errorf("BYDAY=%s is not supported, only plain weekdays are", code)
+			}
+			day = (day + shift + 7) % 7
+			if !seen[day] {
+				seen[day] = true
+				weekdays = append(weekdays, day)
+			}
+		}
+		// Weekday tasks repeat by weeks from Sunday; every few weeks from
+		// another day needs the rule
+		if wkst := cmp.Or(parts["WKST"], "MO"); wkst != "SU" && interval > 1 && len(weekdays) > 1 {
+			return fmt.Errorf("weeks starting on %s are not supported", wkst)
+		}
+	}
+
+	// BYMONTHDAY and BYMONTH are harmless when they repeat DTSTART
+	if value, ok := parts["BYMONTHDAY"]; ok && (value != strconv.Itoa(start.Day()) || (freq != "MONTHLY" && freq != "YEARLY")) {
+		return fmt.Errorf("BYMONTHDAY=%s is not supported", value)
+	}
+	if value, ok := parts["BYMONTH"]; ok && (value != strconv.Itoa(int(start.Month())) || freq != "YEARLY") {
+		return fmt.Errorf("BYMONTH=%s is not supported", value)
+	}
+
+	count := 0
+	if value, ok := parts["COUNT"]; ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid COUNT %q", value)
+		}
+		count = n
+	} else if value, ok := parts["UNTIL"]; ok {
+		until, _, err := icsTime(icsProperty{value: value})
+		if err != nil {
+			return fmt.Errorf("invalid UNTIL %q", value)
+		}
+		count = countUntil(task.EndTime, until.In(task.EndTime.Location()), recurringType, interval, weekdays)
+		if count == 0 {
+			return fmt.Errorf("UNTIL %s is before the first occurrence", value)
+		}
+	}
+
+	// The maximum of weekday tasks counts weeks, and the first week only has
+	// the days from the start on
+	if count > 0 && len(weekdays) > 0 {
+		instances := count
+		for _, day := range weekdays {
+			if day < int(task.EndTime.Weekday()) {
+				instances++
+			}
+		}
+		count = (instances + len(weekdays) - 1) / len(weekdays)
+		if instances%len(weekdays) != 0 {
+			result.warnf("%s: its last week is cut short; it repeats for %d whole weeks", label, count)
+		}
+	}
+
+	task.IsRecurring = true
+	task.RecurringType = recurringType
+	task.RecurringInterval = interval
+	task.RecurringWeekdays = weekdays
+	task.RecurringMaxCount = count
+	return nil
+}
+
+// icsRule makes task recur by rule, an RRULE value kept as the task's
+// recurrence rule from its end time on. start is the DTSTART in the zone of
+// the file; rules picking days are refused when the task falls on another
+// date locally.
+func icsRule(task *domain.TodoItem, rule string, start time.Time) error {
+	parsed, err := rrule.Parse(rule)
+	if err != nil {
+		return err
+	}
+	if !start.IsZero() && start.In(time.Local).Day() != start.Day() &&
+		(len(parsed.ByDay) > 0 || len(parsed.ByMonthDay) > 0) {
+		return fmt.Errorf("RRULE days in another time zone are not supported")
+	}
+	task.IsRecurring = true
+	task.RecurrenceRule = parsed.String()
+	task.RecurrenceStart = task.EndTime
+	return nil
+}
+
+// countUntil counts the occurrences from first up to and including until
+func countUntil(first, until time.Time, recurringType string, interval int, weekdays []int) int {
+	count := 0
+	if len(weekdays) > 0 {
+		on := make(map[int]bool)
+		for _, day := range weekdays {
+			on[day] = true
+		}
+		firstWeek := first.AddDate(0, 0, -int(first.Weekday()))
+		for t := first; !t.After(until); t = t.AddDate(0, 0, 1) {
+			week := int(t.Sub(firstWeek).Hours()/24) / 7
+			if on[int(t.Weekday())] && week%interval == 0 {
+				count++
+			}
+		}
+		return count
+	}
+	for k := 0; ; k++ {
+		var t time.Time
+		switch recurringType {
+		case "daily":
+			t = first.AddDate(0, 0, k*interval)
+		case "weekly":
+			t = first.AddDate(0, 0, 7*k*interval)
+		case "monthly":
+			t = first.AddDate(0, k*interval, 0)
+		default:
+			t = first.AddDate(k*interval, 0, 0)
+		}
+		if t.After(until) {
+			return count
+		}
+		count++
+	}
+}
+
+// icsEventDuration returns how long an event lasts, from its DTEND or
+// DURATION
+func icsEventDuration(c *icsComponent, start time.Time) (time.Duration, error) {
+	if end, ok := c.get("DTEND"); ok {
+		t, _, err := icsTime(end)
+		if err != nil {
+			return 0, err
+		}
+		if t.Before(start) {
+			return 0, fmt.Errorf("DTEND is before DTSTART")
+		}
+		return t.Sub(start), nil
+	}
+	if duration, ok := c.get("DURATION"); ok {
+		return parseICSDuration(duration.value)
+	}
+	return 0, nil
+}
+
+// parseICSDuration parses an iCalendar duration such as PT1H30M or P1W
+func parseICSDuration(value string) (time.Duration, error) {
+	s := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "+")
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("invalid DURATION %q", value)
+	}
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour, 'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+	var d time.Duration
+	inTime := false
+	n := -1
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 'T':
+			inTime = true
+		case c >= '0' && c <= '9':
+			if n < 0 {
+				n = 0
+			}
+			n = n*10 + int(c-'0')
+		default:
+			unit, ok := units[c]
+			// M means months before T, which a duration cannot hold
+			if !ok || n < 0 || (c == 'M' && !inTime) || (c != 'M' && (c == 'H' || c == 'S') != inTime) {
+				return 0, fmt.Errorf("invalid DURATION %q", value)
+			}
+			d += time.Duration(n) * unit
+			n = -1
+		}
+	}
+	if n >= 0 {
+		return 0, fmt.Errorf("invalid DURATION %q", value)
+	}
+	return d, nil
+}
+
+// icsTime parses a DATE or DATE-TIME value. UTC times and times with a
+// known TZID are in that zone, others in local time; dateOnly reports a DATE.
+func icsTime(p icsProperty) (t time.Time, dateOnly bool, err error) {
+	value := strings.TrimSpace(p.value)
+	if p.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err = time.ParseInLocation("20060102", value, time.Local)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+		}
+		return t, true, nil
+	}
+
+	loc := time.Local
+	if strings.HasSuffix(value, "Z") {
+		loc = time.UTC
+		value = strings.TrimSuffix(value, "Z")
+	} else if tzid := p.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(strings.Trim(tzid, "/")); err == nil {
+			loc = l
+		}
+	}
+	t, err = time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date-time %q", p.value)
+	}
+	return t, false, nil
+}
+
+// icsUrgency maps an iCalendar PRIORITY, 1 (highest) to 9 (lowest) with 0
+// undefined, onto an urgency
+func icsUrgency(value string) string {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	switch {
+	case err != nil || n <= 0:
+		return ""
+	case n <= 2:
+		return "urgent"
+	case n <= 4:
+		return "high"
+	case n == 5:
+		return "medium"
+	}
+	return "low"
+}
+
+// parseICS reads the VTODO and VEVENT components of an iCalendar file,
+// unfolding continuation lines
+func parseICS(r io.Reader) ([]icsComponent, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) == 0 {
+			// Files saved by some editors start with a byte order mark
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read iCalendar: %w", err)
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, fmt.Errorf("not an iCalendar file: it must start with BEGIN:VCALENDAR")
+	}
+
+	var components []icsComponent
+	var stack []string
+	var current *icsComponent
+	for i, line := range lines {
+		prop, err := parseICSLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		switch prop.name {
+		case "BEGIN":
+			kind := strings.ToUpper(prop.value)
+			stack = append(stack, kind)
+			if (kind == "VTODO" || kind == "VEVENT") && current == nil {
+				components = append(components, icsComponent{kind: kind})
+				current = &components[len(components)-1]
+			}
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(prop.value) {
+				return nil, fmt.Errorf("line %d: END:%s does not match the open component", i+1, prop.value)
+			}
+			if kind := stack[len(stack)-1]; (kind == "VTODO" || kind == "VEVENT") && current != nil && current.kind == kind {
+				current = nil
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			// Only direct properties; a VALARM's DESCRIPTION is not the task's
+			if current != nil && stack[len(stack)-1] == current.kind {
+				current.props = append(current.props, prop)
+			}
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("iCalendar file ends inside %s", stack[len(stack)-1])
+	}
+	return components, nil
+}
+
+// parseICSLine splits a content line into its name, parameters and value
+func parseICSLine(line string) (icsProperty, error) {
+	prop := icsProperty{params: make(map[string]string)}
+	quoted := false
+	colon := -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return prop, fmt.Errorf("invalid content line %q", line)
+	}
+	prop.value = line[colon+1:]
+
+	fields := strings.Split(line[:colon], ";")
+	prop.name = strings.ToUpper(fields[0])
+	for _, param := range fields[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	if strings.ToUpper(prop.params["VALUE"]) == "DATE" {
+		prop.params["VALUE"] = "DATE"
+	}
+	return prop, nil
+}
+
+// splitICSList splits a comma-separated list, keeping escaped commas
+func splitICSList(value string) []string {
+	var items []string
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value):
+			b.WriteByte(value[i])
+			b.WriteByte(value[i+1])
+			i++
+		case value[i] == ',':
+			items = append(items, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return append(items, b.String())
+}
+
+// unescapeICS undoes the escaping of a TEXT value
+func unescapeICS(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";").Replace(value)
+}
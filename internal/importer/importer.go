@@ -0,0 +1,111 @@
+// Package importer reads tasks exported by other todo tools.
+//
+// Each format maps what it can onto TodoItem fields and reports what it
+// cannot carry over as warnings instead of failing. The tasks it returns
+// have no ID or UUID yet; the caller validates them and allocates both.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+)
+
+// Formats lists the formats Read understands
+var Formats = []string{"todotxt", "taskwarrior", "csv", "ics"}
+
+// Result holds the tasks read from a file and warnings about what could not
+// be imported faithfully
+type Result struct {
+	Tasks    []domain.TodoItem
+	Warnings []string
+}
+
+func (r *Result) warnf(format string, a ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, a...))
+}
+
+// Read parses the tasks in r, which holds data in the given format
+func Read(format string, r io.Reader) (*Result, error) {
+	switch format {
+	case "todotxt":
+		return readTodoTxt(r)
+	case "taskwarrior":
+		return readTaskwarrior(r)
+	case "csv":
+		return readCSV(r)
+	case "ics":
+		return readICS(r)
+	}
+	return nil, fmt.Errorf("unknown import format %q, must be one of: %s", format, strings.Join(Formats, ", "))
+}
+
+// parsePriority maps the priority names and letters used by other tools
+// onto the urgency levels of todo
+func parsePriority(priority string) (string, bool) {
+	switch p := strings.ToLower(strings.TrimSpace(priority)); p {
+	case "":
+		return "", true
+	case "a", "urgent", "critical", "highest":
+		return "urgent", true
+	case "b", "h", "high":
+		return "high", true
+	case "c", "m", "medium", "normal":
+		return "medium", true
+	case "l", "low", "lowest":
+		return "low", true
+	}
+	return "", false
+}
+
+// dateLayouts are the date formats accepted for due, creation and
+// completion dates, most specific first
+var dateLayouts = []string{
+	time.RFC3339,
+	"20060102T150405Z", // Taskwarrior
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+}
+
+// parseDate parses a date in one of dateLayouts, in local time unless it
+// carries a zone. dateOnly reports whether it had no time of day.
+func parseDate(value string) (t time.Time, dateOnly bool, err error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, !strings.Contains(layout, "15"), nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+}
+
+// setDue sets the end time and due date of task. A due date without a time
+// of day falls due at the end of that day.
+func setDue(task *domain.TodoItem, due time.Time, dateOnly bool) {
+	if dateOnly {
+		due = time.Date(due.Year(), due.Month(), due.Day(), 23, 59, 0, 0, due.Location())
+	}
+	task.EndTime = due
+	task.DueDate = due.Format("2006-01-02")
+}
+
+// addTag appends tag to task unless it is empty or already there
+func addTag(task *domain.TodoItem, tag string) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return
+	}
+	for _, t := range task.Tags {
+		if t == tag {
+			return
+		}
+	}
+	task.Tags = append(task.Tags, tag)
+}
@@ -0,0 +1,279 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+)
+
+func TestRead_TodoTxt(t *testing.T) {
+	input := `(A) 2026-10-01 Call mom +Family @phone due:2026-10-20
+x 2026-10-05 2026-10-01 Pay rent +Home +Bills pri:B
+
+(D) Read https://example.com due:someday
+`
+	result, err := Read("todotxt", strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(result.Tasks) != 3 || len(result.Warnings) != 1 {
+		t.Fatalf("Expected 3 tasks and 1 warning, got %+v", result)
+	}
+
+	call := result.Tasks[0]
+	if call.TaskName != "Call mom" || call.Urgent != "urgent" || call.Project != "Family" ||
+		len(call.Tags) != 1 || call.Tags[0] != "phone" || call.DueDate != "2026-10-20" || call.EndTime.Hour() != 23 {
+		t.Errorf("Unexpected task: %+v", call)
+	}
+	if call.CreateTime.Format("2006-01-02") != "2026-10-01" || call.Status != "pending" {
+		t.Errorf("Expected a pending task created on 2026-10-01, got %+v", call)
+	}
+
+	rent := result.Tasks[1]
+	if rent.Status != "completed" || rent.CompletedAt.Format("2006-01-02") != "2026-10-05" || rent.Urgent != "high" ||
+		rent.Project != "Home" || len(rent.Tags) != 1 || rent.Tags[0] != "Bills" {
+		t.Errorf("Unexpected completed task: %+v", rent)
+	}
+
+	if read := result.Tasks[2]; read.TaskName != "Read https://example.com" || read.Urgent != "low" || read.DueDate != "" {
+		t.Errorf("Unexpected task: %+v", read)
+	}
+}
+
+func TestRead_Taskwarrior(t *testing.T) {
+	input := `[
+{"uuid":"a","description":"Standup","status":"recurring","recur":"2weeks","due":"20261020T090000Z","priority":"H","project":"work","tags":["meet"]},
+{"uuid":"b","description":"Standup","status":"pending","parent":"a","due":"20261020T090000Z"},
+{"uuid":"c","description":"Gone","status":"deleted"},
+{"uuid":"d","description":"Report","status":"completed","end":"20261010T120000Z","annotations":[{"description":"draft"},{"description":"final"}]},
+{"uuid":"e","description":"Odd","status":"recurring","recur":"weekdays","due":"20261020T090000Z"}
+]`
+	result, err := Read("taskwarrior", strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(result.Tasks) != 3 || len(result.Warnings) != 3 {
+		t.Fatalf("Expected 3 tasks and 3 warnings, got %+v", result)
+	}
+
+	standup := result.Tasks[0]
+	if !standup.IsRecurring || standup.RecurringType != "weekly" || standup.RecurringInterval != 2 ||
+		standup.Urgent != "high" || standup.Project != "work" || standup.EndTime.UTC().Hour() != 9 {
+		t.Errorf("Unexpected recurring task: %+v", standup)
+	}
+	if report := result.Tasks[1]; report.Status != "completed" || report.TaskDesc != "draft\nfinal" || report.CompletedAt.IsZero() {
+		t.Errorf("Unexpected completed task: %+v", report)
+	}
+	if odd := result.Tasks[2]; odd.IsRecurring {
+		t.Errorf("Expected an unsupported recurrence to be imported as a one-off, got %+v", odd)
+	}
+
+	// Older versions write one object per line
+	lines := `{"description":"One","status":"pending"}
+{"description":"Two","status":"pending","priority":"L"}`
+	if result, err := Read("taskwarrior", strings.NewReader(lines)); err != nil || len(result.Tasks) != 2 || result.Tasks[1].Urgent != "low" {
+		t.Errorf("Expected two tasks from JSON lines, got %+v (%v)", result, err)
+	}
+}
+
+func TestRead_CSV(t *testing.T) {
+	input := "\ufeffTitle,Notes,Due Date,Priority,Tags,Status,Color\n" +
+		"Buy milk,2 liters,2026-10-21 18:00,Low,\"errand, #home\",todo,red\n" +
+		"Old,,,,,deleted,\n" +
+		",,,,,,\n" +
+		"Report,,,x,,done,\n"
+	result, err := Read("csv", strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(result.Tasks) != 2 || len(result.Warnings) != 4 {
+		t.Fatalf("Expected 2 tasks and 4 warnings, got %+v", result)
+	}
+
+	milk := result.Tasks[0]
+	if milk.TaskName != "Buy milk" || milk.TaskDesc != "2 liters" || milk.Status != "pending" || milk.Urgent != "low" ||
+		milk.EndTime.Hour() != 18 || len(milk.Tags) != 2 || milk.Tags[1] != "home" {
+		t.Errorf("Unexpected task: %+v", milk)
+	}
+	if report := result.Tasks[1]; report.Status != "completed" || report.Urgent != "" {
+		t.Errorf("Unexpected task: %+v", report)
+	}
+
+	if _, err := Read("csv", strings.NewReader("Color,Size\nred,big\n")); err == nil {
+		t.Error("Expected an error for a CSV without a name column")
+	}
+}
+
+func TestRead_UnknownFormat(t *testing.T) {
+	if _, err := Read("xml", strings.NewReader("")); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestRead_ICS(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:class",
+		"SUMMARY:Math class",
+		"DTSTART:20261014T140000",
+		"DURATION:PT1H30M",
+		"RRULE:FREQ=WEEKLY;WKST=SU;BYDAY=MO,WE;COUNT=5",
+		"BEGIN:VALARM",
+		"DESCRIPTION:Reminder",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:report",
+		`SUMMARY:Report; draft\, final`,
+		"DESCRIPTION:Line one\\nLine",
+		"  two",
+		"DUE;VALUE=DATE:20261020",
+		"PRIORITY:3",
+		"CATEGORIES:work,deep\\, focus",
+		"END:VTODO",
+		"BEGIN:VEVENT",
+		"SUMMARY:Payday",
+		"DTSTART:20261030T090000",
+		"DTEND:20261030T093000",
+		"RRULE:FREQ=MONTHLY;BYSETPOS=-1;BYDAY=FR",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:class",
+		"RECURRENCE-ID:20261019T140000",
+		"SUMMARY:Math class",
+		"STATUS:COMPLETED",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"SUMMARY:Dropped",
+		"STATUS:CANCELLED",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n")
+	result, err := Read("ics", strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(result.Tasks) != 3 || len(result.Warnings) != 2 {
+		t.Fatalf("Expected 3 tasks and 2 warnings, got %+v", result)
+	}
+
+	class := result.Tasks[0]
+	if !class.IsRecurring || class.RecurringType != "weekly" || class.RecurringInterval != 1 ||
+		len(class.RecurringWeekdays) != 2 || class.RecurringWeekdays[0] != 1 || class.RecurringWeekdays[1] != 3 {
+		t.Errorf("Unexpected recurrence: %+v", class)
+	}
+	// Five classes from a Wednesday are Wednesday plus two full weeks
+	if class.RecurringMaxCount != 3 || class.EventDuration != 90*time.Minute || class.EndTime.Hour() != 14 || class.TaskDesc != "" {
+		t.Errorf("Unexpected event: %+v", class)
+	}
+
+	report := result.Tasks[1]
+	if report.TaskName != "Report; draft, final" || report.TaskDesc != "Line one\nLine two" || report.Urgent != "high" ||
+		report.DueDate != "2026-10-20" || report.EndTime.Hour() != 23 || len(report.Tags) != 2 || report.Tags[1] != "deep, focus" {
+		t.Errorf("Unexpected to-do: %+v", report)
+	}
+
+	payday := result.Tasks[2]
+	if !payday.IsRecurring || payday.RecurrenceRule != "FREQ=MONTHLY;BYDAY=FR;BYSETPOS=-1" ||
+		!payday.RecurrenceStart.Equal(payday.EndTime) || payday.EventDuration != 30*time.Minute {
+		t.Errorf("Expected the last Friday of the month to be kept as a rule, got %+v", payday)
+	}
+
+	if _, err := Read("ics", strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY:x\nEND:VCALENDAR\n")); err == nil {
+		t.Error("Expected an error for mismatched components")
+	}
+}
+
+func TestRead_ICSRecurringTodoWithoutStart(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VTODO",
+		"SUMMARY:Payday",
+		"DUE:20261030T090000",
+		"RRULE:FREQ=MONTHLY;BYDAY=-1FR",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n")
+	result, err := Read("ics", strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(result.Tasks) != 1 || len(result.Warnings) != 0 {
+		t.Fatalf("Expected 1 task and no warnings, got %+v", result)
+	}
+	// The rule repeats from the DUE
+	task := result.Tasks[0]
+	if !task.IsRecurring || task.RecurrenceRule != "FREQ=MONTHLY;BYDAY=-1FR" || !task.RecurrenceStart.Equal(task.EndTime) {
+		t.Errorf("Expected the rule to start at the due time, got %+v", task)
+	}
+}
+
+func TestICSRecurrence(t *testing.T) {
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local) // A Wednesday
+	tests := []struct {
+		rule          string
+		recurringType string
+		interval, max int
+		weekdays      int
+		warnings      int
+		unsupported   bool
+	}{
+		{"FREQ=DAILY;INTERVAL=2;COUNT=10", "daily", 2, 10, 0, 0, false},
+		{"FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", "weekly", 1, 0, 5, 0, false},
+		{"FREQ=WEEKLY;BYDAY=WE,FR;UNTIL=20261030T235959Z", "weekly", 1, 3, 2, 0, false},
+		{"FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4", "weekly", 1, 3, 2, 1, false},
+		{"FREQ=MONTHLY;BYMONTHDAY=14;UNTIL=20261231", "monthly", 1, 3, 0, 0, false},
+		{"FREQ=YEARLY;BYMONTH=10", "yearly", 1, 0, 0, 0, false},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO;WKST=MO", "weekly", 2, 0, 1, 0, false},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;WKST=SU", "weekly", 2, 0, 2, 0, false},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,SU", "", 0, 0, 0, 0, true},
+		{"FREQ=MONTHLY;BYDAY=2TU", "", 0, 0, 0, 0, true},
+		{"FREQ=MONTHLY;BYMONTHDAY=1", "", 0, 0, 0, 0, true},
+		{"FREQ=HOURLY", "", 0, 0, 0, 0, true},
+		{"FREQ=WEEKLY;BYSETPOS=1;BYDAY=MO", "", 0, 0, 0, 0, true},
+		{"FREQ=DAILY;UNTIL=20261001", "", 0, 0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		task := domain.TodoItem{EndTime: start}
+		result := &Result{}
+		err := icsRecurrence(&task, tt.rule, start, result, "test")
+		if tt.unsupported {
+			if err == nil || task.IsRecurring {
+				t.Errorf("%s: expected an error, got %+v", tt.rule, task)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.rule, err)
+			continue
+		}
+		if task.RecurringType != tt.recurringType || task.RecurringInterval != tt.interval || task.RecurringMaxCount != tt.max ||
+			len(task.RecurringWeekdays) != tt.weekdays || len(result.Warnings) != tt.warnings {
+			t.Errorf("%s: got %+v, warnings %q", tt.rule, task, result.Warnings)
+		}
+	}
+}
+
+func TestParseICSDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"PT1H30M": 90 * time.Minute,
+		"P1W":     7 * 24 * time.Hour,
+		"P1DT2H":  26 * time.Hour,
+		"+PT45S":  45 * time.Second,
+		"PT0S":    0,
+	}
+	for value, want := range tests {
+		if got, err := parseICSDuration(value); err != nil || got != want {
+			t.Errorf("parseICSDuration(%s) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"P1M", "PT1D", "P", "1H", "PT1"} {
+		if _, err := parseICSDuration(value); err == nil {
+			t.Errorf("parseICSDuration(%s): expected an error", value)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+)
+
+// twTask is a task as written by "task export"
+type twTask struct {
+	UUID        string   `json:"uuid"`
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Entry       string   `json:"entry"`
+	Due         string   `json:"due"`
+	End         string   `json:"end"`
+	Priority    string   `json:"priority"`
+	Project     string   `json:"project"`
+	Tags        []string `json:"tags"`
+	Recur       string   `json:"recur"`
+	Parent      string   `json:"parent"`
+	Annotations []struct {
+		Description string `json:"description"`
+	} `json:"annotations"`
+}
+
+// twRecurPeriod matches Taskwarrior recurrence periods such as "2w" or "3 months"
+var twRecurPeriod = regexp.MustCompile(`^(\d*)\s*([a-z]+)$`)
+
+// readTaskwarrior reads the output of "task export": a JSON array, or one
+// JSON object per line as older versions write it. Deleted tasks are
+// skipped. A recurring task is imported once, from its template; the
+// instances Taskwarrior generated from it are skipped.
+func readTaskwarrior(r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Taskwarrior export: %w", err)
+	}
+	tasks, err := decodeTaskwarrior(data)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make(map[string]bool)
+	for _, tw := range tasks {
+		if tw.Status == "recurring" {
+			templates[tw.UUID] = true
+		}
+	}
+
+	result := &Result{}
+	instances := 0
+	for i, tw := range tasks {
+		entry := i + 1
+		if tw.Status == "deleted" {
+			result.warnf("task %d (%s) is deleted in Taskwarrior; skipped", entry, tw.Description)
+			continue
+		}
+		if tw.Parent != "" && templates[tw.Parent] {
+			instances++
+			continue
+		}
+		if strings.TrimSpace(tw.Description) == "" {
+			result.warnf("task %d has no description; skipped", entry)
+			continue
+		}
+
+		task := domain.TodoItem{
+			TaskName: tw.Description,
+			Status:   "pending",
+			Project:  tw.Project,
+		}
+		if tw.Status == "completed" {
+			task.Status = "completed"
+		}
+		for _, tag := range tw.Tags {
+			addTag(&task, tag)
+		}
+		var notes []string
+		for _, a := range tw.Annotations {
+			notes = append(notes, a.Description)
+		}
+		task.TaskDesc = strings.Join(notes, "\n")
+
+		if urgent, ok := parsePriority(tw.Priority); ok {
+			task.Urgent = urgent
+		} else {
+			result.warnf("task %d (%s): unknown priority %q; not imported", entry, tw.Description, tw.Priority)
+		}
+		if tw.Entry != "" {
+			if t, _, err := parseDate(tw.Entry); err == nil {
+				task.CreateTime = t
+			}
+		}
+		if tw.End != "" && task.Status == "completed" {
+			if t, _, err := parseDate(tw.End); err == nil {
+				task.CompletedAt = t
+			}
+		}
+		if tw.Due != "" {
+			due, dateOnly, err := parseDate(tw.Due)
+			if err != nil {
+				result.warnf("task %d (%s): %v; the due date was not imported", entry, tw.Description, err)
+			} else {
+				setDue(&task, due, dateOnly)
+			}
+		}
+
+		if tw.Status == "recurring" {
+			switch recurringType, interval, ok := twRecurrence(tw.Recur); {
+			case !ok:
+				result.warnf("task %d (%s): recurrence %q cannot be expressed; imported as a one-off task", entry, tw.Description, tw.Recur)
+			case task.EndTime.IsZero():
+				result.warnf("task %d (%s): recurring task has no due date; imported as a one-off task", entry, tw.Description)
+			default:
+				task.IsRecurring = true
+				task.RecurringType = recurringType
+				task.RecurringInterval = interval
+			}
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+	if instances > 0 {
+		result.warnf("skipped %d instances of recurring tasks; each series is imported once", instances)
+	}
+	return result, nil
+}
+
+// decodeTaskwarrior decodes a JSON array of tasks, or one task per line
+func decodeTaskwarrior(data []byte) ([]twTask, error) {
+	var tasks []twTask
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &tasks); err != nil {
+			return nil, fmt.Errorf("invalid Taskwarrior export: %w", err)
+		}
+		return tasks, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var tw twTask
+		if err := json.Unmarshal(bytes.TrimSuffix(text, []byte(",")), &tw); err != nil {
+			return nil, fmt.Errorf("invalid Taskwarrior export on line %d: %w", line, err)
+		}
+		tasks = append(tasks, tw)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Taskwarrior export: %w", err)
+	}
+	return tasks, nil
+}
+
+// twRecurrence maps a Taskwarrior recurrence period onto a recurring type
+// and interval
+func twRecurrence(recur string) (string, int, bool) {
+	switch strings.ToLower(strings.TrimSpace(recur)) {
+	case "daily", "day":
+		return "daily", 1, true
+	case "weekly", "week":
+		return "weekly", 1, true
+	case "biweekly", "fortnight":
+		return "weekly", 2, true
+	case "monthly", "month":
+		return "monthly", 1, true
+	case "quarterly":
+		return "monthly", 3, true
+	case "yearly", "annual", "year":
+		return "yearly", 1, true
+	}
+
+	m := twRecurPeriod.FindStringSubmatch(strings.ToLower(strings.TrimSpace(recur)))
+	if m == nil {
+		return "", 0, false
+	}
+	n := 1
+	if m[1] != "" {
+		var err error
+		if n, err = strconv.Atoi(m[1]); err != nil || n < 1 {
+			return "", 0, false
+		}
+	}
+	switch m[2] {
+	case "d", "day", "days":
+		return "daily", n, true
+	case "w", "wk", "wks", "week", "weeks":
+		return "weekly", n, true
+	case "mo", "mos", "month", "months":
+		return "monthly", n, true
+	case "q", "qtr", "qtrs", "quarter", "quarters":
+		return "monthly", 3 * n, true
+	case "y", "yr", "yrs", "year", "years":
+		return "yearly", n, true
+	}
+	return "", 0, false
+}
@@ -0,0 +1,114 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+)
+
+var (
+	todoTxtPriority = regexp.MustCompile(`^\(([A-Z])\)$`)
+	todoTxtPriTag   = regexp.MustCompile(`^pri:[A-Z]$`)
+)
+
+// readTodoTxt reads one task per line in the todo.txt format
+// (https://github.com/todotxt/todo.txt): an optional "x" and completion
+// date, an optional (A)-(Z) priority, an optional creation date, then the
+// text with +project, @context and key:value tags. The first project becomes
+// the task's project; further projects and all contexts become tags. due:
+// and pri: are read, and other key:value tags stay in the task name.
+func readTodoTxt(r io.Reader) (*Result, error) {
+	result := &Result{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		task := domain.TodoItem{Status: "pending"}
+		if fields[0] == "x" {
+			task.Status = "completed"
+			fields = fields[1:]
+			if len(fields) > 0 {
+				if t, ok := todoTxtDate(fields[0]); ok {
+					task.CompletedAt = t
+					fields = fields[1:]
+				}
+			}
+		} else if m := todoTxtPriority.FindStringSubmatch(fields[0]); m != nil {
+			task.Urgent = todoTxtUrgency(m[1])
+			fields = fields[1:]
+		}
+		if len(fields) > 0 {
+			if t, ok := todoTxtDate(fields[0]); ok {
+				task.CreateTime = t
+				fields = fields[1:]
+			}
+		}
+
+		var words []string
+		for _, field := range fields {
+			switch {
+			case len(field) > 1 && field[0] == '+':
+				if task.Project == "" {
+					task.Project = field[1:]
+				} else {
+					addTag(&task, field[1:])
+				}
+			case len(field) > 1 && field[0] == '@':
+				addTag(&task, field[1:])
+			case strings.HasPrefix(field, "due:"):
+				due, dateOnly, err := parseDate(strings.TrimPrefix(field, "due:"))
+				if err != nil {
+					result.warnf("line %d: %v; the due date was not imported", line, err)
+					continue
+				}
+				setDue(&task, due, dateOnly)
+			case todoTxtPriTag.MatchString(field):
+				// Completed tasks keep their priority as a pri: tag
+				task.Urgent = todoTxtUrgency(field[len("pri:"):])
+			default:
+				words = append(words, field)
+			}
+		}
+
+		task.TaskName = strings.Join(words, " ")
+		if task.TaskName == "" {
+			result.warnf("line %d: task has no text; skipped", line)
+			continue
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read todo.txt: %w", err)
+	}
+	return result, nil
+}
+
+// todoTxtDate parses the YYYY-MM-DD dates at the start of a todo.txt line
+func todoTxtDate(field string) (time.Time, bool) {
+	t, err := time.ParseInLocation("2006-01-02", field, time.Local)
+	return t, err == nil
+}
+
+// todoTxtUrgency maps a todo.txt priority letter onto an urgency: A is
+// urgent, B high, C medium and anything below low
+func todoTxtUrgency(letter string) string {
+	switch letter {
+	case "A":
+		return "urgent"
+	case "B":
+		return "high"
+	case "C":
+		return "medium"
+	}
+	return "low"
+}
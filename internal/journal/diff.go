@@ -0,0 +1,165 @@
+package journal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+)
+
+// taskKey identifies a task within one list. Lists should not hold the same
+// TaskID twice, but older files sometimes do, so repeats are numbered.
+type taskKey struct {
+	id int
+	n  int
+}
+
+// keyed pairs each task in todos with its key, in list order
+func keyed(todos []domain.TodoItem) ([]taskKey, map[taskKey]domain.TodoItem) {
+	keys := make([]taskKey, 0, len(todos))
+	byKey := make(map[taskKey]domain.TodoItem, len(todos))
+	seen := make(map[int]int)
+	for _, t := range todos {
+		k := taskKey{id: t.TaskID, n: seen[t.TaskID]}
+		seen[t.TaskID]++
+		keys = append(keys, k)
+		byKey[k] = t
+	}
+	return keys, byKey
+}
+
+// Diff returns the changes that turn before into after for the named list.
+// Removals come first, followed by additions and modifications in the order
+// the tasks appear in after.
+func Diff(store string, before, after []domain.TodoItem) []Change {
+	beforeKeys, beforeByKey := keyed(before)
+	afterKeys, afterByKey := keyed(after)
+
+	var changes []Change
+	for _, k := range beforeKeys {
+		if _, ok := afterByKey[k]; !ok {
+			old := beforeByKey[k]
+			changes = append(changes, Change{Store: store, TaskID: k.id, Before: &old})
+		}
+	}
+	for _, k := range afterKeys {
+		now := afterByKey[k]
+		old, existed := beforeByKey[k]
+		switch {
+		case !existed:
+			changes = append(changes, Change{Store: store, TaskID: k.id, After: &now})
+		case !sameTask(old, now):
+			changes = append(changes, Change{Store: store, TaskID: k.id, Before: &old, After: &now})
+		}
+	}
+	return changes
+}
+
+// sameTask compares tasks by their stored (JSON) form, so differences the
+// file cannot represent, such as time zone pointers, are ignored
+func sameTask(a, b domain.TodoItem) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// State is the content of both task lists at some point in the journal
+type State struct {
+	Active []domain.TodoItem
+	Backup []domain.TodoItem
+}
+
+// list returns a pointer to the named list
+func (s *State) list(store string) (*[]domain.TodoItem, error) {
+	switch store {
+	case StoreActive:
+		return &s.Active, nil
+	case StoreBackup:
+		return &s.Backup, nil
+	default:
+		return nil, fmt.Errorf("unknown task list %q", store)
+	}
+}
+
+// Apply applies the changes of e to the state
+func (s *State) Apply(e Event) error {
+	for _, c := range e.Changes {
+		if err := s.applyChange(c, false); err != nil {
+			return fmt.Errorf("event %d: %w", e.Seq, err)
+		}
+	}
+	return nil
+}
+
+// ApplyStrict applies changes, first checking that every task they touch
+// still looks exactly like the change's Before side. On error the state is
+// left unchanged.
+func (s *State) ApplyStrict(changes []Change) error {
+	next := State{
+		Active: append([]domain.TodoItem(nil), s.Active...),
+		Backup: append([]domain.TodoItem(nil), s.Backup...),
+	}
+	for _, c := range changes {
+		if err := next.applyChange(c, true); err != nil {
+			return err
+		}
+	}
+	*s = next
+	return nil
+}
+
+func (s *State) applyChange(c Change, strict bool) error {
+	list, err := s.list(c.Store)
+	if err != nil {
+		return err
+	}
+
+	index := -1
+	for i := range *list {
+		if (*list)[i].TaskID == c.TaskID {
+			index = i
+			break
+		}
+	}
+
+	if strict {
+		switch {
+		case c.Before == nil && index != -1:
+			return fmt.Errorf("task %d is already in the %s list", c.TaskID, c.Store)
+		case c.Before != nil && (index == -1 || !sameTask((*list)[index], *c.Before)):
+			return fmt.Errorf("task %d in the %s list has changed since", c.TaskID, c.Store)
+		}
+	}
+
+	switch {
+	case c.Before == nil && c.After != nil:
+		*list = append(*list, *c.After)
+	case c.Before != nil && c.After == nil:
+		if index == -1 {
+			return fmt.Errorf("cannot remove task %d from %s list: not found", c.TaskID, c.Store)
+		}
+		*list = append((*list)[:index], (*list)[index+1:]...)
+	case c.Before != nil && c.After != nil:
+		if index == -1 {
+			return fmt.Errorf("cannot modify task %d in %s list: not found", c.TaskID, c.Store)
+		}
+		(*list)[index] = *c.After
+	}
+	return nil
+}
+
+// Replay rebuilds the task lists by applying events in order, stopping after
+// the event with sequence number upTo (0 means replay everything)
+func Replay(events []Event, upTo int) (State, error) {
+	state := State{Active: make([]domain.TodoItem, 0), Backup: make([]domain.TodoItem, 0)}
+	for _, e := range events {
+		if upTo > 0 && e.Seq > upTo {
+			break
+		}
+		if err := state.Apply(e); err != nil {
+			return state, err
+		}
+	}
+	return state, nil
+}
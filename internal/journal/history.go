@@ -0,0 +1,159 @@
+package journal
+
+import (
+	"fmt"
+	"time"
+)
+
+// Journal operations with special meaning for undo and redo
+const (
+	OpInit   = "init"
+	OpReplay = "replay"
+	OpUndo   = "undo"
+	OpRedo   = "redo"
+
+	// OpMaintenance labels automatic upkeep done before a command runs,
+	// such as archiving old completed tasks. It is not an operation of the
+	// user's: it cannot be undone and leaves the redo stack alone.
+	OpMaintenance = "maintenance"
+)
+
+// Batch is one user-visible operation: the consecutive events sharing a
+// batch id
+type Batch struct {
+	ID     int
+	Op     string
+	Desc   string
+	Time   time.Time
+	Target int
+	Events []Event
+}
+
+// Changes returns the changes of every event in the batch, in order
+func (b Batch) Changes() []Change {
+	var changes []Change
+	for _, e := range b.Events {
+		changes = append(changes, e.Changes...)
+	}
+	return changes
+}
+
+// Inverse returns the changes that revert the batch
+func (b Batch) Inverse() []Change {
+	changes := b.Changes()
+	inverse := make([]Change, 0, len(changes))
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		inverse = append(inverse, Change{Store: c.Store, TaskID: c.TaskID, Before: c.After, After: c.Before})
+	}
+	return inverse
+}
+
+// Batches groups events into batches, oldest first
+func Batches(events []Event) []Batch {
+	var batches []Batch
+	for _, e := range events {
+		if n := len(batches); n > 0 && batches[n-1].ID == e.Batch {
+			batches[n-1].Events = append(batches[n-1].Events, e)
+			continue
+		}
+		batches = append(batches, Batch{ID: e.Batch, Op: e.Op, Desc: e.Desc, Time: e.Time, Target: e.Target, Events: []Event{e}})
+	}
+	return batches
+}
+
+// History tracks which operations can be undone and redone, derived by
+// walking the journal: every operation can be undone, most recent first;
+// undone operations can be redone until a new operation is made. Journal
+// starts and replays reset the history; maintenance is passed over.
+type History struct {
+	done   []Batch
+	undone []Batch
+}
+
+// NewHistory builds the undo/redo history from the journal's events
+func NewHistory(events []Event) *History {
+	h := &History{}
+	byID := make(map[int]Batch)
+	for _, b := range Batches(events) {
+		byID[b.ID] = b
+		switch b.Op {
+		case OpInit, OpReplay:
+			h.done, h.undone = nil, nil
+		case OpMaintenance:
+			continue
+		case OpUndo:
+			if target, ok := byID[b.Target]; ok {
+				h.MarkUndone(target)
+			}
+		case OpRedo:
+			if target, ok := byID[b.Target]; ok {
+				h.MarkRedone(target)
+			}
+		default:
+			h.done = append(h.done, b)
+			h.undone = nil
+		}
+	}
+	return h
+}
+
+// NextUndo returns the operation the next undo would revert
+func (h *History) NextUndo() (Batch, bool) {
+	if len(h.done) == 0 {
+		return Batch{}, false
+	}
+	return h.done[len(h.done)-1], true
+}
+
+// NextRedo returns the operation the next redo would re-apply
+func (h *History) NextRedo() (Batch, bool) {
+	if len(h.undone) == 0 {
+		return Batch{}, false
+	}
+	return h.undone[len(h.undone)-1], true
+}
+
+// MarkUndone moves b from the undo stack to the redo stack
+func (h *History) MarkUndone(b Batch) {
+	h.done = removeBatch(h.done, b.ID)
+	h.undone = append(h.undone, b)
+}
+
+// MarkRedone moves b from the redo stack back to the undo stack
+func (h *History) MarkRedone(b Batch) {
+	h.undone = removeBatch(h.undone, b.ID)
+	h.done = append(h.done, b)
+}
+
+// removeBatch returns batches without the batch with the given id
+func removeBatch(batches []Batch, id int) []Batch {
+	for i := len(batches) - 1; i >= 0; i-- {
+		if batches[i].ID == id {
+			return append(batches[:i:i], batches[i+1:]...)
+		}
+	}
+	return batches
+}
+
+// Describe renders a change for display, e.g. `task 3 "Buy milk" removed from active`
+func (c Change) Describe() string {
+	name := ""
+	switch {
+	case c.After != nil:
+		name = c.After.TaskName
+	case c.Before != nil:
+		name = c.Before.TaskName
+	}
+
+	switch {
+	case c.Before == nil:
+		return fmt.Sprintf("task %d %q added to %s", c.TaskID, name, c.Store)
+	case c.After == nil:
+		return fmt.Sprintf("task %d %q removed from %s", c.TaskID, name, c.Store)
+	case c.Before.Status != c.After.Status:
+		return fmt.Sprintf("task %d %q in %s: status %s -> %s", c.TaskID, name, c.Store, c.Before.Status, c.After.Status)
+	default:
+		return fmt.Sprintf("task %d %q changed in %s", c.TaskID, name, c.Store)
+	}
+}
@@ -0,0 +1,236 @@
+// Package journal keeps an append-only log of every change made to the
+// task lists, so the lists can be audited and rebuilt from scratch.
+//
+// Each Save through a journaling Store appends one Event holding the
+// before/after state of every task it changed. Saves belonging to the same
+// user-visible operation (e.g. a delete, which writes both the backup and
+// the active list) share a Batch id.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/encryption"
+)
+
+// Task list names used in Change.Store
+const (
+	StoreActive = "active"
+	StoreBackup = "backup"
+)
+
+// Event is one journal entry: a single save and the task changes it made
+type Event struct {
+	Seq     int       `json:"seq"`
+	Time    time.Time `json:"time"`
+	Op      string    `json:"op"`
+	Desc    string    `json:"desc,omitempty"`
+	Batch   int       `json:"batch"`
+	Target  int       `json:"target,omitempty"` // batch undone or redone by an undo/redo event
+	Changes []Change  `json:"changes"`
+}
+
+// Change records one task being added (Before is nil), removed (After is
+// nil) or modified in one of the task lists
+type Change struct {
+	Store  string           `json:"store"`
+	TaskID int              `json:"taskId"`
+	Before *domain.TodoItem `json:"before,omitempty"`
+	After  *domain.TodoItem `json:"after,omitempty"`
+}
+
+// Journal is an append-only file of Events, one JSON object per line.
+// With a Cipher, each line is instead the base64 encoding of the sealed
+// JSON; plaintext and encrypted lines can be read side by side.
+type Journal struct {
+	Path   string
+	Cipher *encryption.Cipher
+
+	lastSeq int
+	scanned bool
+}
+
+// New returns the journal stored at path. The file is created on first append.
+func New(path string) *Journal {
+	return &Journal{Path: path}
+}
+
+// Exists reports whether the journal file exists and holds at least one event
+func (j *Journal) Exists() bool {
+	info, err := os.Stat(j.Path)
+	return err == nil && info.Size() > 0
+}
+
+// Read returns every event in the journal, oldest first. A missing journal
+// is empty. If the file ends in a damaged entry (e.g. after a crash while
+// appending), the events before it are returned together with the error.
+func (j *Journal) Read() ([]Event, error) {
+	file, err := os.Open(j.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	var events []Event
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return events, fmt.Errorf("failed to read journal: %w", err)
+		}
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			e, decodeErr := j.decode(line)
+			if decodeErr != nil {
+				return events, fmt.Errorf("failed to read journal entry after seq %d: %w", j.seqOf(events), decodeErr)
+			}
+			events = append(events, e)
+		}
+		if err != nil {
+			return events, nil
+		}
+	}
+}
+
+// decode parses one journal line, decrypting it if needed
+func (j *Journal) decode(line []byte) (Event, error) {
+	var e Event
+	if line[0] != '{' {
+		sealed, err := base64.StdEncoding.DecodeString(string(line))
+		if err != nil {
+			return e, err
+		}
+		if line, err = encryption.Open(j.Cipher, sealed); err != nil {
+			return e, err
+		}
+	}
+	err := json.Unmarshal(line, &e)
+	return e, err
+}
+
+// encode renders e as one journal line, encrypting it if the journal has a Cipher
+func (j *Journal) encode(e Event) ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal journal event: %w", err)
+	}
+	if j.Cipher != nil {
+		sealed, err := j.Cipher.Encrypt(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt journal event: %w", err)
+		}
+		data = []byte(base64.StdEncoding.EncodeToString(sealed))
+	}
+	return append(data, '\n'), nil
+}
+
+// Rewrite reads every event with the journal's Cipher and writes them back
+// sealed with to (or as plaintext when to is nil), replacing the file
+// atomically; to becomes the journal's Cipher. Callers must hold the store lock.
+func (j *Journal) Rewrite(to *encryption.Cipher) error {
+	events, err := j.Read()
+	if err != nil {
+		return err
+	}
+	j.Cipher = to
+	if events == nil {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, e := range events {
+		line, err := j.encode(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+	}
+
+	tmp := j.Path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), j.fileMode()); err != nil {
+		return fmt.Errorf("failed to rewrite journal: %w", err)
+	}
+	if err := os.Chmod(tmp, j.fileMode()); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to rewrite journal: %w", err)
+	}
+	if err := os.Rename(tmp, j.Path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to rewrite journal: %w", err)
+	}
+	return nil
+}
+
+// fileMode returns the permissions for a newly created journal
+func (j *Journal) fileMode() os.FileMode {
+	if j.Cipher != nil {
+		return 0600
+	}
+	return 0644
+}
+
+// Append writes e to the end of the journal, assigning its Seq and Time
+// (when unset) and returning the stored event. Callers must hold the store
+// lock so sequence numbers stay unique across processes.
+func (j *Journal) Append(e Event) (Event, error) {
+	if !j.scanned {
+		events, err := j.Read()
+		if err != nil {
+			// Appending after a damaged entry would make it unreadable for good
+			return e, err
+		}
+		j.lastSeq = j.seqOf(events)
+		j.scanned = true
+	}
+
+	j.lastSeq++
+	e.Seq = j.lastSeq
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Batch == 0 {
+		e.Batch = e.Seq
+	}
+
+	data, err := j.encode(e)
+	if err != nil {
+		return e, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.Path), 0755); err != nil {
+		return e, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	file, err := os.OpenFile(j.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, j.fileMode())
+	if err != nil {
+		return e, fmt.Errorf("failed to open journal: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return e, fmt.Errorf("failed to append to journal: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return e, fmt.Errorf("failed to sync journal: %w", err)
+	}
+	return e, file.Close()
+}
+
+// seqOf returns the sequence number of the last event in events
+func (j *Journal) seqOf(events []Event) int {
+	if len(events) == 0 {
+		return 0
+	}
+	return events[len(events)-1].Seq
+}
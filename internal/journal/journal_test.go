@@ -0,0 +1,245 @@
+package journal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/encryption"
+	"github.com/SongRunqi/go-todo/internal/storage"
+)
+
+func newTestStore(t *testing.T, active []domain.TodoItem) (*Store, *storage.MemoryTodoStore) {
+	t.Helper()
+	mem := storage.NewMemoryStore()
+	if err := mem.Save(active, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	return NewStore(mem, New(filepath.Join(t.TempDir(), "journal.jsonl"))), mem
+}
+
+func TestDiff(t *testing.T) {
+	before := []domain.TodoItem{
+		{TaskID: 1, TaskName: "Keep"},
+		{TaskID: 2, TaskName: "Change"},
+		{TaskID: 3, TaskName: "Remove"},
+	}
+	after := []domain.TodoItem{
+		{TaskID: 1, TaskName: "Keep"},
+		{TaskID: 2, TaskName: "Changed"},
+		{TaskID: 4, TaskName: "Add"},
+	}
+
+	changes := Diff(StoreActive, before, after)
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %+v", changes)
+	}
+	if c := changes[0]; c.TaskID != 3 || c.Before == nil || c.After != nil {
+		t.Errorf("Expected removal of task 3 first, got %+v", c)
+	}
+	if c := changes[1]; c.TaskID != 2 || c.Before.TaskName != "Change" || c.After.TaskName != "Changed" {
+		t.Errorf("Expected modification of task 2, got %+v", c)
+	}
+	if c := changes[2]; c.TaskID != 4 || c.Before != nil || c.After == nil {
+		t.Errorf("Expected addition of task 4, got %+v", c)
+	}
+}
+
+func TestStore_JournalsSavesAndReplays(t *testing.T) {
+	store, _ := newTestStore(t, []domain.TodoItem{{TaskID: 1, TaskName: "Existing", Status: "pending"}})
+
+	todos, err := store.Load(false)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// A delete writes both lists as a single operation
+	store.BeginOperation("delete", "Delete task 1: Existing")
+	deleted := todos[0]
+	deleted.Status = "deleted"
+	if err := store.Save([]domain.TodoItem{deleted}, true); err != nil {
+		t.Fatalf("Save backup failed: %v", err)
+	}
+	if err := store.Save([]domain.TodoItem{}, false); err != nil {
+		t.Fatalf("Save active failed: %v", err)
+	}
+
+	store.BeginOperation("create", "Create task 2: New")
+	if err := store.Save([]domain.TodoItem{{TaskID: 2, TaskName: "New", Status: "pending"}}, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	events, err := store.Journal().Read()
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	ops := make([]string, 0, len(events))
+	for _, e := range events {
+		ops = append(ops, e.Op)
+	}
+	if len(events) != 4 || events[0].Op != "init" || events[1].Op != "delete" || events[3].Op != "create" {
+		t.Fatalf("Unexpected journal operations %v", ops)
+	}
+	if events[1].Batch != events[2].Batch || events[3].Batch == events[1].Batch {
+		t.Errorf("Expected the two delete saves to share a batch, got batches %d, %d, %d", events[1].Batch, events[2].Batch, events[3].Batch)
+	}
+
+	state, err := Replay(events, 0)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(state.Active) != 1 || state.Active[0].TaskID != 2 {
+		t.Errorf("Expected task 2 active after replay, got %+v", state.Active)
+	}
+	if len(state.Backup) != 1 || state.Backup[0].Status != "deleted" {
+		t.Errorf("Expected deleted task 1 in backup after replay, got %+v", state.Backup)
+	}
+
+	// Replaying only the baseline restores the original list
+	state, err = Replay(events, events[0].Seq)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(state.Active) != 1 || state.Active[0].TaskName != "Existing" || len(state.Backup) != 0 {
+		t.Errorf("Expected the baseline state, got %+v", state)
+	}
+}
+
+func TestStore_InPlaceEditsAreJournaled(t *testing.T) {
+	store, _ := newTestStore(t, []domain.TodoItem{{
+		TaskID:            1,
+		IsRecurring:       true,
+		OccurrenceHistory: []domain.OccurrenceRecord{{Status: "pending"}},
+	}})
+
+	todos, err := store.Load(false)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	// Complete edits occurrence records through a pointer into the slice
+	todos[0].OccurrenceHistory[0].Status = "completed"
+	if err := store.Save(todos, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	events, _ := store.Journal().Read()
+	last := events[len(events)-1]
+	if len(last.Changes) != 1 || last.Changes[0].Before.OccurrenceHistory[0].Status != "pending" {
+		t.Errorf("Expected the occurrence change to be journaled, got %+v", last)
+	}
+}
+
+func TestJournal_DamagedTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j := New(path)
+	if _, err := j.Append(Event{Op: "save"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	f.WriteString(`{"seq": 2, "op": "sa`)
+	f.Close()
+
+	events, err := New(path).Read()
+	if err == nil {
+		t.Error("Expected an error for the damaged entry")
+	}
+	if len(events) != 1 || events[0].Seq != 1 {
+		t.Errorf("Expected the intact entry to be returned, got %+v", events)
+	}
+	if _, err := New(path).Append(Event{Op: "save"}); err == nil {
+		t.Error("Appending after a damaged entry should fail")
+	}
+}
+
+func TestHistory_UndoRedo(t *testing.T) {
+	events := []Event{
+		{Seq: 1, Batch: 1, Op: OpInit},
+		{Seq: 2, Batch: 2, Op: "create"},
+		{Seq: 3, Batch: 3, Op: "delete"},
+		{Seq: 4, Batch: 3, Op: "delete"},
+		{Seq: 5, Batch: 5, Op: OpUndo, Target: 3},
+	}
+
+	h := NewHistory(events)
+	if b, ok := h.NextUndo(); !ok || b.ID != 2 {
+		t.Errorf("Expected batch 2 to be next to undo, got %+v", b)
+	}
+
+	// Maintenance can neither be undone nor discard the redo stack
+	h = NewHistory(append(events, Event{Seq: 6, Batch: 6, Op: OpMaintenance}))
+	if b, ok := h.NextUndo(); !ok || b.ID != 2 {
+		t.Errorf("Expected batch 2 to stay next to undo after maintenance, got %+v", b)
+	}
+	b, ok := h.NextRedo()
+	if !ok || b.ID != 3 || len(b.Events) != 2 {
+		t.Fatalf("Expected both events of batch 3 to be redoable, got %+v", b)
+	}
+
+	// A new operation discards the redo stack
+	events = append(events, Event{Seq: 6, Batch: 6, Op: "update"})
+	h = NewHistory(events)
+	if _, ok := h.NextRedo(); ok {
+		t.Error("Expected nothing to redo after a new operation")
+	}
+
+	// Replays reset the history
+	events = append(events, Event{Seq: 7, Batch: 7, Op: OpReplay})
+	h = NewHistory(events)
+	if _, ok := h.NextUndo(); ok {
+		t.Error("Expected nothing to undo after a replay")
+	}
+}
+
+func TestState_ApplyStrictRejectsStaleChanges(t *testing.T) {
+	old := domain.TodoItem{TaskID: 1, TaskName: "Old"}
+	state := State{Active: []domain.TodoItem{{TaskID: 1, TaskName: "Edited since"}}}
+
+	err := state.ApplyStrict([]Change{{Store: StoreActive, TaskID: 1, Before: &old, After: nil}})
+	if err == nil {
+		t.Fatal("Expected an error for a task that changed since")
+	}
+	if len(state.Active) != 1 {
+		t.Errorf("State must be unchanged after a failed apply, got %+v", state.Active)
+	}
+}
+
+func TestJournal_EncryptedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j := New(path)
+	if _, err := j.Append(Event{Op: "create", Desc: "Create task 1: Plain"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	// Encrypting rewrites the existing entries and seals new ones
+	c := encryption.New("secret")
+	if err := j.Rewrite(c); err != nil {
+		t.Fatalf("Rewrite failed: %v", err)
+	}
+	if _, err := j.Append(Event{Op: "create", Desc: "Create task 2: Sealed"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "Create task") {
+		t.Fatalf("Expected no plaintext in the journal, got %s", data)
+	}
+
+	if _, err := New(path).Read(); !errors.Is(err, encryption.ErrEncrypted) {
+		t.Errorf("Expected ErrEncrypted without a cipher, got %v", err)
+	}
+	reader := New(path)
+	reader.Cipher = encryption.New("secret")
+	events, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(events) != 2 || events[0].Desc != "Create task 1: Plain" || events[1].Seq != 2 {
+		t.Errorf("Expected both entries back in order, got %+v", events)
+	}
+}
@@ -0,0 +1,161 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/logger"
+)
+
+// Store wraps a TodoStore and appends an Event to the journal for every
+// Save that changes a task list
+type Store struct {
+	inner   domain.TodoStore
+	journal *Journal
+
+	// loaded holds a private copy of each list as of our last Load or Save,
+	// the "before" side of the next diff
+	loaded map[string][]domain.TodoItem
+
+	op     string
+	desc   string
+	target int
+	batch  int
+}
+
+// NewStore returns a journaling wrapper around inner
+func NewStore(inner domain.TodoStore, j *Journal) *Store {
+	return &Store{
+		inner:   inner,
+		journal: j,
+		loaded:  make(map[string][]domain.TodoItem),
+	}
+}
+
+// Unwrap returns the wrapped store
+func (s *Store) Unwrap() domain.TodoStore {
+	return s.inner
+}
+
+// Journal returns the journal this store appends to
+func (s *Store) Journal() *Journal {
+	return s.journal
+}
+
+// BeginOperation labels the saves that follow, up to the next call, as one
+// operation sharing a batch in the journal
+func (s *Store) BeginOperation(op, desc string) {
+	s.BeginReversal(op, desc, 0)
+}
+
+// BeginReversal is BeginOperation for undo and redo, which also record the
+// batch they reverse or re-apply. The label is passed on to the wrapped
+// store if it records operations too.
+func (s *Store) BeginReversal(op, desc string, target int) {
+	s.op = op
+	s.desc = desc
+	s.target = target
+	s.batch = 0
+	if recorder, ok := domain.As[domain.OperationRecorder](s.inner); ok {
+		recorder.BeginOperation(op, desc)
+	}
+}
+
+// Load loads todos from the wrapped store. The first load into an empty
+// journal records the current content of both lists as an "init" event, so
+// replaying the journal reproduces tasks that predate it.
+func (s *Store) Load(backup bool) ([]domain.TodoItem, error) {
+	todos, err := s.inner.Load(backup)
+	if err != nil {
+		return todos, err
+	}
+	s.loaded[listName(backup)] = clone(todos)
+
+	if !s.journal.Exists() {
+		if err := s.recordBaseline(); err != nil {
+			logger.Warnf("Failed to start the journal: %v", err)
+		}
+	}
+	return todos, nil
+}
+
+// Save saves todos to the wrapped store and journals what changed. A failure
+// to write the journal is logged but does not fail the save, which has
+// already happened by then.
+func (s *Store) Save(todos []domain.TodoItem, backup bool) error {
+	name := listName(backup)
+	before, ok := s.loaded[name]
+	if !ok {
+		var err error
+		if before, err = s.inner.Load(backup); err != nil {
+			logger.Warnf("Failed to load %s list before saving; the journal entry will show every task as added: %v", name, err)
+			before = nil
+		}
+	}
+
+	if err := s.inner.Save(todos, backup); err != nil {
+		return err
+	}
+	s.loaded[name] = clone(todos)
+
+	changes := Diff(name, before, todos)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	op := s.op
+	if op == "" {
+		op = "save"
+	}
+	event, err := s.journal.Append(Event{Op: op, Desc: s.desc, Batch: s.batch, Target: s.target, Changes: changes})
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to record journal entry")
+		return nil
+	}
+	if s.op != "" {
+		s.batch = event.Batch
+	}
+	logger.Debugf("Journaled %s (seq %d, %d changes)", op, event.Seq, len(changes))
+	return nil
+}
+
+// recordBaseline writes an "init" event holding both lists as they are now
+func (s *Store) recordBaseline() error {
+	var changes []Change
+	for _, backup := range []bool{false, true} {
+		name := listName(backup)
+		todos, ok := s.loaded[name]
+		if !ok {
+			loaded, err := s.inner.Load(backup)
+			if err != nil {
+				return fmt.Errorf("failed to load %s list: %w", name, err)
+			}
+			todos = clone(loaded)
+			s.loaded[name] = todos
+		}
+		changes = append(changes, Diff(name, nil, todos)...)
+	}
+
+	_, err := s.journal.Append(Event{Op: "init", Desc: "Journal started", Changes: changes})
+	return err
+}
+
+// listName returns the journal name of the active or backup list
+func listName(backup bool) string {
+	if backup {
+		return StoreBackup
+	}
+	return StoreActive
+}
+
+// clone deep-copies todos through their JSON form, so later in-place edits by
+// the caller (e.g. to occurrence records) cannot leak into our copy
+func clone(todos []domain.TodoItem) []domain.TodoItem {
+	result := make([]domain.TodoItem, 0, len(todos))
+	data, err := json.Marshal(todos)
+	if err == nil && json.Unmarshal(data, &result) == nil {
+		return result
+	}
+	return append(result, todos...)
+}
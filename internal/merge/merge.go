@@ -0,0 +1,535 @@
+// Package merge reconciles two diverged copies of a task list against their
+// common ancestor.
+//
+// Tasks are matched by UUID, or by TaskID when they have none, and merged
+// field by field, with each device keeping its own IDs: a field changed on
+// one side only takes that side's value, a field changed identically on both
+// sides is kept, and a field changed differently on both sides is a
+// conflict handed to a Resolver. A few fields have merge rules of their own:
+// occurrenceHistory is the union of both sides keyed by scheduledTime,
+// completionCount adds up both sides' increments, the endTime of a recurring
+// task moves to the later of the two new times, and of two UUIDs the lower
+// one wins so that every device settles on the same one.
+package merge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+)
+
+// ErrUnresolved is returned when a conflict has no Resolver to settle it
+var ErrUnresolved = errors.New("unresolved merge conflict")
+
+// Choice is the side a Resolver picks for a conflict
+type Choice int
+
+const (
+	KeepLocal Choice = iota
+	KeepRemote
+)
+
+// Conflict is a change made differently on both sides. Field is empty when
+// the whole task conflicts: one side deleted it while the other changed it,
+// in which case the deleting side's value is nil.
+type Conflict struct {
+	List     string
+	TaskID   int
+	TaskName string
+	Field    string
+	Base     json.RawMessage
+	Local    json.RawMessage
+	Remote   json.RawMessage
+}
+
+func (c Conflict) String() string {
+	if c.Field == "" {
+		return fmt.Sprintf("task %d %q in %s was deleted on one side and changed on the other", c.TaskID, c.TaskName, c.List)
+	}
+	return fmt.Sprintf("task %d %q in %s: %s changed on both sides", c.TaskID, c.TaskName, c.List, c.Field)
+}
+
+// Resolver settles a conflict, or returns an error to abort the merge
+type Resolver func(Conflict) (Choice, error)
+
+// Prefer returns a Resolver that always picks the same side
+func Prefer(choice Choice) Resolver {
+	return func(Conflict) (Choice, error) {
+		return choice, nil
+	}
+}
+
+// Renumbered records a task added on the remote side under an ID that the
+// local side used for a different new task; the remote task got a new ID
+type Renumbered struct {
+	List     string
+	From, To int
+	TaskName string
+}
+
+// Result is the outcome of merging one list
+type Result struct {
+	Tasks      []domain.TodoItem
+	Conflicts  []Conflict // conflicts settled by the Resolver
+	Renumbered []Renumbered
+}
+
+// Merge reconciles the local and remote versions of the list named list
+// against base, their common ancestor (nil when there is none, e.g. on a
+// first sync). The result keeps the local order, with tasks added remotely
+// appended in remote order. Tasks keep their local IDs; tasks added remotely
+// under an ID the local side already uses are renumbered with IDs from
+// newID, normally the local store's ID allocator, or with a nil newID the
+// next IDs after those of local and remote.
+func Merge(list string, base, local, remote []domain.TodoItem, resolve Resolver, newID func() (int, error)) (Result, error) {
+	result := Result{Tasks: make([]domain.TodoItem, 0, len(local))}
+	m := matchTasks(base, local, remote)
+
+	used := make(map[int]bool)
+	for _, l := range local {
+		used[l.TaskID] = true
+	}
+	for _, r := range remote {
+		used[r.TaskID] = true
+	}
+	// IDs in the result: every local task keeps its ID
+	taken := make(map[int]bool)
+	for _, l := range local {
+		taken[l.TaskID] = true
+	}
+	// addRemote adds a task from the remote side, renumbering it if its ID
+	// is taken locally by another task
+	addRemote := func(r domain.TodoItem) error {
+		if taken[r.TaskID] {
+			from := r.TaskID
+			id, err := freshID(used, newID)
+			if err != nil {
+				return err
+			}
+			r.TaskID = id
+			result.Renumbered = append(result.Renumbered, Renumbered{List: list, From: from, To: id, TaskName: r.TaskName})
+		}
+		taken[r.TaskID] = true
+		result.Tasks = append(result.Tasks, r)
+		return nil
+	}
+
+	for i, l := range local {
+		b, r := m.localBase[i], m.localRemote[i]
+
+		switch {
+		case r == nil && b == nil:
+			// Added locally
+			result.Tasks = append(result.Tasks, l)
+		case r == nil:
+			// Deleted remotely: fine unless we changed it meanwhile
+			if same(l, *b) {
+				continue
+			}
+			keep, err := settle(&result, resolve, Conflict{List: list, TaskID: l.TaskID, TaskName: l.TaskName, Base: raw(*b), Local: raw(l)})
+			if err != nil {
+				return Result{}, err
+			}
+			if keep == KeepLocal {
+				result.Tasks = append(result.Tasks, l)
+			}
+		default:
+			merged, err := mergeTask(&result, list, b, l, *r, resolve)
+			if err != nil {
+				return Result{}, err
+			}
+			result.Tasks = append(result.Tasks, merged)
+		}
+	}
+
+	for i, r := range remote {
+		if m.remoteMatched[i] {
+			// Merged above
+			continue
+		}
+		b := m.remoteBase[i]
+
+		switch {
+		case b == nil:
+			// Added remotely; a task without a UUID may be one an earlier sync
+			// added under another ID
+			if r.UUID == "" && containsEquivalent(result.Tasks, r) {
+				continue
+			}
+			if err := addRemote(r); err != nil {
+				return Result{}, err
+			}
+		default:
+			// Deleted locally: fine unless they changed it meanwhile
+			if same(r, *b) {
+				continue
+			}
+			keep, err := settle(&result, resolve, Conflict{List: list, TaskID: r.TaskID, TaskName: r.TaskName, Base: raw(*b), Remote: raw(r)})
+			if err != nil {
+				return Result{}, err
+			}
+			if keep == KeepRemote {
+				if err := addRemote(r); err != nil {
+					return Result{}, err
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// matching records which versions of a task in base, local and remote are
+// the same task, by index into local and remote; nil means none
+type matching struct {
+	localRemote   []*domain.TodoItem
+	localBase     []*domain.TodoItem
+	remoteBase    []*domain.TodoItem
+	remoteMatched []bool
+}
+
+// matchTasks pairs up the versions of each task in base, local and remote.
+// Tasks with a UUID are matched by it, since the same task can have
+// different IDs on two devices after a renumbering. Tasks the UUIDs leave
+// unmatched fall back to their TaskID: a local and a remote task under the
+// same ID are the same task if they have the same name, which covers tasks
+// that devices upgrading separately gave different UUIDs, or if one has no
+// UUID and they share an ancestor.
+func matchTasks(base, local, remote []domain.TodoItem) matching {
+	m := matching{
+		localRemote:   make([]*domain.TodoItem, len(local)),
+		localBase:     make([]*domain.TodoItem, len(local)),
+		remoteBase:    make([]*domain.TodoItem, len(remote)),
+		remoteMatched: make([]bool, len(remote)),
+	}
+	remoteByUUID := make(map[string]int)
+	remoteByID := make(map[int]int)
+	for j, r := range remote {
+		if r.UUID != "" {
+			remoteByUUID[r.UUID] = j
+		}
+		remoteByID[r.TaskID] = j
+	}
+	baseByUUID := make(map[string]*domain.TodoItem)
+	baseByID := make(map[int]*domain.TodoItem)
+	for i := range base {
+		if base[i].UUID != "" {
+			baseByUUID[base[i].UUID] = &base[i]
+		}
+		baseByID[base[i].TaskID] = &base[i]
+	}
+	// ancestor returns the base version of a task with the given versions
+	ancestor := func(versions ...*domain.TodoItem) *domain.TodoItem {
+		for _, t := range versions {
+			if t != nil && t.UUID != "" {
+				if b, ok := baseByUUID[t.UUID]; ok {
+					return b
+				}
+			}
+		}
+		for _, t := range versions {
+			if t == nil {
+				continue
+			}
+			if b, ok := baseByID[t.TaskID]; ok && (t.UUID == "" || b.UUID == "") {
+				return b
+			}
+		}
+		return nil
+	}
+	pair := func(i, j int) {
+		m.localRemote[i] = &remote[j]
+		m.remoteMatched[j] = true
+	}
+
+	for i, l := range local {
+		if j, ok := remoteByUUID[l.UUID]; ok && l.UUID != "" && !m.remoteMatched[j] {
+			pair(i, j)
+		}
+	}
+	for i := range local {
+		l := &local[i]
+		if m.localRemote[i] != nil {
+			continue
+		}
+		j, ok := remoteByID[l.TaskID]
+		if !ok || m.remoteMatched[j] {
+			continue
+		}
+		r := &remote[j]
+		if l.TaskName == r.TaskName || (l.UUID == "" || r.UUID == "") && ancestor(l, r) != nil {
+			pair(i, j)
+		}
+	}
+
+	for i := range local {
+		m.localBase[i] = ancestor(&local[i], m.localRemote[i])
+	}
+	for j := range remote {
+		if !m.remoteMatched[j] {
+			m.remoteBase[j] = ancestor(&remote[j])
+		}
+	}
+	return m
+}
+
+// mergeTask merges one task present on both sides, field by field
+func mergeTask(result *Result, list string, base *domain.TodoItem, local, remote domain.TodoItem, resolve Resolver) (domain.TodoItem, error) {
+	if same(local, remote) {
+		return local, nil
+	}
+	b := fields(base)
+	l := fields(&local)
+	r := fields(&remote)
+
+	merged := make(map[string]json.RawMessage)
+	for _, key := range keys(b, l, r) {
+		bv, lv, rv := b[key], l[key], r[key]
+		switch {
+		case key == "taskId":
+			// IDs are per device; the task keeps ours
+			set(merged, key, lv)
+		case bytes.Equal(lv, rv):
+			set(merged, key, lv)
+		case base != nil && bytes.Equal(lv, bv):
+			set(merged, key, rv)
+		case base != nil && bytes.Equal(rv, bv):
+			set(merged, key, lv)
+		default:
+			// Without an ancestor any difference is a conflict
+			value, ok := mergeField(key, bv, lv, rv, base != nil, local.IsRecurring || remote.IsRecurring)
+			if !ok {
+				choice, err := settle(result, resolve, Conflict{
+					List: list, TaskID: local.TaskID, TaskName: local.TaskName,
+					Field: key, Base: bv, Local: lv, Remote: rv,
+				})
+				if err != nil {
+					return domain.TodoItem{}, err
+				}
+				value = lv
+				if choice == KeepRemote {
+					value = rv
+				}
+			}
+			set(merged, key, value)
+		}
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return domain.TodoItem{}, fmt.Errorf("failed to merge task %d: %w", local.TaskID, err)
+	}
+	var task domain.TodoItem
+	if err := json.Unmarshal(data, &task); err != nil {
+		return domain.TodoItem{}, fmt.Errorf("failed to merge task %d: %w", local.TaskID, err)
+	}
+	return task, nil
+}
+
+// mergeField applies the field-specific rules for a field changed on both
+// sides; hasBase is false when the task has no common ancestor. It reports
+// false when the change is a real conflict.
+func mergeField(key string, base, local, remote json.RawMessage, hasBase, recurring bool) (json.RawMessage, bool) {
+	switch key {
+	case "uuid":
+		// Both devices assigned a UUID to the same task; settle on the same one
+		if bytes.Compare(remote, local) < 0 {
+			return remote, true
+		}
+		return local, true
+	case "occurrenceHistory":
+		return mergeOccurrences(base, local, remote)
+	case "completionCount":
+		var b, l, r int
+		if json.Unmarshal(orNull(base), &b) != nil || json.Unmarshal(orNull(local), &l) != nil || json.Unmarshal(orNull(remote), &r) != nil {
+			return nil, false
+		}
+		if !hasBase {
+			// Without an ancestor the increments are unknown
+			return raw(max(l, r)), true
+		}
+		return raw(b + (l - b) + (r - b)), true
+	case "endTime":
+		if !recurring {
+			return nil, false
+		}
+		var b, l, r time.Time
+		if json.Unmarshal(orNull(base), &b) != nil || json.Unmarshal(orNull(local), &l) != nil || json.Unmarshal(orNull(remote), &r) != nil {
+			return nil, false
+		}
+		// Both sides moved past different occurrences: the later one is next
+		if l.After(b) && r.After(b) {
+			if r.After(l) {
+				return remote, true
+			}
+			return local, true
+		}
+	}
+	return nil, false
+}
+
+// sameOccurrence reports whether two occurrence records are equal, taking
+// times in different locations to be equal when they are the same instant
+func sameOccurrence(a, b domain.OccurrenceRecord) bool {
+	return a.ScheduledTime.Equal(b.ScheduledTime) && a.Status == b.Status &&
+		a.CompletedAt.Equal(b.CompletedAt) && a.Notes == b.Notes && a.OriginalTime.Equal(b.OriginalTime)
+}
+
+// mergeOccurrences unions two occurrence histories by the time the series
+// scheduled each record for (originalTime for rescheduled ones), merging
+// records present on both sides three-way. Records changed differently on
+// both sides are a conflict.
+func mergeOccurrences(base, local, remote json.RawMessage) (json.RawMessage, bool) {
+	var b, l, r []domain.OccurrenceRecord
+	if json.Unmarshal(orNull(base), &b) != nil || json.Unmarshal(orNull(local), &l) != nil || json.Unmarshal(orNull(remote), &r) != nil {
+		return nil, false
+	}
+
+	key := func(o domain.OccurrenceRecord) int64 {
+		if !o.OriginalTime.IsZero() {
+			return o.OriginalTime.UnixNano()
+		}
+		return o.ScheduledTime.UnixNano()
+	}
+	baseByTime := make(map[int64]domain.OccurrenceRecord)
+	for _, o := range b {
+		baseByTime[key(o)] = o
+	}
+	remoteByTime := make(map[int64]domain.OccurrenceRecord)
+	for _, o := range r {
+		remoteByTime[key(o)] = o
+	}
+	localTimes := make(map[int64]bool)
+
+	merged := make([]domain.OccurrenceRecord, 0, len(l)+len(r))
+	for _, lo := range l {
+		localTimes[key(lo)] = true
+		ro, ok := remoteByTime[key(lo)]
+		if !ok || sameOccurrence(lo, ro) {
+			merged = append(merged, lo)
+			continue
+		}
+		bo, inBase := baseByTime[key(lo)]
+		switch {
+		case inBase && sameOccurrence(lo, bo):
+			merged = append(merged, ro)
+		case inBase && sameOccurrence(ro, bo):
+			merged = append(merged, lo)
+		default:
+			return nil, false
+		}
+	}
+	for _, ro := range r {
+		if !localTimes[key(ro)] {
+			merged = append(merged, ro)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].ScheduledTime.Before(merged[j].ScheduledTime)
+	})
+	return raw(merged), true
+}
+
+// settle asks resolve to pick a side for c and records the conflict
+func settle(result *Result, resolve Resolver, c Conflict) (Choice, error) {
+	if resolve == nil {
+		return KeepLocal, fmt.Errorf("%w: %s", ErrUnresolved, c)
+	}
+	choice, err := resolve(c)
+	if err != nil {
+		return KeepLocal, err
+	}
+	result.Conflicts = append(result.Conflicts, c)
+	return choice, nil
+}
+
+// fields returns the JSON fields of task, or none for a nil task
+func fields(task *domain.TodoItem) map[string]json.RawMessage {
+	m := make(map[string]json.RawMessage)
+	if task != nil {
+		data, _ := json.Marshal(task)
+		_ = json.Unmarshal(data, &m)
+	}
+	return m
+}
+
+func keys(maps ...map[string]json.RawMessage) []string {
+	seen := make(map[string]bool)
+	var all []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				all = append(all, k)
+			}
+		}
+	}
+	sort.Strings(all)
+	return all
+}
+
+// set stores value under key; a missing value leaves the field out
+func set(m map[string]json.RawMessage, key string, value json.RawMessage) {
+	if value != nil {
+		m[key] = value
+	}
+}
+
+func raw(v interface{}) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func orNull(data json.RawMessage) json.RawMessage {
+	if data == nil {
+		return json.RawMessage("null")
+	}
+	return data
+}
+
+// same reports whether two tasks have identical content
+func same(a, b domain.TodoItem) bool {
+	return bytes.Equal(raw(a), raw(b))
+}
+
+// containsEquivalent reports whether todos holds a task identical to task
+// apart from its ID
+func containsEquivalent(todos []domain.TodoItem, task domain.TodoItem) bool {
+	for _, t := range todos {
+		t.TaskID = task.TaskID
+		if same(t, task) {
+			return true
+		}
+	}
+	return false
+}
+
+// freshID returns an ID from newID that is not in used, and marks it used.
+// An allocator only knows the IDs of the local store, so IDs up to the
+// highest used one are passed over.
+func freshID(used map[int]bool, newID func() (int, error)) (int, error) {
+	id := nextID(used)
+	if newID != nil {
+		allocated, err := newID()
+		if err != nil {
+			return 0, fmt.Errorf("failed to allocate task ID: %w", err)
+		}
+		id = max(id, allocated)
+	}
+	used[id] = true
+	return id, nil
+}
+
+// nextID returns the lowest ID above every used one
+func nextID(used map[int]bool) int {
+	highest := 0
+	for id := range used {
+		highest = max(highest, id)
+	}
+	return highest + 1
+}
@@ -0,0 +1,228 @@
+package merge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+)
+
+func TestMerge_CombinesFieldsChangedOnEachSide(t *testing.T) {
+	base := []domain.TodoItem{{TaskID: 1, TaskName: "Report", Status: "pending", TaskDesc: "draft"}}
+	local := []domain.TodoItem{{TaskID: 1, TaskName: "Report", Status: "completed", TaskDesc: "draft"}}
+	remote := []domain.TodoItem{{TaskID: 1, TaskName: "Report", Status: "pending", TaskDesc: "final draft"}}
+
+	result, err := Merge("active", base, local, remote, nil, nil)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(result.Tasks) != 1 || result.Tasks[0].Status != "completed" || result.Tasks[0].TaskDesc != "final draft" {
+		t.Errorf("Expected both changes to be kept, got %+v", result.Tasks)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %+v", result.Conflicts)
+	}
+}
+
+func TestMerge_AddsAndDeletes(t *testing.T) {
+	base := []domain.TodoItem{{TaskID: 1, TaskName: "Old"}, {TaskID: 2, TaskName: "Shared"}}
+	local := []domain.TodoItem{{TaskID: 2, TaskName: "Shared"}, {TaskID: 3, TaskName: "Local"}}
+	remote := []domain.TodoItem{{TaskID: 1, TaskName: "Old"}, {TaskID: 2, TaskName: "Shared"}, {TaskID: 4, TaskName: "Remote"}}
+
+	result, err := Merge("active", base, local, remote, nil, nil)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	var ids []int
+	for _, task := range result.Tasks {
+		ids = append(ids, task.TaskID)
+	}
+	if len(ids) != 3 || ids[0] != 2 || ids[1] != 3 || ids[2] != 4 {
+		t.Errorf("Expected tasks 2, 3, 4, got %v", ids)
+	}
+}
+
+func TestMerge_RenumbersClashingNewTask(t *testing.T) {
+	local := []domain.TodoItem{{TaskID: 1, TaskName: "Shared"}, {TaskID: 2, TaskName: "Buy milk"}}
+	remote := []domain.TodoItem{{TaskID: 1, TaskName: "Shared"}, {TaskID: 2, TaskName: "Call mom"}}
+	base := []domain.TodoItem{{TaskID: 1, TaskName: "Shared"}}
+
+	result, err := Merge("active", base, local, remote, nil, nil)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(result.Tasks) != 3 || result.Tasks[2].TaskID != 3 || result.Tasks[2].TaskName != "Call mom" {
+		t.Fatalf("Expected the remote task to become #3, got %+v", result.Tasks)
+	}
+	if len(result.Renumbered) != 1 || result.Renumbered[0].From != 2 || result.Renumbered[0].To != 3 {
+		t.Errorf("Expected the renumbering to be reported, got %+v", result.Renumbered)
+	}
+
+	// The next sync sees the renumbered task as already present
+	again, err := Merge("active", remote, result.Tasks, remote, nil, nil)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(again.Tasks) != 3 || len(again.Renumbered) != 0 {
+		t.Errorf("Expected a stable result, got %+v", again)
+	}
+
+	// An allocator knows IDs the merge does not, such as those in backup
+	result, err = Merge("active", base, local, remote, nil, func() (int, error) { return 7, nil })
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(result.Renumbered) != 1 || result.Renumbered[0].To != 7 || result.Tasks[2].TaskID != 7 {
+		t.Errorf("Expected the remote task to get the allocated ID 7, got %+v", result.Tasks)
+	}
+}
+
+func TestMerge_Conflicts(t *testing.T) {
+	base := []domain.TodoItem{{TaskID: 1, TaskName: "Report", TaskDesc: "draft"}, {TaskID: 2, TaskName: "Gone"}}
+	local := []domain.TodoItem{{TaskID: 1, TaskName: "Report", TaskDesc: "mine"}, {TaskID: 2, TaskName: "Gone", TaskDesc: "edited"}}
+	remote := []domain.TodoItem{{TaskID: 1, TaskName: "Report", TaskDesc: "theirs"}}
+
+	if _, err := Merge("active", base, local, remote, nil, nil); !errors.Is(err, ErrUnresolved) {
+		t.Fatalf("Expected ErrUnresolved without a resolver, got %v", err)
+	}
+
+	result, err := Merge("active", base, local, remote, Prefer(KeepRemote), nil)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(result.Tasks) != 1 || result.Tasks[0].TaskDesc != "theirs" {
+		t.Errorf("Expected the remote side to win, got %+v", result.Tasks)
+	}
+	if len(result.Conflicts) != 2 || result.Conflicts[0].Field != "taskDesc" || result.Conflicts[1].Field != "" {
+		t.Errorf("Expected a field and a delete conflict, got %+v", result.Conflicts)
+	}
+}
+
+func TestMerge_RecurringTask(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 9, 0, 0, 0, time.UTC) }
+	base := []domain.TodoItem{{
+		TaskID: 1, TaskName: "Stretch", IsRecurring: true, EndTime: day(1), CompletionCount: 2,
+		OccurrenceHistory: []domain.OccurrenceRecord{{ScheduledTime: day(1), Status: "pending"}},
+	}}
+	local := []domain.TodoItem{{
+		TaskID: 1, TaskName: "Stretch", IsRecurring: true, EndTime: day(2), CompletionCount: 3,
+		OccurrenceHistory: []domain.OccurrenceRecord{{ScheduledTime: day(1), Status: "completed", CompletedAt: day(1)}},
+	}}
+	remote := []domain.TodoItem{{
+		TaskID: 1, TaskName: "Stretch", IsRecurring: true, EndTime: day(3), CompletionCount: 4,
+		OccurrenceHistory: []domain.OccurrenceRecord{
+			{ScheduledTime: day(1), Status: "pending"},
+			{ScheduledTime: day(2), Status: "completed", CompletedAt: day(2)},
+		},
+	}}
+
+	result, err := Merge("active", base, local, remote, nil, nil)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	task := result.Tasks[0]
+	if task.CompletionCount != 5 {
+		t.Errorf("Expected both increments to add up to 5, got %d", task.CompletionCount)
+	}
+	if !task.EndTime.Equal(day(3)) {
+		t.Errorf("Expected the later end time, got %v", task.EndTime)
+	}
+	if len(task.OccurrenceHistory) != 2 || task.OccurrenceHistory[0].Status != "completed" || task.OccurrenceHistory[1].Status != "completed" {
+		t.Errorf("Expected the union of both histories, got %+v", task.OccurrenceHistory)
+	}
+}
+
+func TestMerge_OccurrenceTimesInOtherZones(t *testing.T) {
+	day := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	history := func(at time.Time, status string) []domain.OccurrenceRecord {
+		return []domain.OccurrenceRecord{{ScheduledTime: at, Status: status}}
+	}
+	// The local side wrote the same instant with another offset
+	base := []domain.TodoItem{{TaskID: 1, TaskName: "Stretch", IsRecurring: true, OccurrenceHistory: history(day, "pending")}}
+	local := []domain.TodoItem{{TaskID: 1, TaskName: "Stretch", IsRecurring: true,
+		OccurrenceHistory: history(day.In(time.FixedZone("CST", 8*60*60)), "pending")}}
+	remote := []domain.TodoItem{{TaskID: 1, TaskName: "Stretch", IsRecurring: true, OccurrenceHistory: history(day, "completed")}}
+
+	result, err := Merge("active", base, local, remote, nil, nil)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if h := result.Tasks[0].OccurrenceHistory; len(h) != 1 || h[0].Status != "completed" {
+		t.Errorf("Expected the remote completion, got %+v", h)
+	}
+}
+
+func TestMerge_RescheduledOccurrence(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 9, 0, 0, 0, time.UTC) }
+	history := []domain.OccurrenceRecord{{ScheduledTime: day(1), Status: "pending"}}
+	base := []domain.TodoItem{{TaskID: 1, TaskName: "Stretch", IsRecurring: true, OccurrenceHistory: history}}
+	local := []domain.TodoItem{{TaskID: 1, TaskName: "Stretch", IsRecurring: true, OccurrenceHistory: history}}
+	moved := domain.OccurrenceRecord{ScheduledTime: day(2), Status: "pending", OriginalTime: day(1)}
+	remote := []domain.TodoItem{{
+		TaskID: 1, TaskName: "Stretch", IsRecurring: true, OccurrenceHistory: []domain.OccurrenceRecord{moved},
+	}}
+
+	result, err := Merge("active", base, local, remote, nil, nil)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if got := result.Tasks[0].OccurrenceHistory; len(got) != 1 || got[0] != moved {
+		t.Errorf("Expected only the moved occurrence, got %+v", got)
+	}
+}
+
+func TestMerge_SettlesOnOneUUID(t *testing.T) {
+	local := []domain.TodoItem{{TaskID: 1, UUID: "b", TaskName: "Report"}}
+	remote := []domain.TodoItem{{TaskID: 1, UUID: "a", TaskName: "Report"}}
+
+	result, err := Merge("active", nil, local, remote, nil, nil)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(result.Tasks) != 1 || result.Tasks[0].UUID != "a" {
+		t.Errorf("Expected one task with the lower UUID, got %+v", result.Tasks)
+	}
+}
+
+func TestMerge_FollowsRenumberedTaskByUUID(t *testing.T) {
+	// Here foo is #6 after a clash renumbering; the other device has it as #5
+	base := []domain.TodoItem{{TaskID: 5, UUID: "uf", TaskName: "foo"}, {TaskID: 6, UUID: "ub", TaskName: "bar"}}
+	local := []domain.TodoItem{{TaskID: 5, UUID: "ub", TaskName: "bar"}, {TaskID: 6, UUID: "uf", TaskName: "foo"}}
+	remote := []domain.TodoItem{{TaskID: 5, UUID: "uf", TaskName: "foo", TaskDesc: "edited"}, {TaskID: 6, UUID: "ub", TaskName: "bar"}}
+
+	result, err := Merge("active", base, local, remote, nil, nil)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(result.Tasks) != 2 || result.Tasks[0].TaskDesc != "" || result.Tasks[1].TaskID != 6 || result.Tasks[1].TaskDesc != "edited" {
+		t.Errorf("Expected the edit on #6 only, got %+v", result.Tasks)
+	}
+	if len(result.Conflicts) != 0 || len(result.Renumbered) != 0 {
+		t.Errorf("Expected a clean merge, got %+v", result)
+	}
+}
+
+func TestMerge_FirstSyncConflictsPerField(t *testing.T) {
+	// The same task on both devices, edited on each before they first synced
+	local := []domain.TodoItem{{TaskID: 1, UUID: "u", TaskName: "Report", TaskDesc: "mine"}}
+	remote := []domain.TodoItem{{TaskID: 3, UUID: "u", TaskName: "Report", Project: "work"}}
+
+	if _, err := Merge("active", nil, local, remote, nil, nil); !errors.Is(err, ErrUnresolved) {
+		t.Fatalf("Expected ErrUnresolved without a resolver, got %v", err)
+	}
+
+	result, err := Merge("active", nil, local, remote, Prefer(KeepRemote), nil)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(result.Tasks) != 1 || result.Tasks[0].TaskID != 1 || result.Tasks[0].TaskDesc != "" || result.Tasks[0].Project != "work" {
+		t.Errorf("Expected one task #1 with the remote fields, got %+v", result.Tasks)
+	}
+	if len(result.Conflicts) != 2 || result.Conflicts[0].Field != "project" || result.Conflicts[1].Field != "taskDesc" {
+		t.Errorf("Expected a conflict per field, got %+v", result.Conflicts)
+	}
+	if len(result.Renumbered) != 0 {
+		t.Errorf("Expected no renumbering, got %+v", result.Renumbered)
+	}
+}
@@ -0,0 +1,90 @@
+// Package migration upgrades task lists written by older versions of todo.
+//
+// Stored task lists carry a schema version. Each registered Migration
+// upgrades a list by exactly one version, and Run applies every migration
+// the list has not seen yet, in order.
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/logger"
+)
+
+// LegacyVersion is the version of task files written before versioning
+// existed, which hold a bare JSON array of tasks
+const LegacyVersion = 1
+
+// ErrTooNew is returned for task lists written by a newer version of todo
+var ErrTooNew = errors.New("task list was written by a newer version of todo")
+
+// Migration upgrades a task list from Version-1 to Version
+type Migration struct {
+	Version     int
+	Description string
+	Apply       func(todos []domain.TodoItem) ([]domain.TodoItem, error)
+}
+
+var registry []Migration
+
+// Register adds m to the registry, which is kept in version order, so
+// migrations can register in any order. Registering a version twice, or one
+// not above LegacyVersion, panics.
+func Register(m Migration) {
+	if m.Version <= LegacyVersion {
+		panic(fmt.Sprintf("migration: %q registered as version %d, which is not above %d", m.Description, m.Version, LegacyVersion))
+	}
+	i, found := slices.BinarySearchFunc(registry, m.Version, byVersion)
+	if found {
+		panic(fmt.Sprintf("migration: %q registered as version %d, already taken by %q", m.Description, m.Version, registry[i].Description))
+	}
+	registry = slices.Insert(registry, i, m)
+}
+
+// byVersion compares a migration with a version, for searching the registry
+func byVersion(m Migration, version int) int {
+	return m.Version - version
+}
+
+// Latest returns the schema version produced by running every migration
+func Latest() int {
+	if len(registry) == 0 {
+		return LegacyVersion
+	}
+	return registry[len(registry)-1].Version
+}
+
+// Pending returns the migrations a task list at version still needs
+func Pending(version int) []Migration {
+	if version < LegacyVersion {
+		version = LegacyVersion
+	}
+	i, _ := slices.BinarySearchFunc(registry, version+1, byVersion)
+	return registry[i:]
+}
+
+// Run upgrades todos from version to Latest. It fails with ErrTooNew if
+// version is newer than this build understands.
+func Run(todos []domain.TodoItem, version int) ([]domain.TodoItem, error) {
+	if version > Latest() {
+		return nil, fmt.Errorf("%w (version %d, this build supports up to %d); please upgrade todo", ErrTooNew, version, Latest())
+	}
+
+	version = max(version, LegacyVersion)
+	for _, m := range Pending(version) {
+		if m.Version != version+1 {
+			return nil, fmt.Errorf("failed to migrate tasks from version %d: no migration to version %d", version, version+1)
+		}
+		version = m.Version
+		logger.Infof("Migrating tasks to version %d: %s", m.Version, m.Description)
+		migrated, err := m.Apply(todos)
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate tasks to version %d (%s): %w", m.Version, m.Description, err)
+		}
+		todos = migrated
+	}
+	return todos, nil
+}
@@ -0,0 +1,164 @@
+package migration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+)
+
+func TestRun_UpgradesLegacyToLatest(t *testing.T) {
+	if Latest() < 2 {
+		t.Fatalf("Expected at least one registered migration, Latest() = %d", Latest())
+	}
+	if n := len(Pending(LegacyVersion)); n != Latest()-LegacyVersion {
+		t.Errorf("Expected %d pending migrations for a legacy list, got %d", Latest()-LegacyVersion, n)
+	}
+	if n := len(Pending(Latest())); n != 0 {
+		t.Errorf("Expected no pending migrations at the latest version, got %d", n)
+	}
+
+	todos := []domain.TodoItem{{TaskID: 1, TaskName: "Plain task", Status: "pending"}}
+	migrated, err := Run(todos, LegacyVersion)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(migrated) != 1 || migrated[0].TaskName != "Plain task" {
+		t.Errorf("Non-recurring tasks must pass through unchanged, got %+v", migrated)
+	}
+}
+
+func TestRegister_KeepsVersionOrder(t *testing.T) {
+	saved := registry
+	t.Cleanup(func() { registry = saved })
+	registry = nil
+
+	noop := func(todos []domain.TodoItem) ([]domain.TodoItem, error) { return todos, nil }
+	for _, version := range []int{4, 2, 3} {
+		Register(Migration{Version: version, Description: "noop", Apply: noop})
+	}
+	if Latest() != 4 {
+		t.Errorf("Expected version 4 to be the latest, got %d", Latest())
+	}
+	pending := Pending(2)
+	if len(pending) != 2 || pending[0].Version != 3 || pending[1].Version != 4 {
+		t.Errorf("Expected versions 3 and 4 pending, got %+v", pending)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a version twice to panic")
+		}
+	}()
+	Register(Migration{Version: 3, Description: "again", Apply: noop})
+}
+
+func TestRun_RejectsMissingVersion(t *testing.T) {
+	saved := registry
+	t.Cleanup(func() { registry = saved })
+	registry = nil
+
+	Register(Migration{Version: 3, Description: "skips version 2", Apply: func(todos []domain.TodoItem) ([]domain.TodoItem, error) {
+		return todos, nil
+	}})
+	if _, err := Run(nil, LegacyVersion); err == nil {
+		t.Error("Expected an error for the missing migration to version 2")
+	}
+}
+
+func TestRun_RejectsNewerVersion(t *testing.T) {
+	_, err := Run(nil, Latest()+1)
+	if !errors.Is(err, ErrTooNew) {
+		t.Errorf("Expected ErrTooNew, got %v", err)
+	}
+}
+
+func TestConvertPeriodCompletions_WeekdayTask(t *testing.T) {
+	// Mon/Wed/Fri task; Monday done, Wednesday is next
+	next := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC) // Wednesday
+	task := domain.TodoItem{
+		TaskID:                   1,
+		Status:                   "pending",
+		EndTime:                  next,
+		IsRecurring:              true,
+		RecurringType:            "weekly",
+		RecurringInterval:        1,
+		RecurringWeekdays:        []int{1, 3, 5},
+		CurrentPeriodCompletions: []string{"2024-01-08"},
+	}
+
+	if !ConvertPeriodCompletions(&task) {
+		t.Fatal("Expected the task to be converted")
+	}
+	if task.CurrentPeriodCompletions != nil {
+		t.Errorf("Legacy completions should be cleared, got %v", task.CurrentPeriodCompletions)
+	}
+
+	want := []struct {
+		day    int
+		status string
+	}{{8, "completed"}, {10, "pending"}, {12, "pending"}}
+	if len(task.OccurrenceHistory) != len(want) {
+		t.Fatalf("Expected %d occurrences, got %+v", len(want), task.OccurrenceHistory)
+	}
+	for i, w := range want {
+		occ := task.OccurrenceHistory[i]
+		if occ.ScheduledTime.Day() != w.day || occ.ScheduledTime.Hour() != 9 || occ.Status != w.status {
+			t.Errorf("Occurrence %d: got %s %s, want Jan %d 09:00 %s", i, occ.ScheduledTime, occ.Status, w.day, w.status)
+		}
+	}
+}
+
+func TestConvertPeriodCompletions_RecurringWithoutHistory(t *testing.T) {
+	next := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	task := domain.TodoItem{TaskID: 2, Status: "pending", EndTime: next, IsRecurring: true, RecurringType: "daily", RecurringInterval: 1}
+
+	if !ConvertPeriodCompletions(&task) {
+		t.Fatal("Expected a pending occurrence to be created")
+	}
+	if len(task.OccurrenceHistory) != 1 || !task.OccurrenceHistory[0].ScheduledTime.Equal(next) || task.OccurrenceHistory[0].Status != "pending" {
+		t.Errorf("Expected one pending occurrence at EndTime, got %+v", task.OccurrenceHistory)
+	}
+
+	// Already converted tasks are left alone
+	if ConvertPeriodCompletions(&task) {
+		t.Error("Converting twice should be a no-op")
+	}
+}
+
+func TestAssignUUIDs(t *testing.T) {
+	todos := []domain.TodoItem{{TaskID: 1}, {TaskID: 2, UUID: "kept"}}
+	if n := AssignUUIDs(todos); n != 1 {
+		t.Errorf("Expected 1 task to get a UUID, got %d", n)
+	}
+	if todos[0].UUID == "" || todos[1].UUID != "kept" {
+		t.Errorf("Unexpected UUIDs: %q, %q", todos[0].UUID, todos[1].UUID)
+	}
+}
+
+func TestAddRecurrenceRule(t *testing.T) {
+	first := time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC)
+	task := domain.TodoItem{
+		TaskID: 1, Status: "active", EndTime: first.AddDate(0, 0, 2), IsRecurring: true,
+		RecurringType: "weekly", RecurringInterval: 2, RecurringWeekdays: []int{3, 1},
+		OccurrenceHistory: []domain.OccurrenceRecord{
+			{ScheduledTime: first, Status: "completed"},
+			{ScheduledTime: first.AddDate(0, 0, 2), Status: "pending"},
+		},
+	}
+	if !AddRecurrenceRule(&task) {
+		t.Fatal("Expected the task to get a rule")
+	}
+	if task.RecurrenceRule != "FREQ=WEEKLY;INTERVAL=2;WKST=SU;BYDAY=MO,WE" || !task.RecurrenceStart.Equal(first) {
+		t.Errorf("Got rule %q starting %s", task.RecurrenceRule, task.RecurrenceStart)
+	}
+	if AddRecurrenceRule(&task) {
+		t.Error("Adding a rule twice should be a no-op")
+	}
+
+	plain := domain.TodoItem{TaskID: 2, Status: "pending"}
+	if AddRecurrenceRule(&plain) || plain.RecurrenceRule != "" {
+		t.Error("Non-recurring tasks must be left alone")
+	}
+}
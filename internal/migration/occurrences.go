@@ -0,0 +1,149 @@
+package migration
+
+import (
+	"sort"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/logger"
+)
+
+// legacyNote marks occurrences reconstructed from CurrentPeriodCompletions
+const legacyNote = "migrated from currentPeriodCompletions"
+
+func init() {
+	Register(Migration{
+		Version:     2,
+		Description: "convert currentPeriodCompletions to occurrenceHistory",
+		Apply: func(todos []domain.TodoItem) ([]domain.TodoItem, error) {
+			converted := 0
+			for i := range todos {
+				if ConvertPeriodCompletions(&todos[i]) {
+					converted++
+				}
+			}
+			if converted > 0 {
+				logger.Infof("Converted %d recurring tasks to occurrence tracking", converted)
+			}
+			return todos, nil
+		},
+	})
+}
+
+// ConvertPeriodCompletions moves a recurring task from the deprecated
+// CurrentPeriodCompletions list to OccurrenceHistory and reports whether the
+// task changed. Each legacy completion day becomes a completed occurrence.
+// For weekday-specific weekly tasks the other weekdays of that week become
+// pending occurrences (or missed, if they lie before the next scheduled
+// time), and the next scheduled time (EndTime) is always kept as a pending
+// occurrence. Tasks that already track occurrences are left alone.
+func ConvertPeriodCompletions(task *domain.TodoItem) bool {
+	if !task.IsRecurring {
+		return false
+	}
+	if len(task.OccurrenceHistory) > 0 {
+		if len(task.CurrentPeriodCompletions) == 0 {
+			return false
+		}
+		// The occurrence history is authoritative; drop the stale list
+		task.CurrentPeriodCompletions = nil
+		return true
+	}
+
+	loc := time.Local
+	if !task.EndTime.IsZero() {
+		loc = task.EndTime.Location()
+	}
+
+	var history []domain.OccurrenceRecord
+	completed := make(map[string]bool)
+	var latest time.Time
+	for _, day := range task.CurrentPeriodCompletions {
+		date, err := time.ParseInLocation("2006-01-02", day, loc)
+		if err != nil {
+			logger.Warnf("Task %d: ignoring unreadable completion date %q", task.TaskID, day)
+			continue
+		}
+		if completed[day] {
+			continue
+		}
+		completed[day] = true
+
+		scheduled := atTimeOf(date, task.EndTime)
+		history = append(history, domain.OccurrenceRecord{
+			ScheduledTime: scheduled,
+			Status:        "completed",
+			CompletedAt:   scheduled,
+			Notes:         legacyNote,
+		})
+		if scheduled.After(latest) {
+			latest = scheduled
+		}
+	}
+
+	finished := task.Status == "completed"
+	if !finished && task.RecurringType == "weekly" && len(task.RecurringWeekdays) > 0 {
+		ref := task.EndTime
+		if !latest.IsZero() {
+			ref = latest
+		}
+		weekStart := ref
+		for weekStart.Weekday() != time.Sunday {
+			weekStart = weekStart.AddDate(0, 0, -1)
+		}
+
+		for _, weekday := range task.RecurringWeekdays {
+			scheduled := atTimeOf(weekStart.AddDate(0, 0, weekday), task.EndTime)
+			if completed[scheduled.Format("2006-01-02")] {
+				continue
+			}
+			status := "pending"
+			if scheduled.Before(task.EndTime) {
+				status = "missed"
+			}
+			history = append(history, domain.OccurrenceRecord{
+				ScheduledTime: scheduled,
+				Status:        status,
+				Notes:         legacyNote,
+			})
+		}
+	}
+
+	if !finished && !task.EndTime.IsZero() && !completed[task.EndTime.Format("2006-01-02")] && !hasOccurrenceAt(history, task.EndTime) {
+		history = append(history, domain.OccurrenceRecord{
+			ScheduledTime: task.EndTime,
+			Status:        "pending",
+		})
+	}
+
+	if len(history) == 0 {
+		return false
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].ScheduledTime.Before(history[j].ScheduledTime)
+	})
+	task.OccurrenceHistory = history
+	task.CurrentPeriodCompletions = nil
+	return true
+}
+
+// atTimeOf returns date's calendar day at the time of day (and location) of clock
+func atTimeOf(date, clock time.Time) time.Time {
+	loc := date.Location()
+	if !clock.IsZero() {
+		loc = clock.Location()
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(),
+		clock.Hour(), clock.Minute(), clock.Second(), 0, loc)
+}
+
+// hasOccurrenceAt reports whether history already has an occurrence scheduled at t
+func hasOccurrenceAt(history []domain.OccurrenceRecord, t time.Time) bool {
+	for _, occ := range history {
+		if occ.ScheduledTime.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package migration
+
+import (
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/rrule"
+)
+
+func init() {
+	Register(Migration{
+		Version:     4,
+		Description: "describe recurring tasks by recurrence rules",
+		Apply: func(todos []domain.TodoItem) ([]domain.TodoItem, error) {
+			for i := range todos {
+				AddRecurrenceRule(&todos[i])
+			}
+			return todos, nil
+		},
+	})
+}
+
+// AddRecurrenceRule gives a recurring task without a recurrence rule the
+// rule its type, interval and weekdays describe, starting at its first
+// scheduled occurrence, and reports whether the task changed. Tasks with
+// an unknown recurring type are left alone.
+func AddRecurrenceRule(task *domain.TodoItem) bool {
+	if !task.IsRecurring || task.RecurrenceRule != "" {
+		return false
+	}
+	rule, err := rrule.FromFields(task.RecurringType, task.RecurringInterval, task.RecurringWeekdays)
+	if err != nil {
+		return false
+	}
+	task.RecurrenceRule = rule.String()
+	if task.RecurrenceStart.IsZero() {
+		start := task.EndTime
+		for _, occ := range task.OccurrenceHistory {
+			if start.IsZero() || occ.ScheduledTime.Before(start) {
+				start = occ.ScheduledTime
+			}
+		}
+		task.RecurrenceStart = start
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/google/uuid"
+)
+
+func init() {
+	Register(Migration{
+		Version:     3,
+		Description: "give every task a UUID",
+		Apply: func(todos []domain.TodoItem) ([]domain.TodoItem, error) {
+			AssignUUIDs(todos)
+			return todos, nil
+		},
+	})
+}
+
+// AssignUUIDs gives a fresh UUID to every task in todos that has none and
+// returns how many tasks it changed
+func AssignUUIDs(todos []domain.TodoItem) int {
+	assigned := 0
+	for i := range todos {
+		if todos[i].UUID == "" {
+			todos[i].UUID = uuid.NewString()
+			assigned++
+		}
+	}
+	return assigned
+}
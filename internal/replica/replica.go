@@ -0,0 +1,116 @@
+// Package replica reads and writes the per-device copies of a task list
+// that `todo sync` exchanges through a shared folder. Each device only ever
+// writes its own replica, so file sync services never see two writers.
+package replica
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/encryption"
+	"github.com/SongRunqi/go-todo/internal/migration"
+	"github.com/SongRunqi/go-todo/internal/validator"
+)
+
+// Replica holds both lists of one device as of its last sync. The common
+// ancestors a device keeps for its peers use the same layout.
+type Replica struct {
+	Version int               `json:"version"`
+	Device  string            `json:"device"`
+	Time    time.Time         `json:"time"`
+	Active  []domain.TodoItem `json:"active"`
+	Backup  []domain.TodoItem `json:"backup"`
+}
+
+// Path returns the location of device's replica in dir
+func Path(dir, device string) string {
+	return filepath.Join(dir, device+".json")
+}
+
+// Read loads the replica at path, upgrading tasks written by an older
+// version. A missing file yields nil and no error.
+func Read(path string, c *encryption.Cipher) (*Replica, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read replica: %w", err)
+	}
+	if data, err = encryption.Open(c, data); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", path, err)
+	}
+
+	var r Replica
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if r.Active, err = migration.Run(r.Active, r.Version); err != nil {
+		return nil, fmt.Errorf("cannot load %s: %w", path, err)
+	}
+	if r.Backup, err = migration.Run(r.Backup, r.Version); err != nil {
+		return nil, fmt.Errorf("cannot load %s: %w", path, err)
+	}
+	r.Version = migration.Latest()
+	return &r, nil
+}
+
+// Write stores r at path in the current schema version, replacing the file
+// atomically
+func Write(path string, r Replica, c *encryption.Cipher) error {
+	r.Version = migration.Latest()
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal replica: %w", err)
+	}
+	if data, err = encryption.Seal(c, data); err != nil {
+		return fmt.Errorf("failed to encrypt replica: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create replica directory: %w", err)
+	}
+	perm := os.FileMode(0644)
+	if c != nil {
+		perm = 0600
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return fmt.Errorf("failed to write replica: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write replica: %w", err)
+	}
+	return nil
+}
+
+// Peers returns the names of the devices other than device with a replica
+// in dir. Files that are not replicas, such as the "conflicted copy" files
+// some sync services create, are ignored.
+func Peers(dir, device string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read sync directory: %w", err)
+	}
+
+	var peers []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() || name == device || validator.ValidateDeviceName(name) != nil {
+			continue
+		}
+		peers = append(peers, name)
+	}
+	sort.Strings(peers)
+	return peers, nil
+}
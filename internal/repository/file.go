@@ -1,35 +1,104 @@
 package repository
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/encryption"
 	"github.com/SongRunqi/go-todo/internal/logger"
+	"github.com/SongRunqi/go-todo/internal/migration"
+	"github.com/SongRunqi/go-todo/internal/snapshot"
 )
 
+// ErrConflict is matched by errors.Is for every *ConflictError
+var ErrConflict = errors.New("todo file changed since it was loaded")
+
+// ConflictError reports that a file was changed by someone else (another
+// process or a hand edit) between Load and Save. The save is aborted so
+// those changes are not overwritten; reloading and re-applying the
+// operation is safe.
+type ConflictError struct {
+	Path string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s was modified since it was loaded; save aborted to avoid overwriting those changes", e.Path)
+}
+
+// Is makes errors.Is(err, ErrConflict) match any ConflictError
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
 // FileTodoStore implements file-based storage for todos
 type FileTodoStore struct {
 	Path       string
 	BackupPath string
+
+	// LockTimeout bounds how long Lock waits for another process.
+	// Zero means DefaultLockTimeout.
+	LockTimeout time.Duration
+
+	lock fileLock
+
+	// Snapshots, when set, receives a copy of every file written by Save
+	Snapshots *snapshot.Manager
+
+	// Cipher, when set, encrypts everything Save writes and makes the files
+	// private (0600). Encrypted files can only be loaded with a Cipher;
+	// plaintext files are still read, so existing files can be converted.
+	Cipher *encryption.Cipher
+
+	// lastID is the highest task ID seen in either file or handed out by
+	// NextID; Save writes it to both files so IDs are never reused
+	lastID int
+
+	// seen holds the content hash of each file as of our last Load or Save,
+	// so Save can detect changes made underneath us
+	seen map[string][sha256.Size]byte
 }
 
 // NewFileTodoStore creates a new file-based todo store
 func NewFileTodoStore(path, backupPath string) *FileTodoStore {
 	return &FileTodoStore{
-		Path:       path,
-		BackupPath: backupPath,
+		Path:        path,
+		BackupPath:  backupPath,
+		LockTimeout: DefaultLockTimeout,
 	}
 }
 
+// LockPath returns the path of the advisory lock file guarding the store
+func (f *FileTodoStore) LockPath() string {
+	return f.Path + ".lock"
+}
+
+// Lock takes an exclusive advisory lock on the store so a whole
+// load-modify-save cycle cannot interleave with another todo process.
+// It waits up to LockTimeout and then fails with ErrLockTimeout.
+// Calling Lock while already holding the lock is a no-op.
+func (f *FileTodoStore) Lock() error {
+	return f.lock.acquire(f.LockPath(), f.LockTimeout)
+}
+
+// Unlock releases the lock taken by Lock. It is safe to call without holding the lock.
+func (f *FileTodoStore) Unlock() error {
+	return f.lock.release()
+}
+
 // Load loads todos from file
 func (f *FileTodoStore) Load(backup bool) ([]domain.TodoItem, error) {
 	filePath := f.Path
 	if backup {
 		filePath = f.BackupPath
 	}
-	bytes, err := os.ReadFile(filePath)
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		// 如果文件不存在，创建一个空的文件
 		if os.IsNotExist(err) {
@@ -45,31 +114,245 @@ func (f *FileTodoStore) Load(backup bool) ([]domain.TodoItem, error) {
 		logger.ErrorWithErr(err, "Failed to read file")
 		return make([]domain.TodoItem, 0), fmt.Errorf("failed to read file: %w", err)
 	}
-	var loadingTodos []domain.TodoItem = make([]domain.TodoItem, 0)
-	err = json.Unmarshal(bytes, &loadingTodos)
+	plaintext, err := encryption.Open(f.Cipher, data)
+	if err != nil {
+		return make([]domain.TodoItem, 0), fmt.Errorf("cannot read %s: %w", filePath, err)
+	}
+	envelope, err := decodeTodos(plaintext)
 	if err != nil {
 		logger.ErrorWithErr(err, "Failed to parse JSON")
 		return make([]domain.TodoItem, 0), fmt.Errorf("failed to parse JSON: %w", err)
 	}
+	f.remember(filePath, data)
+	f.noteIDs(envelope.LastID, envelope.Tasks)
+	loadingTodos, version := envelope.Tasks, envelope.Version
+
+	if version != migration.Latest() {
+		return f.migrate(filePath, plaintext, loadingTodos, version, backup)
+	}
 	return loadingTodos, nil
 }
 
-// Save saves todos to file
+// migrate upgrades a file written by another version of todo. The original
+// content is copied to <path>.v<version>.bak before anything is written, and
+// the upgraded tasks are saved back so the migration only runs once.
+func (f *FileTodoStore) migrate(path string, plaintext []byte, todos []domain.TodoItem, version int, backup bool) ([]domain.TodoItem, error) {
+	migrated, err := migration.Run(todos, version)
+	if err != nil {
+		return make([]domain.TodoItem, 0), fmt.Errorf("cannot load %s: %w", path, err)
+	}
+
+	backupPath := fmt.Sprintf("%s.v%d.bak", path, version)
+	data, err := encryption.Seal(f.Cipher, plaintext)
+	if err != nil {
+		return make([]domain.TodoItem, 0), fmt.Errorf("failed to encrypt backup: %w", err)
+	}
+	if err := writeFileAtomic(backupPath, data, f.fileMode()); err != nil {
+		return make([]domain.TodoItem, 0), fmt.Errorf("failed to back up %s before migrating: %w", path, err)
+	}
+	logger.Infof("Backed up %s to %s before migrating to version %d", path, backupPath, migration.Latest())
+
+	if err := f.Save(migrated, backup); err != nil {
+		return make([]domain.TodoItem, 0), fmt.Errorf("failed to save migrated todos: %w", err)
+	}
+	return migrated, nil
+}
+
+// Save saves todos to file. The data is written to a temporary file in the
+// same directory and renamed over the target, so readers never observe a
+// half-written file. If the file changed since this store last loaded or
+// saved it, Save returns a *ConflictError and leaves the file untouched.
 func (f *FileTodoStore) Save(todos []domain.TodoItem, backup bool) error {
 	filePath := f.Path
 	if backup {
 		filePath = f.BackupPath
 	}
-	data, err := json.MarshalIndent(todos, "", "  ")
+	f.noteIDs(0, todos)
+	plaintext, err := encodeTodos(todos, f.lastID)
 	if err != nil {
 		return fmt.Errorf("failed to marshal todos: %w", err)
 	}
+	data, err := encryption.Seal(f.Cipher, plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt todos: %w", err)
+	}
+
+	if err := f.checkUnchanged(filePath); err != nil {
+		return err
+	}
 
-	err = os.WriteFile(filePath, data, 0644)
+	err = writeFileAtomic(filePath, data, f.fileMode())
 	if err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
+	f.remember(filePath, data)
+
+	if f.Snapshots != nil {
+		if _, err := f.Snapshots.Take(filePath, data); err != nil {
+			logger.Warnf("Failed to snapshot %s: %v", filePath, err)
+		}
+	}
 
 	logger.Debug("Successfully saved todos to file")
 	return nil
 }
+
+// NextID hands out the next task ID, above every ID either file has ever
+// held. The new high mark is written to disk by the next Save.
+func (f *FileTodoStore) NextID() (int, error) {
+	for _, backup := range []bool{false, true} {
+		path := f.Path
+		if backup {
+			path = f.BackupPath
+		}
+		if _, ok := f.seen[path]; ok {
+			continue
+		}
+		if _, err := f.Load(backup); err != nil {
+			return 0, err
+		}
+	}
+	f.lastID++
+	return f.lastID, nil
+}
+
+// noteIDs raises lastID to cover lastID and every task in todos
+func (f *FileTodoStore) noteIDs(lastID int, todos []domain.TodoItem) {
+	f.lastID = max(f.lastID, lastID)
+	for _, t := range todos {
+		f.lastID = max(f.lastID, t.TaskID)
+	}
+}
+
+// fileMode returns the permissions for files written by the store
+func (f *FileTodoStore) fileMode() os.FileMode {
+	if f.Cipher != nil {
+		return 0600
+	}
+	return 0644
+}
+
+// remember records the content we last observed for path
+func (f *FileTodoStore) remember(path string, data []byte) {
+	if f.seen == nil {
+		f.seen = make(map[string][sha256.Size]byte)
+	}
+	f.seen[path] = sha256.Sum256(data)
+}
+
+// checkUnchanged returns a *ConflictError if path no longer holds the content
+// we last observed. Files this store has never read are not checked.
+func (f *FileTodoStore) checkUnchanged(path string) error {
+	want, ok := f.seen[path]
+	if !ok {
+		return nil
+	}
+	current, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &ConflictError{Path: path}
+		}
+		return fmt.Errorf("failed to re-read file before saving: %w", err)
+	}
+	if sha256.Sum256(current) != want {
+		logger.Warnf("%s changed on disk since it was loaded", path)
+		return &ConflictError{Path: path}
+	}
+	return nil
+}
+
+// fileEnvelope is the on-disk layout of a task file. Files written before
+// versioning existed hold a bare array of tasks and count as
+// migration.LegacyVersion. LastID is the highest task ID handed out for the
+// store, which may belong to a task that no longer exists.
+type fileEnvelope struct {
+	Version int               `json:"version"`
+	LastID  int               `json:"lastId,omitempty"`
+	Tasks   []domain.TodoItem `json:"tasks"`
+}
+
+// decodeTodos parses a task file; a bare array is returned as an envelope
+// at migration.LegacyVersion
+func decodeTodos(data []byte) (fileEnvelope, error) {
+	todos := make([]domain.TodoItem, 0)
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &todos); err != nil {
+			return fileEnvelope{}, err
+		}
+		return fileEnvelope{Version: migration.LegacyVersion, Tasks: todos}, nil
+	}
+
+	envelope := fileEnvelope{Tasks: todos}
+	if err := json.Unmarshal(trimmed, &envelope); err != nil {
+		return fileEnvelope{}, err
+	}
+	if envelope.Version < migration.LegacyVersion {
+		return fileEnvelope{}, fmt.Errorf("missing or invalid schema version %d", envelope.Version)
+	}
+	if envelope.Tasks == nil {
+		envelope.Tasks = todos
+	}
+	return envelope, nil
+}
+
+// ParseTodos decodes the content of a task file (e.g. a snapshot), upgrading
+// it in memory if it was written by an older version. c may be nil if the
+// data is not encrypted.
+func ParseTodos(data []byte, c *encryption.Cipher) ([]domain.TodoItem, error) {
+	data, err := encryption.Open(c, data)
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := decodeTodos(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return migration.Run(envelope.Tasks, envelope.Version)
+}
+
+// MarshalTodos renders todos as the content of a task file (e.g. for a
+// snapshot), encrypted with c unless it is nil
+func MarshalTodos(todos []domain.TodoItem, c *encryption.Cipher) ([]byte, error) {
+	data, err := encodeTodos(todos, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal todos: %w", err)
+	}
+	return encryption.Seal(c, data)
+}
+
+// encodeTodos renders todos in the current versioned file layout
+func encodeTodos(todos []domain.TodoItem, lastID int) ([]byte, error) {
+	if todos == nil {
+		todos = make([]domain.TodoItem, 0)
+	}
+	return json.MarshalIndent(fileEnvelope{Version: migration.Latest(), LastID: lastID, Tasks: todos}, "", "  ")
+}
+
+// writeFileAtomic writes data to a temporary sibling of path, flushes it to
+// disk and renames it into place
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	// Best effort cleanup; after a successful rename the temp file no longer exists
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
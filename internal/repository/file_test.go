@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/encryption"
+	"github.com/SongRunqi/go-todo/internal/migration"
+)
+
+func TestFileStore_LockExcludesOtherStores(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "todo.json")
+
+	first := NewFileTodoStore(path, filepath.Join(dir, "todo_back.json"))
+	if err := first.Lock(); err != nil {
+		t.Fatalf("first Lock failed: %v", err)
+	}
+
+	second := NewFileTodoStore(path, filepath.Join(dir, "todo_back.json"))
+	second.LockTimeout = 100 * time.Millisecond
+
+	start := time.Now()
+	err := second.Lock()
+	if !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("Expected ErrLockTimeout, got %v", err)
+	}
+	if waited := time.Since(start); waited < second.LockTimeout {
+		t.Errorf("Lock gave up after %v, before the %v timeout", waited, second.LockTimeout)
+	}
+
+	if err := first.Unlock(); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := second.Lock(); err != nil {
+		t.Fatalf("Lock after release failed: %v", err)
+	}
+	if err := second.Unlock(); err != nil {
+		t.Fatalf("second Unlock failed: %v", err)
+	}
+}
+
+func TestFileStore_LockIsReentrant(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileTodoStore(filepath.Join(dir, "todo.json"), filepath.Join(dir, "todo_back.json"))
+
+	if err := store.Lock(); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	if err := store.Lock(); err != nil {
+		t.Fatalf("second Lock on the same store failed: %v", err)
+	}
+	if err := store.Unlock(); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := store.Unlock(); err != nil {
+		t.Fatalf("Unlock without lock should be a no-op, got %v", err)
+	}
+}
+
+func TestFileStore_SaveLeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileTodoStore(filepath.Join(dir, "todo.json"), filepath.Join(dir, "todo_back.json"))
+
+	for i := 0; i < 3; i++ {
+		if err := store.Save([]domain.TodoItem{{TaskID: i + 1, TaskName: "Task"}}, false); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "todo.json" {
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("Expected only todo.json in %s, found %v", dir, names)
+	}
+
+	loaded, err := store.Load(false)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].TaskID != 3 {
+		t.Errorf("Expected the last save to win, got %+v", loaded)
+	}
+}
+
+func TestFileStore_SaveDetectsExternalChange(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "todo.json")
+	store := NewFileTodoStore(path, filepath.Join(dir, "todo_back.json"))
+
+	if err := store.Save([]domain.TodoItem{{TaskID: 1, TaskName: "Original"}}, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	todos, err := store.Load(false)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// Simulate a hand edit while the command is running
+	if err := os.WriteFile(path, []byte(`[{"taskId": 1, "taskName": "Edited by hand"}]`), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	todos[0].Status = "completed"
+	err = store.Save(todos, false)
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("Expected ErrConflict, got %v", err)
+	}
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Path != path {
+		t.Errorf("Expected *ConflictError for %s, got %#v", path, err)
+	}
+
+	// The hand edit must survive
+	data, _ := os.ReadFile(path)
+	if string(data) != `[{"taskId": 1, "taskName": "Edited by hand"}]` {
+		t.Errorf("Conflicting save overwrote the file: %s", data)
+	}
+
+	// Reloading picks up the edit and allows saving again
+	todos, err = store.Load(false)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	todos[0].Status = "completed"
+	if err := store.Save(todos, false); err != nil {
+		t.Fatalf("Save after reload failed: %v", err)
+	}
+}
+
+func TestFileStore_ConsecutiveSavesDoNotConflict(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileTodoStore(filepath.Join(dir, "todo.json"), filepath.Join(dir, "todo_back.json"))
+
+	if _, err := store.Load(false); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		if err := store.Save([]domain.TodoItem{{TaskID: i}}, false); err != nil {
+			t.Fatalf("Save %d failed: %v", i, err)
+		}
+	}
+}
+
+func TestFileStore_LoadMigratesLegacyArray(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "todo.json")
+	legacy := `[{"taskId": 1, "taskName": "Gym", "status": "pending", "endTime": "2024-01-10T09:00:00Z",
+		"isRecurring": true, "recurringType": "weekly", "recurringInterval": 1,
+		"recurringWeekdays": [1, 3, 5], "currentPeriodCompletions": ["2024-01-08"]}]`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	store := NewFileTodoStore(path, filepath.Join(dir, "todo_back.json"))
+	todos, err := store.Load(false)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(todos) != 1 || len(todos[0].OccurrenceHistory) != 3 || todos[0].CurrentPeriodCompletions != nil {
+		t.Fatalf("Expected the legacy task to be converted, got %+v", todos)
+	}
+
+	backup, err := os.ReadFile(path + ".v1.bak")
+	if err != nil {
+		t.Fatalf("Expected a backup of the legacy file: %v", err)
+	}
+	if string(backup) != legacy {
+		t.Errorf("Backup does not match the original file: %s", backup)
+	}
+
+	envelope, err := decodeTodos(mustReadFile(t, path))
+	if err != nil || envelope.Version != migration.Latest() {
+		t.Errorf("Expected the file to be rewritten at version %d, got %d (%v)", migration.Latest(), envelope.Version, err)
+	}
+
+	// The migrated file can be saved without a conflict
+	if err := store.Save(todos, false); err != nil {
+		t.Fatalf("Save after migration failed: %v", err)
+	}
+}
+
+func TestFileStore_LoadRejectsNewerVersion(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "todo.json")
+	content := fmt.Sprintf(`{"version": %d, "tasks": []}`, migration.Latest()+1)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	store := NewFileTodoStore(path, filepath.Join(dir, "todo_back.json"))
+	if _, err := store.Load(false); !errors.Is(err, migration.ErrTooNew) {
+		t.Fatalf("Expected ErrTooNew, got %v", err)
+	}
+	if data := mustReadFile(t, path); string(data) != content {
+		t.Errorf("A file from a newer version must not be touched, got %s", data)
+	}
+}
+
+func TestFileStore_Encrypted(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "todo.json")
+	store := NewFileTodoStore(path, filepath.Join(dir, "todo_back.json"))
+	store.Cipher = encryption.New("secret")
+
+	if err := store.Save([]domain.TodoItem{{TaskID: 1, TaskName: "Call the bank"}}, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	data := mustReadFile(t, path)
+	if !encryption.IsEncrypted(data) || strings.Contains(string(data), "Call the bank") {
+		t.Fatalf("Expected an encrypted file, got %q", data)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected an encrypted file to be private, got %v (%v)", info.Mode().Perm(), err)
+	}
+
+	reader := NewFileTodoStore(path, filepath.Join(dir, "todo_back.json"))
+	reader.Cipher = encryption.New("secret")
+	todos, err := reader.Load(false)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(todos) != 1 || todos[0].TaskName != "Call the bank" {
+		t.Errorf("Expected the saved task, got %+v", todos)
+	}
+
+	if _, err := NewFileTodoStore(path, "").Load(false); !errors.Is(err, encryption.ErrEncrypted) {
+		t.Errorf("Expected ErrEncrypted without a passphrase, got %v", err)
+	}
+	wrong := NewFileTodoStore(path, "")
+	wrong.Cipher = encryption.New("guess")
+	if _, err := wrong.Load(false); !errors.Is(err, encryption.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt with the wrong passphrase, got %v", err)
+	}
+	if data := mustReadFile(t, path); !encryption.IsEncrypted(data) {
+		t.Errorf("A failed load must not touch the file")
+	}
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	return data
+}
+
+func TestFileStore_NextIDNeverReuses(t *testing.T) {
+	dir := t.TempDir()
+	path, backupPath := filepath.Join(dir, "todo.json"), filepath.Join(dir, "todo_back.json")
+	store := NewFileTodoStore(path, backupPath)
+	if err := store.Save([]domain.TodoItem{{TaskID: 1, TaskName: "Active"}}, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := store.Save([]domain.TodoItem{{TaskID: 7, TaskName: "Done"}}, true); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// A fresh store looks at the backup list too
+	store = NewFileTodoStore(path, backupPath)
+	id, err := store.NextID()
+	if err != nil || id != 8 {
+		t.Fatalf("Expected ID 8 after the backup's 7, got %d (%v)", id, err)
+	}
+	if err := store.Save([]domain.TodoItem{{TaskID: 1, TaskName: "Active"}, {TaskID: id, TaskName: "New"}}, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Deleting every task for good does not free their IDs
+	if err := store.Save(nil, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := store.Save(nil, true); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	store = NewFileTodoStore(path, backupPath)
+	if id, err := store.NextID(); err != nil || id != 9 {
+		t.Errorf("Expected ID 9 after deleting everything, got %d (%v)", id, err)
+	}
+}
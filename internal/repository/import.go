@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+)
+
+// CopyTodos copies the active and backup lists from src into dst, replacing
+// whatever dst held. It returns the number of active and backup tasks copied.
+func CopyTodos(dst, src domain.TodoStore) (int, int, error) {
+	active, err := src.Load(false)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load active todos: %w", err)
+	}
+	backup, err := src.Load(true)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load backup todos: %w", err)
+	}
+
+	if err := dst.Save(active, false); err != nil {
+		return 0, 0, fmt.Errorf("failed to save active todos: %w", err)
+	}
+	if err := dst.Save(backup, true); err != nil {
+		return 0, 0, fmt.Errorf("failed to save backup todos: %w", err)
+	}
+	return len(active), len(backup), nil
+}
+
+// IsEmpty reports whether both the active and backup lists of store are empty
+func IsEmpty(store domain.TodoStore) (bool, error) {
+	for _, backup := range []bool{false, true} {
+		todos, err := store.Load(backup)
+		if err != nil {
+			return false, err
+		}
+		if len(todos) > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/logger"
+)
+
+// DefaultLockTimeout is how long Lock waits for another process to release the store
+const DefaultLockTimeout = 5 * time.Second
+
+// lockRetryInterval is how often Lock polls a lock held by another process
+const lockRetryInterval = 50 * time.Millisecond
+
+// ErrLockTimeout is returned when the store lock could not be acquired in time
+var ErrLockTimeout = errors.New("timed out waiting for the todo lock")
+
+// fileLock is an exclusive advisory lock on a lock file, shared by the
+// stores so every backend serialises load-modify-save cycles the same way
+type fileLock struct {
+	file *os.File
+}
+
+// acquire locks path, waiting up to timeout for another process to release
+// it. Acquiring a lock that is already held is a no-op.
+func (l *fileLock) acquire(path string, timeout time.Duration) error {
+	if l.file != nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		locked, err := tryLockFile(file)
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			l.file = file
+			logger.Debugf("Acquired lock %s", path)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return fmt.Errorf("%w after %s: %s is held by another todo process", ErrLockTimeout, timeout, path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// release drops the lock. It is safe to call without holding the lock.
+func (l *fileLock) release() error {
+	if l.file == nil {
+		return nil
+	}
+	file := l.file
+	l.file = nil
+
+	if err := unlockFile(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to unlock %s: %w", file.Name(), err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/SongRunqi/go-todo/internal/config"
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/encryption"
+	"github.com/SongRunqi/go-todo/internal/githistory"
+	"github.com/SongRunqi/go-todo/internal/journal"
+	"github.com/SongRunqi/go-todo/internal/snapshot"
+)
+
+// Open returns the store selected by cfg.Storage, wrapped in a store
+// committing to git when cfg.GitHistory is set and in a journaling store
+// when cfg.JournalPath is set. Stores that hold resources (the SQLite
+// database) implement io.Closer; use domain.As to find it and close it.
+// storage.MemoryTodoStore is for tests only and cannot be selected here.
+func Open(cfg config.Config) (domain.TodoStore, error) {
+	var c *encryption.Cipher
+	if cfg.Encrypt {
+		if cfg.Storage == config.StorageSQLite {
+			return nil, errors.New("encryption is only supported by the file storage backend")
+		}
+		passphrase, err := encryption.Passphrase(cfg.PassphraseCommand)
+		if err != nil {
+			return nil, err
+		}
+		c = encryption.New(passphrase)
+	}
+
+	if cfg.GitHistory && cfg.Storage == config.StorageSQLite {
+		return nil, errors.New("git history is only supported by the file storage backend")
+	}
+
+	store, err := openBackend(cfg, c)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.GitHistory {
+		repo, err := githistory.Open(cfg.GitDir)
+		if err != nil {
+			return nil, err
+		}
+		store = githistory.NewStore(store, repo, cfg.TodoPath, cfg.BackupPath)
+	}
+	if cfg.JournalPath != "" {
+		j := journal.New(cfg.JournalPath)
+		j.Cipher = c
+		store = journal.NewStore(store, j)
+	}
+	return store, nil
+}
+
+// OpenSnapshots returns the snapshot manager configured by cfg, or nil when
+// snapshots are disabled
+func OpenSnapshots(cfg config.Config) *snapshot.Manager {
+	if cfg.SnapshotDir == "" || (cfg.SnapshotKeep <= 0 && cfg.SnapshotDays <= 0) {
+		return nil
+	}
+	return snapshot.NewManager(cfg.SnapshotDir, cfg.SnapshotKeep, cfg.SnapshotDays)
+}
+
+// openBackend returns the unwrapped storage backend selected by cfg.Storage
+func openBackend(cfg config.Config, c *encryption.Cipher) (domain.TodoStore, error) {
+	switch cfg.Storage {
+	case "", config.StorageFile:
+		store := NewFileTodoStore(cfg.TodoPath, cfg.BackupPath)
+		store.Snapshots = OpenSnapshots(cfg)
+		store.Cipher = c
+		return store, nil
+	case config.StorageSQLite:
+		store, err := NewSQLiteTodoStore(cfg.DBPath)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (use %s|%s)", cfg.Storage, config.StorageFile, config.StorageSQLite)
+	}
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/config"
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/journal"
+)
+
+func TestOpen_SelectsBackend(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Config{
+		TodoPath:   filepath.Join(dir, "todo.json"),
+		BackupPath: filepath.Join(dir, "todo_back.json"),
+		DBPath:     filepath.Join(dir, "todo.db"),
+	}
+
+	for _, storage := range []string{"", config.StorageFile} {
+		cfg.Storage = storage
+		store, err := Open(cfg)
+		if err != nil {
+			t.Fatalf("Open(%q) failed: %v", storage, err)
+		}
+		if _, ok := store.(*FileTodoStore); !ok {
+			t.Errorf("Open(%q) returned %T, want *FileTodoStore", storage, store)
+		}
+	}
+
+	cfg.Storage = config.StorageSQLite
+	store, err := Open(cfg)
+	if err != nil {
+		t.Fatalf("Open(sqlite) failed: %v", err)
+	}
+	sqliteStore, ok := store.(*SQLiteTodoStore)
+	if !ok {
+		t.Fatalf("Open(sqlite) returned %T, want *SQLiteTodoStore", store)
+	}
+	defer sqliteStore.Close()
+
+	cfg.Storage = "cloud"
+	if _, err := Open(cfg); err == nil {
+		t.Error("Expected error for unknown storage backend")
+	}
+
+	// Unsupported combinations are refused before the database is opened
+	cfg.Storage, cfg.GitHistory, cfg.DBPath = config.StorageSQLite, true, filepath.Join(dir, "git.db")
+	if _, err := Open(cfg); err == nil {
+		t.Error("Expected error for git history with the sqlite backend")
+	}
+	if _, err := os.Stat(cfg.DBPath); !os.IsNotExist(err) {
+		t.Errorf("Expected no database to be opened, got %v", err)
+	}
+}
+
+func TestSQLiteStore_LockExcludesOtherStores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.db")
+	first, err := NewSQLiteTodoStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteTodoStore failed: %v", err)
+	}
+	defer first.Close()
+	second, err := NewSQLiteTodoStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteTodoStore failed: %v", err)
+	}
+	defer second.Close()
+	second.LockTimeout = 100 * time.Millisecond
+
+	if err := first.Lock(); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	if err := second.Lock(); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("Expected ErrLockTimeout, got %v", err)
+	}
+	if err := first.Unlock(); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := second.Lock(); err != nil {
+		t.Fatalf("Lock after release failed: %v", err)
+	}
+}
+
+func TestOpen_WrapsStoreInJournal(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Config{
+		TodoPath:    filepath.Join(dir, "todo.json"),
+		BackupPath:  filepath.Join(dir, "todo_back.json"),
+		JournalPath: filepath.Join(dir, "journal.jsonl"),
+	}
+
+	store, err := Open(cfg)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, ok := domain.As[*journal.Store](store); !ok {
+		t.Fatalf("Expected a journaling store, got %T", store)
+	}
+	if _, ok := domain.As[domain.Locker](store); !ok {
+		t.Error("Expected the file store's Locker to be reachable through the journal")
+	}
+}
@@ -0,0 +1,583 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+	"github.com/SongRunqi/go-todo/internal/logger"
+	"github.com/SongRunqi/go-todo/internal/migration"
+	"github.com/google/uuid"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+const (
+	storeActive = "active"
+	storeBackup = "backup"
+)
+
+// sqliteSchema holds the schema migrations for the SQLite store.
+// Entry i upgrades the database from user_version i to i+1; append new
+// entries to evolve the schema and never edit existing ones.
+var sqliteSchema = []string{
+	`CREATE TABLE tasks (
+		id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+		store               TEXT    NOT NULL,
+		position            INTEGER NOT NULL,
+		task_id             INTEGER NOT NULL,
+		create_time         TEXT    NOT NULL DEFAULT '',
+		end_time            TEXT    NOT NULL DEFAULT '',
+		user                TEXT    NOT NULL DEFAULT '',
+		task_name           TEXT    NOT NULL DEFAULT '',
+		task_desc           TEXT    NOT NULL DEFAULT '',
+		status              TEXT    NOT NULL DEFAULT '',
+		due_date            TEXT    NOT NULL DEFAULT '',
+		urgent              TEXT    NOT NULL DEFAULT '',
+		event_duration      INTEGER NOT NULL DEFAULT 0,
+		is_recurring        INTEGER NOT NULL DEFAULT 0,
+		recurring_type      TEXT    NOT NULL DEFAULT '',
+		recurring_interval  INTEGER NOT NULL DEFAULT 0,
+		recurring_max_count INTEGER NOT NULL DEFAULT 0,
+		completion_count    INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX idx_tasks_store ON tasks(store, position);
+	CREATE TABLE task_weekdays (
+		task_row INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		weekday  INTEGER NOT NULL
+	);
+	CREATE INDEX idx_task_weekdays_task ON task_weekdays(task_row);
+	CREATE TABLE occurrences (
+		task_row       INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		position       INTEGER NOT NULL,
+		scheduled_time TEXT    NOT NULL,
+		status         TEXT    NOT NULL,
+		completed_at   TEXT    NOT NULL DEFAULT '',
+		notes          TEXT    NOT NULL DEFAULT ''
+	);
+	CREATE INDEX idx_occurrences_task ON occurrences(task_row);
+	CREATE TABLE legacy_period_completions (
+		task_row INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		day      TEXT    NOT NULL
+	);
+	CREATE INDEX idx_legacy_completions_task ON legacy_period_completions(task_row);`,
+	`ALTER TABLE tasks ADD COLUMN uuid TEXT NOT NULL DEFAULT '';
+	CREATE TABLE counters (
+		name  TEXT    PRIMARY KEY,
+		value INTEGER NOT NULL
+	);
+	INSERT INTO counters (name, value) SELECT 'last_task_id', COALESCE(MAX(task_id), 0) FROM tasks;`,
+	`ALTER TABLE tasks ADD COLUMN completed_at TEXT NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN archived_at TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE tasks ADD COLUMN deleted_at TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE tasks ADD COLUMN project TEXT NOT NULL DEFAULT '';
+	CREATE TABLE task_tags (
+		task_row INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		tag      TEXT    NOT NULL
+	);
+	CREATE INDEX idx_task_tags_task ON task_tags(task_row);`,
+	`ALTER TABLE tasks ADD COLUMN recurrence_rule TEXT NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN recurrence_start TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE occurrences ADD COLUMN original_time TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE tasks ADD COLUMN paused_until TEXT NOT NULL DEFAULT '';`,
+}
+
+// SQLiteTodoStore implements domain.TodoStore on top of a SQLite database.
+// Active and backup tasks live in the same tables, distinguished by the
+// store column; weekdays and occurrence history are kept in child tables.
+type SQLiteTodoStore struct {
+	Path string
+
+	// LockTimeout bounds how long Lock waits for another process.
+	// Zero means DefaultLockTimeout.
+	LockTimeout time.Duration
+
+	db   *sql.DB
+	lock fileLock
+}
+
+// NewSQLiteTodoStore opens (or creates) the database at path and brings its
+// schema up to date
+func NewSQLiteTodoStore(path string) (*SQLiteTodoStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// A single connection keeps the per-connection pragmas in effect and
+	// serialises writers inside this process.
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteTodoStore{Path: path, LockTimeout: DefaultLockTimeout, db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close releases the underlying database handle and any lock still held
+func (s *SQLiteTodoStore) Close() error {
+	if err := s.lock.release(); err != nil {
+		logger.Warnf("Failed to release database lock: %v", err)
+	}
+	return s.db.Close()
+}
+
+// LockPath returns the path of the advisory lock file guarding the database
+func (s *SQLiteTodoStore) LockPath() string {
+	return s.Path + ".lock"
+}
+
+// Lock takes the same kind of advisory lock as FileTodoStore.Lock. SQLite
+// already makes each Save atomic; the lock additionally stops another
+// process from saving between our Load and Save.
+func (s *SQLiteTodoStore) Lock() error {
+	return s.lock.acquire(s.LockPath(), s.LockTimeout)
+}
+
+// Unlock releases the lock taken by Lock. It is safe to call without holding the lock.
+func (s *SQLiteTodoStore) Unlock() error {
+	return s.lock.release()
+}
+
+// migrate applies any schema migrations the database has not seen yet
+func (s *SQLiteTodoStore) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for v := version; v < len(sqliteSchema); v++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin schema migration: %w", err)
+		}
+		if _, err := tx.Exec(sqliteSchema[v]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply schema version %d: %w", v+1, err)
+		}
+		// PRAGMA does not accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", v+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record schema version %d: %w", v+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit schema version %d: %w", v+1, err)
+		}
+		logger.Debugf("Applied SQLite schema version %d", v+1)
+	}
+	if err := s.assignUUIDs(); err != nil {
+		return err
+	}
+	return s.addRecurrenceRules()
+}
+
+// assignUUIDs gives a UUID to every task stored before tasks had one
+func (s *SQLiteTodoStore) assignUUIDs() error {
+	rows, err := s.db.Query(`SELECT id FROM tasks WHERE uuid = ''`)
+	if err != nil {
+		return fmt.Errorf("failed to query tasks without a UUID: %w", err)
+	}
+	var rowIDs []int64
+	for rows.Next() {
+		var rowID int64
+		if err := rows.Scan(&rowID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan task: %w", err)
+		}
+		rowIDs = append(rowIDs, rowID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read tasks: %w", err)
+	}
+
+	for _, rowID := range rowIDs {
+		if _, err := s.db.Exec(`UPDATE tasks SET uuid = ? WHERE id = ?`, uuid.NewString(), rowID); err != nil {
+			return fmt.Errorf("failed to assign UUID: %w", err)
+		}
+	}
+	return nil
+}
+
+// addRecurrenceRules gives a recurrence rule to every recurring task stored
+// before tasks had one, as the JSON store's migration does
+func (s *SQLiteTodoStore) addRecurrenceRules() error {
+	var missing int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE is_recurring = 1 AND recurrence_rule = ''`).Scan(&missing); err != nil {
+		return fmt.Errorf("failed to query tasks without a recurrence rule: %w", err)
+	}
+	if missing == 0 {
+		return nil
+	}
+
+	for _, backup := range []bool{false, true} {
+		todos, err := s.Load(backup)
+		if err != nil {
+			return err
+		}
+		changed := false
+		for i := range todos {
+			if migration.AddRecurrenceRule(&todos[i]) {
+				changed = true
+			}
+		}
+		if changed {
+			if err := s.Save(todos, backup); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// NextID hands out the next task ID, above every ID the database has ever
+// held, and records it at once
+func (s *SQLiteTodoStore) NextID() (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	if err := tx.QueryRow(`SELECT MAX(
+		(SELECT value FROM counters WHERE name = 'last_task_id'),
+		(SELECT COALESCE(MAX(task_id), 0) FROM tasks)) + 1`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to read last task ID: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE counters SET value = ? WHERE name = 'last_task_id'`, id); err != nil {
+		return 0, fmt.Errorf("failed to record task ID: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to record task ID: %w", err)
+	}
+	return id, nil
+}
+
+// Load loads todos from the database
+func (s *SQLiteTodoStore) Load(backup bool) ([]domain.TodoItem, error) {
+	store := storeName(backup)
+
+	rows, err := s.db.Query(`SELECT id, task_id, uuid, create_time, end_time, user, task_name, task_desc,
+		status, due_date, urgent, project, completed_at, archived_at, deleted_at, event_duration,
+		is_recurring, recurring_type, recurring_interval, recurring_max_count, recurrence_rule, recurrence_start,
+		completion_count, paused_until
+		FROM tasks WHERE store = ? ORDER BY position`, store)
+	if err != nil {
+		return make([]domain.TodoItem, 0), fmt.Errorf("failed to query tasks: %w", err)
+	}
+
+	todos := make([]domain.TodoItem, 0)
+	index := make(map[int64]int)
+	for rows.Next() {
+		var (
+			rowID                              int64
+			item                               domain.TodoItem
+			createTime, endTime                string
+			completedAt, archivedAt, deletedAt string
+			recurrenceStart, pausedUntil       string
+			eventDuration                      int64
+			isRecurring                        int
+		)
+		if err := rows.Scan(&rowID, &item.TaskID, &item.UUID, &createTime, &endTime, &item.User, &item.TaskName,
+			&item.TaskDesc, &item.Status, &item.DueDate, &item.Urgent, &item.Project, &completedAt, &archivedAt, &deletedAt,
+			&eventDuration, &isRecurring, &item.RecurringType, &item.RecurringInterval, &item.RecurringMaxCount,
+			&item.RecurrenceRule, &recurrenceStart, &item.CompletionCount, &pausedUntil); err != nil {
+			rows.Close()
+			return make([]domain.TodoItem, 0), fmt.Errorf("failed to scan task: %w", err)
+		}
+		if item.CreateTime, err = parseTime(createTime); err != nil {
+			rows.Close()
+			return make([]domain.TodoItem, 0), err
+		}
+		if item.EndTime, err = parseTime(endTime); err != nil {
+			rows.Close()
+			return make([]domain.TodoItem, 0), err
+		}
+		if item.CompletedAt, err = parseTime(completedAt); err != nil {
+			rows.Close()
+			return make([]domain.TodoItem, 0), err
+		}
+		if item.ArchivedAt, err = parseTime(archivedAt); err != nil {
+			rows.Close()
+			return make([]domain.TodoItem, 0), err
+		}
+		if item.DeletedAt, err = parseTime(deletedAt); err != nil {
+			rows.Close()
+			return make([]domain.TodoItem, 0), err
+		}
+		if item.RecurrenceStart, err = parseTime(recurrenceStart); err != nil {
+			rows.Close()
+			return make([]domain.TodoItem, 0), err
+		}
+		if item.PausedUntil, err = parseTime(pausedUntil); err != nil {
+			rows.Close()
+			return make([]domain.TodoItem, 0), err
+		}
+		item.EventDuration = time.Duration(eventDuration)
+		item.IsRecurring = isRecurring != 0
+
+		index[rowID] = len(todos)
+		todos = append(todos, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return make([]domain.TodoItem, 0), fmt.Errorf("failed to read tasks: %w", err)
+	}
+
+	if err := s.loadWeekdays(store, todos, index); err != nil {
+		return make([]domain.TodoItem, 0), err
+	}
+	if err := s.loadTags(store, todos, index); err != nil {
+		return make([]domain.TodoItem, 0), err
+	}
+	if err := s.loadOccurrences(store, todos, index); err != nil {
+		return make([]domain.TodoItem, 0), err
+	}
+	if err := s.loadLegacyCompletions(store, todos, index); err != nil {
+		return make([]domain.TodoItem, 0), err
+	}
+	return todos, nil
+}
+
+func (s *SQLiteTodoStore) loadWeekdays(store string, todos []domain.TodoItem, index map[int64]int) error {
+	rows, err := s.db.Query(`SELECT w.task_row, w.weekday FROM task_weekdays w
+		JOIN tasks t ON t.id = w.task_row WHERE t.store = ? ORDER BY w.task_row, w.position`, store)
+	if err != nil {
+		return fmt.Errorf("failed to query weekdays: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rowID int64
+		var weekday int
+		if err := rows.Scan(&rowID, &weekday); err != nil {
+			return fmt.Errorf("failed to scan weekday: %w", err)
+		}
+		if i, ok := index[rowID]; ok {
+			todos[i].RecurringWeekdays = append(todos[i].RecurringWeekdays, weekday)
+		}
+	}
+	return rows.Err()
+}
+
+func (s *SQLiteTodoStore) loadTags(store string, todos []domain.TodoItem, index map[int64]int) error {
+	rows, err := s.db.Query(`SELECT g.task_row, g.tag FROM task_tags g
+		JOIN tasks t ON t.id = g.task_row WHERE t.store = ? ORDER BY g.task_row, g.position`, store)
+	if err != nil {
+		return fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rowID int64
+		var tag string
+		if err := rows.Scan(&rowID, &tag); err != nil {
+			return fmt.Errorf("failed to scan tag: %w", err)
+		}
+		if i, ok := index[rowID]; ok {
+			todos[i].Tags = append(todos[i].Tags, tag)
+		}
+	}
+	return rows.Err()
+}
+
+func (s *SQLiteTodoStore) loadOccurrences(store string, todos []domain.TodoItem, index map[int64]int) error {
+	rows, err := s.db.Query(`SELECT o.task_row, o.scheduled_time, o.status, o.completed_at, o.notes, o.original_time
+		FROM occurrences o JOIN tasks t ON t.id = o.task_row
+		WHERE t.store = ? ORDER BY o.task_row, o.position`, store)
+	if err != nil {
+		return fmt.Errorf("failed to query occurrences: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rowID int64
+		var occ domain.OccurrenceRecord
+		var scheduled, completedAt, original string
+		if err := rows.Scan(&rowID, &scheduled, &occ.Status, &completedAt, &occ.Notes, &original); err != nil {
+			return fmt.Errorf("failed to scan occurrence: %w", err)
+		}
+		if occ.ScheduledTime, err = parseTime(scheduled); err != nil {
+			return err
+		}
+		if occ.CompletedAt, err = parseTime(completedAt); err != nil {
+			return err
+		}
+		if occ.OriginalTime, err = parseTime(original); err != nil {
+			return err
+		}
+		if i, ok := index[rowID]; ok {
+			todos[i].OccurrenceHistory = append(todos[i].OccurrenceHistory, occ)
+		}
+	}
+	return rows.Err()
+}
+
+func (s *SQLiteTodoStore) loadLegacyCompletions(store string, todos []domain.TodoItem, index map[int64]int) error {
+	rows, err := s.db.Query(`SELECT l.task_row, l.day FROM legacy_period_completions l
+		JOIN tasks t ON t.id = l.task_row WHERE t.store = ? ORDER BY l.task_row, l.position`, store)
+	if err != nil {
+		return fmt.Errorf("failed to query legacy completions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rowID int64
+		var day string
+		if err := rows.Scan(&rowID, &day); err != nil {
+			return fmt.Errorf("failed to scan legacy completion: %w", err)
+		}
+		if i, ok := index[rowID]; ok {
+			todos[i].CurrentPeriodCompletions = append(todos[i].CurrentPeriodCompletions, day)
+		}
+	}
+	return rows.Err()
+}
+
+// Save replaces the contents of the active or backup store in a single transaction
+func (s *SQLiteTodoStore) Save(todos []domain.TodoItem, backup bool) error {
+	store := storeName(backup)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Child rows are removed through ON DELETE CASCADE
+	if _, err := tx.Exec(`DELETE FROM tasks WHERE store = ?`, store); err != nil {
+		return fmt.Errorf("failed to clear %s tasks: %w", store, err)
+	}
+
+	insertTask, err := tx.Prepare(`INSERT INTO tasks (store, position, task_id, uuid, create_time, end_time,
+		user, task_name, task_desc, status, due_date, urgent, project, completed_at, archived_at, deleted_at,
+		event_duration, is_recurring, recurring_type, recurring_interval, recurring_max_count, recurrence_rule,
+		recurrence_start, completion_count, paused_until)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare task insert: %w", err)
+	}
+	defer insertTask.Close()
+
+	insertWeekday, err := tx.Prepare(`INSERT INTO task_weekdays (task_row, position, weekday) VALUES (?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare weekday insert: %w", err)
+	}
+	defer insertWeekday.Close()
+
+	insertTag, err := tx.Prepare(`INSERT INTO task_tags (task_row, position, tag) VALUES (?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare tag insert: %w", err)
+	}
+	defer insertTag.Close()
+
+	insertOccurrence, err := tx.Prepare(`INSERT INTO occurrences (task_row, position, scheduled_time,
+		status, completed_at, notes, original_time) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare occurrence insert: %w", err)
+	}
+	defer insertOccurrence.Close()
+
+	insertLegacy, err := tx.Prepare(`INSERT INTO legacy_period_completions (task_row, position, day) VALUES (?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare legacy completion insert: %w", err)
+	}
+	defer insertLegacy.Close()
+
+	for pos, item := range todos {
+		res, err := insertTask.Exec(store, pos, item.TaskID, item.UUID, formatTime(item.CreateTime), formatTime(item.EndTime),
+			item.User, item.TaskName, item.TaskDesc, item.Status, item.DueDate, item.Urgent, item.Project,
+			formatTime(item.CompletedAt), formatTime(item.ArchivedAt), formatTime(item.DeletedAt),
+			int64(item.EventDuration), boolToInt(item.IsRecurring), item.RecurringType, item.RecurringInterval,
+			item.RecurringMaxCount, item.RecurrenceRule, formatTime(item.RecurrenceStart), item.CompletionCount,
+			formatTime(item.PausedUntil))
+		if err != nil {
+			return fmt.Errorf("failed to insert task %d: %w", item.TaskID, err)
+		}
+		rowID, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to read row id for task %d: %w", item.TaskID, err)
+		}
+
+		for i, weekday := range item.RecurringWeekdays {
+			if _, err := insertWeekday.Exec(rowID, i, weekday); err != nil {
+				return fmt.Errorf("failed to insert weekday for task %d: %w", item.TaskID, err)
+			}
+		}
+		for i, tag := range item.Tags {
+			if _, err := insertTag.Exec(rowID, i, tag); err != nil {
+				return fmt.Errorf("failed to insert tag for task %d: %w", item.TaskID, err)
+			}
+		}
+		for i, occ := range item.OccurrenceHistory {
+			if _, err := insertOccurrence.Exec(rowID, i, formatTime(occ.ScheduledTime), occ.Status,
+				formatTime(occ.CompletedAt), occ.Notes, formatTime(occ.OriginalTime)); err != nil {
+				return fmt.Errorf("failed to insert occurrence for task %d: %w", item.TaskID, err)
+			}
+		}
+		for i, day := range item.CurrentPeriodCompletions {
+			if _, err := insertLegacy.Exec(rowID, i, day); err != nil {
+				return fmt.Errorf("failed to insert legacy completion for task %d: %w", item.TaskID, err)
+			}
+		}
+	}
+
+	// Remember the highest ID saved, so it is not reused once the task is gone
+	if _, err := tx.Exec(`UPDATE counters SET value = MAX(value, (SELECT COALESCE(MAX(task_id), 0) FROM tasks))
+		WHERE name = 'last_task_id'`); err != nil {
+		return fmt.Errorf("failed to record last task ID: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit %s tasks: %w", store, err)
+	}
+
+	logger.Debug("Successfully saved todos to SQLite")
+	return nil
+}
+
+func storeName(backup bool) string {
+	if backup {
+		return storeBackup
+	}
+	return storeActive
+}
+
+// formatTime stores times as RFC 3339 text so the original offset survives a round trip
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid stored time %q: %w", s, err)
+	}
+	return t, nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/domain"
+)
+
+func newTestSQLiteStore(t *testing.T) *SQLiteTodoStore {
+	t.Helper()
+	store, err := NewSQLiteTodoStore(filepath.Join(t.TempDir(), "todo.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteTodoStore failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSQLiteStore_SaveAndLoad(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	loc := time.FixedZone("CST", 8*3600)
+	endTime := time.Date(2025, 11, 5, 14, 0, 0, 0, loc)
+	todos := []domain.TodoItem{
+		{
+			TaskID:            1,
+			CreateTime:        time.Date(2025, 11, 1, 9, 30, 0, 0, loc),
+			EndTime:           endTime,
+			User:              "alice",
+			TaskName:          "Class",
+			TaskDesc:          "Wednesday and Friday class",
+			Status:            "active",
+			DueDate:           "2025-11-05",
+			Urgent:            "medium",
+			Project:           "school",
+			Tags:              []string{"class", "math"},
+			EventDuration:     time.Hour,
+			IsRecurring:       true,
+			RecurringType:     "weekly",
+			RecurringInterval: 1,
+			RecurringWeekdays: []int{3, 5},
+			RecurringMaxCount: 4,
+			RecurrenceRule:    "FREQ=WEEKLY;BYDAY=WE,FR",
+			RecurrenceStart:   endTime,
+			PausedUntil:       endTime.AddDate(0, 1, 0),
+			OccurrenceHistory: []domain.OccurrenceRecord{
+				{ScheduledTime: endTime, Status: "completed", CompletedAt: endTime.Add(time.Hour), Notes: "on time"},
+				{ScheduledTime: endTime.AddDate(0, 0, 3), Status: "pending", OriginalTime: endTime.AddDate(0, 0, 2)},
+			},
+		},
+		{TaskID: 2, TaskName: "Buy milk", Status: "deleted", CompletedAt: endTime, ArchivedAt: endTime.AddDate(0, 0, 7), DeletedAt: endTime.AddDate(0, 0, 9)},
+	}
+
+	if err := store.Save(todos, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load(false)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded) != 2 {
+		t.Fatalf("Expected 2 todos, got %d", len(loaded))
+	}
+
+	got := loaded[0]
+	if got.TaskName != "Class" || got.User != "alice" || got.EventDuration != time.Hour || got.Project != "school" {
+		t.Errorf("Scalar fields not preserved: %+v", got)
+	}
+	if !got.EndTime.Equal(endTime) {
+		t.Errorf("EndTime: expected %v, got %v", endTime, got.EndTime)
+	}
+	if !got.PausedUntil.Equal(endTime.AddDate(0, 1, 0)) {
+		t.Errorf("PausedUntil not preserved: %v", got.PausedUntil)
+	}
+	if got.RecurrenceRule != "FREQ=WEEKLY;BYDAY=WE,FR" || !got.RecurrenceStart.Equal(endTime) {
+		t.Errorf("Recurrence rule not preserved: %q from %v", got.RecurrenceRule, got.RecurrenceStart)
+	}
+	if len(got.RecurringWeekdays) != 2 || got.RecurringWeekdays[0] != 3 || got.RecurringWeekdays[1] != 5 {
+		t.Errorf("RecurringWeekdays not preserved: %v", got.RecurringWeekdays)
+	}
+	if len(got.Tags) != 2 || got.Tags[0] != "class" || got.Tags[1] != "math" {
+		t.Errorf("Tags not preserved: %v", got.Tags)
+	}
+	if len(got.OccurrenceHistory) != 2 {
+		t.Fatalf("Expected 2 occurrences, got %d", len(got.OccurrenceHistory))
+	}
+	if got.OccurrenceHistory[0].Status != "completed" || got.OccurrenceHistory[0].Notes != "on time" {
+		t.Errorf("Occurrence not preserved: %+v", got.OccurrenceHistory[0])
+	}
+	if !got.OccurrenceHistory[1].CompletedAt.IsZero() {
+		t.Errorf("Expected zero CompletedAt, got %v", got.OccurrenceHistory[1].CompletedAt)
+	}
+	if !got.OccurrenceHistory[1].OriginalTime.Equal(endTime.AddDate(0, 0, 2)) || !got.OccurrenceHistory[0].OriginalTime.IsZero() {
+		t.Errorf("OriginalTime not preserved: %+v", got.OccurrenceHistory)
+	}
+	if loaded[1].TaskID != 2 || loaded[1].IsRecurring || !loaded[1].CompletedAt.Equal(endTime) || !loaded[1].ArchivedAt.Equal(endTime.AddDate(0, 0, 7)) ||
+		!loaded[1].DeletedAt.Equal(endTime.AddDate(0, 0, 9)) {
+		t.Errorf("Second task not preserved: %+v", loaded[1])
+	}
+}
+
+func TestSQLiteStore_ActiveAndBackupAreSeparate(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	if err := store.Save([]domain.TodoItem{{TaskID: 1, TaskName: "Active"}}, false); err != nil {
+		t.Fatalf("Save active failed: %v", err)
+	}
+	if err := store.Save([]domain.TodoItem{{TaskID: 2, TaskName: "Done", Status: "completed"}}, true); err != nil {
+		t.Fatalf("Save backup failed: %v", err)
+	}
+
+	// Saving the active list again must not touch the backup list
+	if err := store.Save([]domain.TodoItem{}, false); err != nil {
+		t.Fatalf("Save empty active failed: %v", err)
+	}
+
+	active, _ := store.Load(false)
+	backup, _ := store.Load(true)
+	if len(active) != 0 {
+		t.Errorf("Expected empty active list, got %d", len(active))
+	}
+	if len(backup) != 1 || backup[0].TaskID != 2 {
+		t.Errorf("Backup list not preserved: %+v", backup)
+	}
+}
+
+func TestCopyTodos_FromFileStore(t *testing.T) {
+	dir := t.TempDir()
+	src := NewFileTodoStore(filepath.Join(dir, "todo.json"), filepath.Join(dir, "todo_back.json"))
+	if err := src.Save([]domain.TodoItem{{TaskID: 1, TaskName: "A"}, {TaskID: 2, TaskName: "B"}}, false); err != nil {
+		t.Fatalf("Save active failed: %v", err)
+	}
+	if err := src.Save([]domain.TodoItem{{TaskID: 3, TaskName: "C", Status: "deleted"}}, true); err != nil {
+		t.Fatalf("Save backup failed: %v", err)
+	}
+
+	dst := newTestSQLiteStore(t)
+	active, backup, err := CopyTodos(dst, src)
+	if err != nil {
+		t.Fatalf("CopyTodos failed: %v", err)
+	}
+	if active != 2 || backup != 1 {
+		t.Errorf("Expected 2 active and 1 backup, got %d and %d", active, backup)
+	}
+
+	empty, err := IsEmpty(dst)
+	if err != nil {
+		t.Fatalf("IsEmpty failed: %v", err)
+	}
+	if empty {
+		t.Error("Expected database to contain tasks after import")
+	}
+}
+
+func TestSQLiteStore_NextID(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	if err := store.Save([]domain.TodoItem{{TaskID: 3, TaskName: "Done"}}, true); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if id, err := store.NextID(); err != nil || id != 4 {
+		t.Fatalf("Expected ID 4 after the backup's 3, got %d (%v)", id, err)
+	}
+
+	if err := store.Save(nil, true); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if id, err := store.NextID(); err != nil || id != 5 {
+		t.Errorf("Expected IDs not to be reused, got %d (%v)", id, err)
+	}
+
+	if err := store.Save([]domain.TodoItem{{TaskID: 5, UUID: "5f0c1a2e-0000-4000-8000-000000000005"}}, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if loaded, _ := store.Load(false); len(loaded) != 1 || loaded[0].UUID != "5f0c1a2e-0000-4000-8000-000000000005" {
+		t.Errorf("UUID not preserved: %+v", loaded)
+	}
+}
+
+func TestSQLiteStore_AddsRecurrenceRulesOnOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.db")
+	store, err := NewSQLiteTodoStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteTodoStore failed: %v", err)
+	}
+	first := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	// A task stored before tasks had rules
+	old := domain.TodoItem{
+		TaskID: 1, TaskName: "Class", Status: "active", EndTime: first.AddDate(0, 0, 2),
+		IsRecurring: true, RecurringType: "weekly", RecurringInterval: 2, RecurringWeekdays: []int{1, 3},
+		OccurrenceHistory: []domain.OccurrenceRecord{{ScheduledTime: first, Status: "completed"}},
+	}
+	if err := store.Save([]domain.TodoItem{old}, true); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	store.Close()
+
+	store, err = NewSQLiteTodoStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteTodoStore failed: %v", err)
+	}
+	defer store.Close()
+	backup, _ := store.Load(true)
+	if len(backup) != 1 || backup[0].RecurrenceRule != "FREQ=WEEKLY;INTERVAL=2;WKST=SU;BYDAY=MO,WE" || !backup[0].RecurrenceStart.Equal(first) {
+		t.Errorf("Expected the rule of the recurring fields, got %+v", backup)
+	}
+}
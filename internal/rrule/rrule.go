@@ -0,0 +1,487 @@
+// Package rrule evaluates iCalendar recurrence rules (RFC 5545, section
+// 3.3.10), which describe the schedules of recurring tasks.
+//
+// Rules repeat DAILY, WEEKLY, MONTHLY or YEARLY and support INTERVAL, COUNT,
+// UNTIL, WKST, BYMONTH, BYMONTHDAY, BYDAY (with ordinals such as -1FR for
+// the last Friday) and BYSETPOS. Every occurrence keeps the time of day of
+// the start, so the parts below a day (BYHOUR, BYMINUTE, BYSECOND) are not
+// supported, and neither are BYWEEKNO and BYYEARDAY.
+package rrule
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is how often a rule repeats
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// WeekdayNum is a BYDAY entry: a weekday, or with N set the Nth such
+// weekday of the month or year, counting from the end when negative
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// Rule is a parsed recurrence rule. The occurrences of a rule depend on
+// the start (DTSTART) they are computed from.
+type Rule struct {
+	Freq       Frequency
+	Interval   int          // Repeat every Interval days, weeks, months or years; at least 1
+	Count      int          // Number of occurrences from the start; 0 means no limit
+	Until      time.Time    // Last possible occurrence; zero means no limit
+	WeekStart  time.Weekday // First day of the week (WKST) for WEEKLY rules; Monday unless given
+	ByMonth    []int        // 1-12
+	ByMonthDay []int        // 1-31, or -1 for the last day of the month
+	ByDay      []WeekdayNum
+	BySetPos   []int // Picks the nth occurrences of each period, -1 being the last
+}
+
+// cyclePeriods is the number of periods of each frequency in 400 years,
+// after which the Gregorian calendar repeats: a rule that selects no day in
+// that many periods in a row never will, such as the 30th of February
+var cyclePeriods = map[Frequency]int{Daily: 146097, Weekly: 20871, Monthly: 4800, Yearly: 400}
+
+var weekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Parse parses a rule such as "FREQ=MONTHLY;BYDAY=-1FR". An "RRULE:" prefix
+// is allowed. UNTIL is read as UTC when it ends in Z, and otherwise in local
+// time, a date meaning the end of that day.
+func Parse(s string) (*Rule, error) {
+	text := strings.ToUpper(strings.TrimSpace(s))
+	text = strings.TrimPrefix(text, "RRULE:")
+	if text == "" {
+		return nil, fmt.Errorf("invalid RRULE: empty rule")
+	}
+
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(text, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid RRULE part %q", part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("invalid RRULE: %s given twice", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			switch f := Frequency(value); f {
+			case Daily, Weekly, Monthly, Yearly:
+				r.Freq = f
+			default:
+				return nil, fmt.Errorf("unsupported RRULE frequency %s", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = parseInt(key, value, 1, 0)
+		case "COUNT":
+			r.Count, err = parseInt(key, value, 1, 0)
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+		case "WKST":
+			day := slices.Index(weekdayCodes, value)
+			if day < 0 {
+				return nil, fmt.Errorf("invalid RRULE WKST %s", value)
+			}
+			r.WeekStart = time.Weekday(day)
+		case "BYMONTH":
+			r.ByMonth, err = parseInts(key, value, 1, 12, false)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseInts(key, value, 1, 31, true)
+		case "BYSETPOS":
+			r.BySetPos, err = parseInts(key, value, 1, 366, true)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		default:
+			return nil, fmt.Errorf("unsupported RRULE part %s", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case r.Freq == "":
+		return nil, fmt.Errorf("invalid RRULE: FREQ is required")
+	case r.Count > 0 && !r.Until.IsZero():
+		return nil, fmt.Errorf("invalid RRULE: COUNT and UNTIL cannot both be given")
+	case r.Freq == Weekly && len(r.ByMonthDay) > 0:
+		return nil, fmt.Errorf("invalid RRULE: BYMONTHDAY cannot be used with FREQ=WEEKLY")
+	case len(r.BySetPos) > 0 && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByMonth) == 0:
+		return nil, fmt.Errorf("invalid RRULE: BYSETPOS needs BYDAY, BYMONTHDAY or BYMONTH")
+	}
+	for _, day := range r.ByDay {
+		if day.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return nil, fmt.Errorf("invalid RRULE: BYDAY=%s needs FREQ=MONTHLY or FREQ=YEARLY", day)
+		}
+		if day.N != 0 && r.Freq == Monthly && (day.N > 5 || day.N < -5) {
+			return nil, fmt.Errorf("invalid RRULE: a month has no %s", day)
+		}
+	}
+	return r, nil
+}
+
+// FromFields builds the rule of a task described by a recurring type
+// (daily, weekly, monthly or yearly), an interval and, for weekly tasks,
+// weekdays (0 is Sunday). Weeks start on Sunday.
+func FromFields(recurringType string, interval int, weekdays []int) (*Rule, error) {
+	freq := Frequency(strings.ToUpper(recurringType))
+	switch freq {
+	case Daily, Weekly, Monthly, Yearly:
+	default:
+		return nil, fmt.Errorf("unknown recurring type %q", recurringType)
+	}
+	r := &Rule{Freq: freq, Interval: max(interval, 1), WeekStart: time.Sunday}
+	if freq == Weekly {
+		days := slices.Clone(weekdays)
+		slices.Sort(days)
+		for _, day := range slices.Compact(days) {
+			if day < 0 || day > 6 {
+				return nil, fmt.Errorf("invalid weekday %d", day)
+			}
+			r.ByDay = append(r.ByDay, WeekdayNum{Day: time.Weekday(day)})
+		}
+	}
+	return r, nil
+}
+
+// Weekdays returns the weekdays of a WEEKLY rule whose BYDAY lists plain
+// weekdays and which has no other BY parts, as the RecurringWeekdays of a
+// task; nil for any other rule
+func (r *Rule) Weekdays() []int {
+	if r.Freq != Weekly || len(r.ByMonth) > 0 || len(r.BySetPos) > 0 {
+		return nil
+	}
+	var days []int
+	for _, day := range r.ByDay {
+		if day.N != 0 {
+			return nil
+		}
+		days = append(days, int(day.Day))
+	}
+	slices.Sort(days)
+	return slices.Compact(days)
+}
+
+// String formats r as an RRULE value. UNTIL is written in UTC when it is a
+// UTC time and as a local time otherwise.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	// The start of the week decides which weeks a multi-day weekly rule
+	// with an interval falls on, and the weeks tasks group its days by
+	if r.Freq == Weekly && len(r.ByDay) > 0 && r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCodes[r.WeekStart])
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		if r.Until.Location() == time.UTC {
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102T150405Z"))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102T150405"))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// String formats d as a BYDAY entry such as MO or -1FR
+func (d WeekdayNum) String() string {
+	if d.N == 0 {
+		return weekdayCodes[d.Day]
+	}
+	return strconv.Itoa(d.N) + weekdayCodes[d.Day]
+}
+
+// Occurrences returns the occurrences of r from start on, in order. Each
+// falls on a day the rule selects, at the time of day of start; start
+// itself is only an occurrence if the rule selects its day. COUNT counts
+// from start.
+func (r *Rule) Occurrences(start time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		count, empty := 0, 0
+		for period := 0; empty < cyclePeriods[r.Freq]; period++ {
+			days := r.periodDays(start, period)
+			if len(days) == 0 {
+				empty++
+				continue
+			}
+			empty = 0
+			for _, day := range days {
+				t := time.Date(day.Year(), day.Month(), day.Day(),
+					start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+				if t.Before(start) {
+					continue
+				}
+				if !r.Until.IsZero() && t.After(r.Until) {
+					return
+				}
+				if !yield(t) {
+					return
+				}
+				count++
+				if r.Count > 0 && count >= r.Count {
+					return
+				}
+			}
+		}
+	}
+}
+
+// After returns the first occurrence of r from start that is after t, or
+// the zero time if the rule has ended by then
+func (r *Rule) After(start, t time.Time) time.Time {
+	for occ := range r.Occurrences(start) {
+		if occ.After(t) {
+			return occ
+		}
+	}
+	return time.Time{}
+}
+
+// Between returns the occurrences of r from start that fall within
+// [from, to)
+func (r *Rule) Between(start, from, to time.Time) []time.Time {
+	var occurrences []time.Time
+	for occ := range r.Occurrences(start) {
+		if !occ.Before(to) {
+			break
+		}
+		if !occ.Before(from) {
+			occurrences = append(occurrences, occ)
+		}
+	}
+	return occurrences
+}
+
+// periodDays returns the days the rule selects in the given period, counted
+// in intervals from the period of start, at midnight and in order
+func (r *Rule) periodDays(start time.Time, period int) []time.Time {
+	loc := start.Location()
+	y, m, d := start.Date()
+	step := period * r.Interval
+
+	var first time.Time
+	var length int
+	switch r.Freq {
+	case Daily:
+		first, length = time.Date(y, m, d+step, 0, 0, 0, 0, loc), 1
+	case Weekly:
+		back := (int(start.Weekday()) - int(r.WeekStart) + 7) % 7
+		first, length = time.Date(y, m, d-back+7*step, 0, 0, 0, 0, loc), 7
+	case Monthly:
+		first = time.Date(y, m+time.Month(step), 1, 0, 0, 0, 0, loc)
+		length = daysIn(first.Year(), first.Month())
+	default:
+		first = time.Date(y+step, 1, 1, 0, 0, 0, 0, loc)
+		length = time.Date(first.Year(), 12, 31, 0, 0, 0, 0, loc).YearDay()
+	}
+
+	var days []time.Time
+	for i := 0; i < length; i++ {
+		day := time.Date(first.Year(), first.Month(), first.Day()+i, 0, 0, 0, 0, loc)
+		if r.selects(day, start) {
+			days = append(days, day)
+		}
+	}
+	if len(r.BySetPos) > 0 {
+		days = pickPositions(days, r.BySetPos)
+	}
+	return days
+}
+
+// selects reports whether the rule picks day, filling in the parts the
+// rule leaves out from start as RFC 5545 does: a weekly rule repeats on the
+// weekday of start, a monthly one on its day and a yearly one on its date
+func (r *Rule) selects(day, start time.Time) bool {
+	if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, int(day.Month())) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 && !r.selectsMonthDay(day) {
+		return false
+	}
+	if len(r.ByDay) > 0 && !r.selectsWeekday(day) {
+		return false
+	}
+
+	switch r.Freq {
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return day.Weekday() == start.Weekday()
+		}
+	case Monthly:
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			return day.Day() == start.Day()
+		}
+	case Yearly:
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			if len(r.ByMonth) == 0 && day.Month() != start.Month() {
+				return false
+			}
+			return day.Day() == start.Day()
+		}
+	}
+	return true
+}
+
+func (r *Rule) selectsMonthDay(day time.Time) bool {
+	last := daysIn(day.Year(), day.Month())
+	for _, d := range r.ByMonthDay {
+		if d == day.Day() || (d < 0 && last+d+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectsWeekday matches day against BYDAY. Ordinals count within the
+// month, or within the year for yearly rules without BYMONTH.
+func (r *Rule) selectsWeekday(day time.Time) bool {
+	for _, wd := range r.ByDay {
+		if day.Weekday() != wd.Day {
+			continue
+		}
+		if wd.N == 0 {
+			return true
+		}
+		pos, length := day.Day(), daysIn(day.Year(), day.Month())
+		if r.Freq == Yearly && len(r.ByMonth) == 0 {
+			pos, length = day.YearDay(), time.Date(day.Year(), 12, 31, 0, 0, 0, 0, day.Location()).YearDay()
+		}
+		if wd.N == (pos-1)/7+1 || wd.N == -((length-pos)/7+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// pickPositions returns the days at the given BYSETPOS positions, in order
+func pickPositions(days []time.Time, positions []int) []time.Time {
+	var picked []int
+	for _, pos := range positions {
+		i := pos - 1
+		if pos < 0 {
+			i = len(days) + pos
+		}
+		if i >= 0 && i < len(days) && !slices.Contains(picked, i) {
+			picked = append(picked, i)
+		}
+	}
+	slices.Sort(picked)
+	result := make([]time.Time, len(picked))
+	for i, index := range picked {
+		result[i] = days[index]
+	}
+	return result
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func parseInt(key, value string, lo, hi int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < lo || (hi > 0 && n > hi) {
+		return 0, fmt.Errorf("invalid RRULE %s %s", key, value)
+	}
+	return n, nil
+}
+
+// parseInts parses a list of numbers within [lo, hi], or within [-hi, -lo]
+// too when negative is set
+func parseInts(key, value string, lo, hi int, negative bool) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		abs := n
+		if negative && n < 0 {
+			abs = -n
+		}
+		if err != nil || abs < lo || abs > hi {
+			return nil, fmt.Errorf("invalid RRULE %s %s", key, value)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid RRULE BYDAY %s", value)
+		}
+		code := item[len(item)-2:]
+		day := slices.Index(weekdayCodes, code)
+		if day < 0 {
+			return nil, fmt.Errorf("invalid RRULE BYDAY %s", value)
+		}
+		wd := WeekdayNum{Day: time.Weekday(day)}
+		if prefix := strings.TrimPrefix(item[:len(item)-2], "+"); prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n > 53 || n < -53 {
+				return nil, fmt.Errorf("invalid RRULE BYDAY %s", value)
+			}
+			wd.N = n
+		}
+		days = append(days, wd)
+	}
+	return days, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102", value, time.Local); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid RRULE UNTIL %s", value)
+}
+
+func joinInts(values []int) string {
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = strconv.Itoa(v)
+	}
+	return strings.Join(items, ",")
+}
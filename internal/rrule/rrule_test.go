@@ -0,0 +1,180 @@
+package rrule
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 9, 30, 0, 0, time.Local)
+}
+
+// firstN returns the first n occurrences of rule from start
+func firstN(t *testing.T, rule string, start time.Time, n int) []time.Time {
+	t.Helper()
+	r, err := Parse(rule)
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", rule, err)
+	}
+	var got []time.Time
+	for occ := range r.Occurrences(start) {
+		if len(got) == n {
+			break
+		}
+		got = append(got, occ)
+	}
+	return got
+}
+
+func TestOccurrences(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		ends  bool // want lists every occurrence
+		want  []time.Time
+	}{
+		{
+			"last Friday of the month", "FREQ=MONTHLY;BYDAY=-1FR", date(2026, 10, 1), false,
+			[]time.Time{date(2026, 10, 30), date(2026, 11, 27), date(2026, 12, 25), date(2027, 1, 29)},
+		},
+		{
+			"2nd and 4th Tuesday", "FREQ=MONTHLY;BYDAY=2TU,4TU", date(2026, 10, 14), false,
+			[]time.Time{date(2026, 10, 27), date(2026, 11, 10), date(2026, 11, 24), date(2026, 12, 8)},
+		},
+		{
+			"weekdays", "RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", date(2026, 10, 16), false,
+			[]time.Time{date(2026, 10, 16), date(2026, 10, 19), date(2026, 10, 20), date(2026, 10, 21)},
+		},
+		{
+			"last workday of the month", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", date(2026, 10, 1), false,
+			[]time.Time{date(2026, 10, 30), date(2026, 11, 30), date(2026, 12, 31), date(2027, 1, 29)},
+		},
+		{
+			"15th and last day", "FREQ=MONTHLY;BYMONTHDAY=15,-1", date(2027, 1, 20), false,
+			[]time.Time{date(2027, 1, 31), date(2027, 2, 15), date(2027, 2, 28), date(2027, 3, 15)},
+		},
+		{
+			"31st skips short months", "FREQ=MONTHLY", date(2027, 1, 31), false,
+			[]time.Time{date(2027, 1, 31), date(2027, 3, 31), date(2027, 5, 31), date(2027, 7, 31)},
+		},
+		{
+			"every other week from the start's week", "FREQ=WEEKLY;INTERVAL=2;WKST=SU;BYDAY=MO,SA", date(2026, 10, 17), false,
+			[]time.Time{date(2026, 10, 17), date(2026, 10, 26), date(2026, 10, 31), date(2026, 11, 9)},
+		},
+		{
+			"Thanksgiving", "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", date(2026, 1, 1), false,
+			[]time.Time{date(2026, 11, 26), date(2027, 11, 25), date(2028, 11, 23)},
+		},
+		{
+			"first Monday of the year", "FREQ=YEARLY;BYDAY=1MO", date(2026, 1, 1), false,
+			[]time.Time{date(2026, 1, 5), date(2027, 1, 4)},
+		},
+		{
+			"leap day", "FREQ=YEARLY", date(2028, 2, 29), false,
+			[]time.Time{date(2028, 2, 29), date(2032, 2, 29)},
+		},
+		{
+			"leap day of a daily rule", "FREQ=DAILY;BYMONTH=2;BYMONTHDAY=29", date(2026, 3, 1), false,
+			[]time.Time{date(2028, 2, 29), date(2032, 2, 29)},
+		},
+		{
+			"count", "FREQ=DAILY;INTERVAL=3;COUNT=3", date(2026, 10, 1), true,
+			[]time.Time{date(2026, 10, 1), date(2026, 10, 4), date(2026, 10, 7)},
+		},
+		{
+			"until is inclusive", "FREQ=WEEKLY;UNTIL=20261015", date(2026, 10, 1), true,
+			[]time.Time{date(2026, 10, 1), date(2026, 10, 8), date(2026, 10, 15)},
+		},
+		{
+			"never matches", "FREQ=MONTHLY;BYMONTH=2;BYMONTHDAY=30", date(2026, 1, 1), true,
+			nil,
+		},
+		{
+			"never matches daily", "FREQ=DAILY;BYMONTH=4;BYMONTHDAY=31", date(2026, 1, 1), true,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := firstN(t, tt.rule, tt.start, len(tt.want)+1)
+			if !tt.ends && len(got) > len(tt.want) {
+				got = got[:len(tt.want)]
+			}
+			if !slices.EqualFunc(got, tt.want, time.Time.Equal) {
+				t.Errorf("Occurrences = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAfterAndBetween(t *testing.T) {
+	r, err := Parse("FREQ=MONTHLY;BYDAY=-1FR;COUNT=2")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	start := date(2026, 10, 1)
+	if got := r.After(start, date(2026, 10, 30)); !got.Equal(date(2026, 11, 27)) {
+		t.Errorf("After = %v, want 2026-11-27", got)
+	}
+	if got := r.After(start, date(2026, 11, 27)); !got.IsZero() {
+		t.Errorf("After the last occurrence = %v, want zero", got)
+	}
+	got := r.Between(start, date(2026, 10, 1), date(2026, 11, 27))
+	if !slices.EqualFunc(got, []time.Time{date(2026, 10, 30)}, time.Time.Equal) {
+		t.Errorf("Between = %v", got)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, rule := range []string{
+		"", "INTERVAL=2", "FREQ=HOURLY", "FREQ=DAILY;BYHOUR=9", "FREQ=DAILY;COUNT=2;UNTIL=20261231",
+		"FREQ=WEEKLY;BYDAY=2TU", "FREQ=WEEKLY;BYMONTHDAY=1", "FREQ=MONTHLY;BYDAY=6MO", "FREQ=MONTHLY;BYSETPOS=1",
+		"FREQ=DAILY;INTERVAL=0", "FREQ=DAILY;FREQ=WEEKLY", "FREQ=MONTHLY;BYMONTHDAY=32", "FREQ=WEEKLY;BYDAY=XX",
+	} {
+		if _, err := Parse(rule); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", rule)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	for rule, want := range map[string]string{
+		"rrule:freq=monthly;byday=+2tu,4TU":                "FREQ=MONTHLY;BYDAY=2TU,4TU",
+		"FREQ=WEEKLY;WKST=SU;INTERVAL=2;BYDAY=MO":          "FREQ=WEEKLY;INTERVAL=2;WKST=SU;BYDAY=MO",
+		"FREQ=WEEKLY;WKST=SU;BYDAY=MO":                     "FREQ=WEEKLY;WKST=SU;BYDAY=MO",
+		"FREQ=MONTHLY;WKST=SU;BYDAY=MO":                    "FREQ=MONTHLY;BYDAY=MO",
+		"FREQ=DAILY;UNTIL=20261231T235959Z":                "FREQ=DAILY;UNTIL=20261231T235959Z",
+		"FREQ=DAILY;UNTIL=20261231":                        "FREQ=DAILY;UNTIL=20261231T235959",
+		"FREQ=MONTHLY;BYSETPOS=-1;BYDAY=MO,FR;BYMONTH=1,7": "FREQ=MONTHLY;BYMONTH=1,7;BYDAY=MO,FR;BYSETPOS=-1",
+	} {
+		r, err := Parse(rule)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", rule, err)
+		}
+		if got := r.String(); got != want {
+			t.Errorf("Parse(%q).String() = %q, want %q", rule, got, want)
+		}
+	}
+}
+
+func TestFromFields(t *testing.T) {
+	r, err := FromFields("weekly", 2, []int{5, 1, 5})
+	if err != nil {
+		t.Fatalf("FromFields failed: %v", err)
+	}
+	if got := r.String(); got != "FREQ=WEEKLY;INTERVAL=2;WKST=SU;BYDAY=MO,FR" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := r.Weekdays(); !slices.Equal(got, []int{1, 5}) {
+		t.Errorf("Weekdays() = %v", got)
+	}
+	if _, err := FromFields("hourly", 1, nil); err == nil {
+		t.Error("Expected an error for an unknown type")
+	}
+	monthly, _ := Parse("FREQ=MONTHLY;BYDAY=-1FR")
+	if got := monthly.Weekdays(); got != nil {
+		t.Errorf("Weekdays() of a monthly rule = %v, want nil", got)
+	}
+}
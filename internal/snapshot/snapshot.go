@@ -0,0 +1,203 @@
+// Package snapshot keeps rotating copies of the task files.
+//
+// Every save stores a copy of the written file in the snapshot directory,
+// named after the file and the time of the save (e.g.
+// todo-20240110-093000.000.json). Old copies are pruned so that the most
+// recent saves plus the last snapshot of each recent day are kept.
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SongRunqi/go-todo/internal/logger"
+)
+
+// timeLayout is the timestamp embedded in snapshot names
+const timeLayout = "20060102-150405.000"
+
+// Snapshot is one stored copy of a task file
+type Snapshot struct {
+	Name   string    // File name inside the snapshot directory
+	Path   string    // Full path of the snapshot
+	Source string    // Base name of the task file it copies, e.g. todo.json
+	Time   time.Time // When it was taken
+	Size   int64
+}
+
+// Manager stores and rotates snapshots in Dir
+type Manager struct {
+	Dir string
+
+	// KeepRecent is how many of the most recent snapshots of each file are kept
+	KeepRecent int
+	// KeepDays keeps the last snapshot of each of this many recent days
+	KeepDays int
+
+	// now is replaceable in tests
+	now func() time.Time
+}
+
+// NewManager returns a manager storing snapshots in dir
+func NewManager(dir string, keepRecent, keepDays int) *Manager {
+	return &Manager{Dir: dir, KeepRecent: keepRecent, KeepDays: keepDays, now: time.Now}
+}
+
+// Take stores data as a new snapshot of the file at source and prunes old
+// snapshots of that file. Nothing is stored if data is identical to the most
+// recent snapshot.
+func (m *Manager) Take(source string, data []byte) (Snapshot, error) {
+	base := filepath.Base(source)
+	existing, err := m.List(base)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if len(existing) > 0 {
+		if latest, err := os.ReadFile(existing[0].Path); err == nil && bytes.Equal(latest, data) {
+			return existing[0], nil
+		}
+	}
+
+	if err := os.MkdirAll(m.Dir, 0755); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	taken := m.now()
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	var path string
+	for {
+		path = filepath.Join(m.Dir, stem+"-"+taken.Format(timeLayout)+ext)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		// Two saves within the same millisecond
+		taken = taken.Add(time.Millisecond)
+	}
+
+	// Snapshots are as private as the file they copy
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(source); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	logger.Debugf("Snapshot %s taken", path)
+
+	if err := m.prune(base); err != nil {
+		logger.Warnf("Failed to prune old snapshots: %v", err)
+	}
+	return Snapshot{Name: filepath.Base(path), Path: path, Source: base, Time: taken, Size: int64(len(data))}, nil
+}
+
+// List returns the snapshots of the task file named source (e.g. todo.json),
+// newest first. An empty source lists the snapshots of every file.
+func (m *Manager) List(source string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(m.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		s, ok := parseName(entry.Name())
+		if !ok || (source != "" && s.Source != source) {
+			continue
+		}
+		s.Path = filepath.Join(m.Dir, s.Name)
+		if info, err := entry.Info(); err == nil {
+			s.Size = info.Size()
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.After(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// Find returns the snapshot with the given name
+func (m *Manager) Find(name string) (Snapshot, error) {
+	all, err := m.List("")
+	if err != nil {
+		return Snapshot{}, err
+	}
+	for _, s := range all {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return Snapshot{}, fmt.Errorf("snapshot %q not found in %s", name, m.Dir)
+}
+
+// prune deletes the snapshots of source that fall outside the retention policy
+func (m *Manager) prune(source string) error {
+	snapshots, err := m.List(source)
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool)
+	for i := 0; i < len(snapshots) && i < m.KeepRecent; i++ {
+		keep[snapshots[i].Name] = true
+	}
+	if m.KeepDays > 0 {
+		now := m.now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		cutoff := today.AddDate(0, 0, -(m.KeepDays - 1))
+		days := make(map[string]bool)
+		// Newest first, so the first snapshot seen for a day is its last one
+		for _, s := range snapshots {
+			if s.Time.Before(cutoff) {
+				continue
+			}
+			day := s.Time.Format("2006-01-02")
+			if !days[day] {
+				days[day] = true
+				keep[s.Name] = true
+			}
+		}
+	}
+
+	for _, s := range snapshots {
+		if keep[s.Name] {
+			continue
+		}
+		if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		logger.Debugf("Pruned snapshot %s", s.Name)
+	}
+	return nil
+}
+
+// parseName splits a snapshot file name into its source file and time
+func parseName(name string) (Snapshot, bool) {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if len(stem) <= len(timeLayout)+1 || stem[len(stem)-len(timeLayout)-1] != '-' {
+		return Snapshot{}, false
+	}
+	taken, err := time.ParseInLocation(timeLayout, stem[len(stem)-len(timeLayout):], time.Local)
+	if err != nil {
+		return Snapshot{}, false
+	}
+	return Snapshot{
+		Name:   name,
+		Source: stem[:len(stem)-len(timeLayout)-1] + ext,
+		Time:   taken,
+	}, true
+}
@@ -0,0 +1,84 @@
+package snapshot
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTake_SkipsUnchangedContent(t *testing.T) {
+	m := NewManager(t.TempDir(), 5, 0)
+
+	first, err := m.Take("/home/u/.todo/todo.json", []byte("one"))
+	if err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	again, err := m.Take("/home/u/.todo/todo.json", []byte("one"))
+	if err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	if again.Name != first.Name {
+		t.Errorf("Identical content should reuse %s, got %s", first.Name, again.Name)
+	}
+
+	snapshots, _ := m.List("todo.json")
+	if len(snapshots) != 1 || snapshots[0].Source != "todo.json" {
+		t.Errorf("Expected one snapshot of todo.json, got %+v", snapshots)
+	}
+}
+
+func TestTake_RotatesRecentAndDaily(t *testing.T) {
+	m := NewManager(t.TempDir(), 3, 5)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+
+	// Two saves a day for the last 8 days, oldest first
+	for day := 7; day >= 0; day-- {
+		for _, hour := range []int{9, 18} {
+			taken := now.AddDate(0, 0, -day)
+			taken = time.Date(taken.Year(), taken.Month(), taken.Day(), hour, 0, 0, 0, time.Local)
+			m.now = func() time.Time { return taken }
+			if _, err := m.Take("todo.json", []byte(fmt.Sprintf("%s %d", taken, hour))); err != nil {
+				t.Fatalf("Take failed: %v", err)
+			}
+		}
+	}
+	// A snapshot of another file is rotated independently
+	if _, err := m.Take("todo_back.json", []byte("backup")); err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+
+	snapshots, err := m.List("todo.json")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var names []string
+	for _, s := range snapshots {
+		names = append(names, s.Time.Format("01-02 15h"))
+	}
+
+	// 3 most recent (03-10 18h, 03-10 09h, 03-09 18h) plus the last snapshot
+	// of each of the 5 days up to today (03-06 .. 03-10)
+	want := []string{"03-10 18h", "03-10 09h", "03-09 18h", "03-08 18h", "03-07 18h", "03-06 18h"}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("Kept %v, want %v", names, want)
+	}
+
+	if backups, _ := m.List("todo_back.json"); len(backups) != 1 {
+		t.Errorf("Expected the backup snapshot to survive, got %+v", backups)
+	}
+}
+
+func TestFind(t *testing.T) {
+	m := NewManager(t.TempDir(), 5, 0)
+	s, err := m.Take("todo.json", []byte("data"))
+	if err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	found, err := m.Find(s.Name)
+	if err != nil || found.Path != s.Path {
+		t.Errorf("Find(%s) = %+v, %v", s.Name, found, err)
+	}
+	if _, err := m.Find("todo-19990101-000000.000.json"); err == nil {
+		t.Error("Expected an error for an unknown snapshot")
+	}
+}
@@ -10,10 +10,12 @@ import (
 
 type TodoItem = domain.TodoItem
 
-// MemoryTodoStore implements in-memory storage for testing
+// MemoryTodoStore implements in-memory storage for tests only; it is not a
+// storage backend and cannot be selected through config.Storage
 type MemoryTodoStore struct {
-	data map[string][]TodoItem
-	mu   sync.RWMutex
+	data   map[string][]TodoItem
+	lastID int
+	mu     sync.RWMutex
 }
 
 // NewMemoryStore creates a new memory-based store
@@ -61,9 +63,21 @@ func (m *MemoryTodoStore) Save(todos []TodoItem, backup bool) error {
 	copy(data, todos)
 
 	m.data[key] = data
+	for _, t := range data {
+		m.lastID = max(m.lastID, t.TaskID)
+	}
 	return nil
 }
 
+// NextID hands out a task ID above every ID saved so far
+func (m *MemoryTodoStore) NextID() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastID++
+	return m.lastID, nil
+}
+
 // Clear clears all data (useful for testing)
 func (m *MemoryTodoStore) Clear() {
 	m.mu.Lock()
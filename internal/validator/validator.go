@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/SongRunqi/go-todo/internal/i18n"
+	"github.com/SongRunqi/go-todo/internal/rrule"
 )
 
 // ValidateTaskID validates that a task ID is valid (must be > 0)
@@ -42,6 +43,26 @@ func ValidateStatus(status string) error {
 	return nil
 }
 
+// ValidateRecurringStatus validates the status of a recurring task, which
+// tracks the series as a whole rather than a single piece of work
+func ValidateRecurringStatus(status string) error {
+	switch status {
+	case "active", "paused", "completed", "cancelled":
+		return nil
+	}
+	return fmt.Errorf(i18n.T("validation.invalid_recurring_status"), status)
+}
+
+// ValidateOccurrenceStatus validates the status of one occurrence of a
+// recurring task
+func ValidateOccurrenceStatus(status string) error {
+	switch status {
+	case "pending", "completed", "missed", "skipped":
+		return nil
+	}
+	return fmt.Errorf(i18n.T("validation.invalid_occurrence_status"), status)
+}
+
 // NormalizeStatus maps common status terms (Chinese/English) to system-defined statuses
 func NormalizeStatus(status string) string {
 	statusMap := map[string]string{
@@ -208,6 +229,50 @@ func ValidateRecurringMaxCount(maxCount int, isRecurring bool) error {
 	return nil
 }
 
+// ValidateRecurrenceRule validates an RFC 5545 recurrence rule such as
+// FREQ=MONTHLY;BYDAY=-1FR
+func ValidateRecurrenceRule(rule string) error {
+	if rule == "" {
+		return nil // Optional, the recurring type and interval describe simple schedules
+	}
+	if _, err := rrule.Parse(rule); err != nil {
+		return fmt.Errorf("invalid recurrence rule: %w", err)
+	}
+	return nil
+}
+
+// ValidateListName validates a task list name. Names become directory
+// names, so they are limited to letters, digits, '-' and '_'.
+func ValidateListName(name string) error {
+	return validateFileName(i18n.T("validation.kind_list"), name)
+}
+
+// ValidateDeviceName validates a device name used by sync, which becomes a
+// file name under the same rules as list names
+func ValidateDeviceName(name string) error {
+	return validateFileName(i18n.T("validation.kind_device"), name)
+}
+
+// validateFileName checks that name is safe to use as a file name; kind
+// names what it is the name of in messages
+func validateFileName(kind, name string) error {
+	if name == "" {
+		return fmt.Errorf(i18n.T("validation.name_empty"), kind)
+	}
+	if len(name) > 64 {
+		return fmt.Errorf(i18n.T("validation.name_too_long"), kind, len(name))
+	}
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case (r == '-' || r == '_') && i > 0:
+		default:
+			return fmt.Errorf(i18n.T("validation.invalid_name"), kind, name)
+		}
+	}
+	return nil
+}
+
 // ValidateTodoItem validates all fields of a TodoItem
 type TodoItem interface {
 	GetTaskID() int
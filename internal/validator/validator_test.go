@@ -197,3 +197,30 @@ func TestValidateAll(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateListName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"simple", "work", false},
+		{"with dash and digits", "side-project-2", false},
+		{"with underscore", "home_stuff", false},
+		{"empty", "", true},
+		{"path separator", "work/home", true},
+		{"parent directory", "..", true},
+		{"leading dash", "-work", true},
+		{"space", "my list", true},
+		{"too long", strings.Repeat("a", 65), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateListName(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateListName(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -6,11 +6,13 @@ import (
 	"time"
 
 	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/storage"
 )
 
 // BenchmarkCreateTask benchmarks task creation
 func BenchmarkCreateTask(b *testing.B) {
 	todos := make([]app.TodoItem, 0, b.N)
+	store := storage.NewMemoryStore()
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -20,7 +22,7 @@ func BenchmarkCreateTask(b *testing.B) {
 			User:     "benchuser",
 			Urgent:   "medium",
 		}
-		app.CreateTask(&todos, task)
+		app.CreateTask(&todos, task, store)
 	}
 }
 
@@ -189,13 +191,14 @@ func BenchmarkCreateMultipleTasks(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		todos := make([]app.TodoItem, 0, 10)
+		store := storage.NewMemoryStore()
 		for j := 0; j < 10; j++ {
 			task := &app.TodoItem{
 				TaskName: fmt.Sprintf("Task %d", j),
 				TaskDesc: "Description",
 				User:     "user",
 			}
-			app.CreateTask(&todos, task)
+			app.CreateTask(&todos, task, store)
 		}
 	}
 }
@@ -7,10 +7,12 @@ import (
 	"time"
 
 	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/storage"
 )
 
 func TestCreateTask(t *testing.T) {
 	todos := []app.TodoItem{}
+	store := storage.NewMemoryStore()
 
 	newTask := &app.TodoItem{
 		TaskName: "New Task",
@@ -18,7 +20,7 @@ func TestCreateTask(t *testing.T) {
 		User:     "testuser",
 	}
 
-	err := app.CreateTask(&todos, newTask)
+	err := app.CreateTask(&todos, newTask, store)
 	if err != nil {
 		t.Fatalf("CreateTask failed: %v", err)
 	}
@@ -42,12 +44,13 @@ func TestCreateTask(t *testing.T) {
 
 func TestCreateTask_MultipleItems(t *testing.T) {
 	todos := []app.TodoItem{}
+	store := storage.NewMemoryStore()
 
 	for i := 1; i <= 5; i++ {
 		task := &app.TodoItem{
 			TaskName: "Task " + string(rune(i)),
 		}
-		err := app.CreateTask(&todos, task)
+		err := app.CreateTask(&todos, task, store)
 		if err != nil {
 			t.Fatalf("CreateTask failed at iteration %d: %v", i, err)
 		}
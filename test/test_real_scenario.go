@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/storage"
 )
 
 func main() {
@@ -49,7 +50,7 @@ func main() {
 		RecurringMaxCount: 4,
 	}
 
-	err := app.CreateTask(&todos, task)
+	err := app.CreateTask(&todos, task, storage.NewMemoryStore())
 	if err != nil {
 		fmt.Printf("❌ 创建失败: %v\n", err)
 		return
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/SongRunqi/go-todo/app"
+	"github.com/SongRunqi/go-todo/internal/storage"
 )
 
 func main() {
@@ -30,7 +31,7 @@ func main() {
 	}
 
 	todos := []app.TodoItem{}
-	err := app.CreateTask(&todos, task)
+	err := app.CreateTask(&todos, task, storage.NewMemoryStore())
 	if err != nil {
 		fmt.Printf("❌ Error creating task: %v\n", err)
 		return